@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/admin"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/gin-gonic/gin"
+)
+
+// reloadHook applies a reloaded config's setting; name says which, as the
+// config file spells it
+type reloadHook struct {
+	name    string
+	prepare func(cfg *config.Config) (func(), error)
+}
+
+var (
+	reloadMu    sync.Mutex
+	reloadHooks []reloadHook
+)
+
+// onReload adds a setting that a config reload applies while running.
+// prepare checks the setting and returns the function applying it, which
+// can't fail, so a setting is only applied once every one has been checked.
+func onReload(name string, prepare func(cfg *config.Config) (func(), error)) {
+	reloadHooks = append(reloadHooks, reloadHook{name: name, prepare: prepare})
+}
+
+// reloadConfig loads the config again from path and the environment and
+// applies its reloadable settings, returning their names. An invalid
+// config changes nothing.
+func reloadConfig(path string) ([]string, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	applies := make([]func(), 0, len(reloadHooks))
+	for _, hook := range reloadHooks {
+		apply, err := hook.prepare(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s: %w", hook.name, err)
+		}
+		applies = append(applies, apply)
+	}
+	applied := make([]string, 0, len(reloadHooks))
+	for i, apply := range applies {
+		apply()
+		applied = append(applied, reloadHooks[i].name)
+	}
+	return applied, nil
+}
+
+// setupAdmin serves the admin API and registers the settings it can
+// reload: the log level and whether requests are logged
+func setupAdmin(cfg *config.Config, configPath string, requestLogging *atomic.Bool, handler *a2a.A2AHandler, geminiClient *profiler.GeminiClient, router *gin.Engine) {
+	onReload("logging.level", func(cfg *config.Config) (func(), error) {
+		level, err := logging.ParseLevel(cfg.Logging.Level)
+		if err != nil {
+			return nil, err
+		}
+		return func() { logging.UseLevel(level) }, nil
+	})
+	onReload("logging.requests.enabled", func(cfg *config.Config) (func(), error) {
+		return func() { requestLogging.Store(cfg.Logging.Requests.Enabled) }, nil
+	})
+
+	admin.NewHandler(admin.Config{
+		Token:          cfg.Auth.AdminToken,
+		Handler:        handler,
+		Cache:          geminiClient,
+		RequestLogging: requestLogging,
+		Reload:         func() ([]string, error) { return reloadConfig(configPath) },
+	}).Register(router)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/auth"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+)
+
+// setupAuth requires API keys or JWT bearer tokens on the A2A endpoint when
+// the config sets either up
+func setupAuth(settings config.Auth, handlerConfig *a2a.HandlerConfig) {
+	keys := auth.NewKeys(settings.APIKeys)
+	if path := settings.APIKeysFile; path != "" {
+		if err := keys.LoadKeyFile(path); err != nil {
+			fatal("Failed to load API keys", "error", err)
+		}
+	}
+	if keys.Len() > 0 {
+		handlerConfig.APIKeys = keys
+		logger.Info("API keys accepted", "keys", keys.Len())
+	}
+
+	if jwt := settings.JWT; jwt.JWKSURL != "" {
+		handlerConfig.BearerTokens = auth.NewJWTVerifier(auth.JWTConfig{
+			JWKSURL:  jwt.JWKSURL,
+			Issuer:   jwt.Issuer,
+			Audience: jwt.Audience,
+		})
+		logger.Info("JWT bearer tokens accepted", "jwks_url", jwt.JWKSURL, "issuer", jwt.Issuer, "audience", jwt.Audience)
+	}
+
+	if handlerConfig.Demo != nil && (handlerConfig.APIKeys != nil || handlerConfig.BearerTokens != nil) {
+		logger.Info("Demo mode lets anonymous callers through")
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+)
+
+// configFlag adds the -config flag of the commands that read settings
+func configFlag(flags *flag.FlagSet) *string {
+	return flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file; environment variables override it (default $CONFIG_FILE)")
+}
+
+// loadConfig reads the settings from the config file and the environment,
+// sets up logging and reads the settings written as secret references,
+// exiting if they are invalid
+func loadConfig(path string) *config.Config {
+	cfg, err := config.Load(path)
+	if err != nil {
+		// Logging isn't set up yet, and each problem gets its own line
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := logging.Setup(os.Stderr, cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		fatal("Invalid logging configuration", "error", err)
+	}
+	if path != "" {
+		logger.Info("Loaded config", "path", path)
+	}
+	resolveSecrets(cfg)
+	return cfg
+}
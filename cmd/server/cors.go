@@ -0,0 +1,27 @@
+package main
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// setupCORS lets the allowed origins call the server from browsers, and
+// returns the check WebSocket connections are held to, nil when CORS is off
+func setupCORS(settings config.CORS, router *gin.Engine) func(origin string) bool {
+	if len(settings.AllowedOrigins) == 0 {
+		return nil
+	}
+
+	corsConfig := cors.Config{
+		AllowedOrigins: settings.AllowedOrigins,
+		AllowedMethods: settings.AllowedMethods,
+		AllowedHeaders: settings.AllowedHeaders,
+		MaxAge:         time.Duration(settings.MaxAge),
+	}
+	router.Use(cors.Middleware(corsConfig))
+	logger.Info("Cross-origin requests allowed", "origins", settings.AllowedOrigins)
+	return corsConfig.AllowsOrigin
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/ratelimit"
+)
+
+// setupDemo turns on demo mode when the config enables it
+func setupDemo(settings config.Demo, handlerConfig *a2a.HandlerConfig) {
+	if !settings.Enabled {
+		return
+	}
+
+	limits := ratelimit.Limits{
+		PerMinute: settings.RequestsPerMinute,
+		PerDay:    settings.RequestsPerDay,
+	}
+	handlerConfig.Demo = &a2a.DemoConfig{
+		Limiter:     ratelimit.New(limits),
+		MaxPersonas: settings.MaxPersonas,
+	}
+	logger.Info("Demo mode enabled",
+		"per_minute", limits.PerMinute,
+		"per_day", limits.PerDay,
+		"max_personas", handlerConfig.Demo.MaxPersonas)
+}
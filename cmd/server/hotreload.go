@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+)
+
+// WatchInterval is how often the agent card and prompt template files are
+// checked for changes
+const WatchInterval = 2 * time.Second
+
+// watchedFile is a file whose contents are swapped in when it changes.
+// Its path, and what it was last applied from, are guarded by reloadMu.
+type watchedFile struct {
+	name    string
+	path    string
+	modTime time.Time
+	size    int64
+	// read checks the file at path and returns the function swapping it in
+	read func(path string) (func(), error)
+}
+
+// prepare checks path and returns the function that applies it and makes
+// it the watched path
+func (f *watchedFile) prepare(path string) (func(), error) {
+	var info os.FileInfo
+	if path != "" {
+		var err error
+		if info, err = os.Stat(path); err != nil {
+			return nil, err
+		}
+	}
+	apply, err := f.read(path)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		apply()
+		f.path = path
+		if info != nil {
+			f.modTime, f.size = info.ModTime(), info.Size()
+		}
+	}, nil
+}
+
+// load applies path, which becomes the watched path if that succeeds
+func (f *watchedFile) load(path string) error {
+	apply, err := f.prepare(path)
+	if err != nil {
+		return err
+	}
+	apply()
+	return nil
+}
+
+// changed tells whether the watched file differs from when it was applied
+func (f *watchedFile) changed() bool {
+	if f.path == "" {
+		return false
+	}
+	info, err := os.Stat(f.path)
+	if err != nil {
+		// A file being replaced may briefly be missing; the next check
+		// picks it up
+		return false
+	}
+	return !info.ModTime().Equal(f.modTime) || info.Size() != f.size
+}
+
+// setupHotReload applies the agent card and prompt template files, then
+// reloads them when they change, when the config names other files, and
+// on SIGHUP, which reloads the whole config as the admin API does. Tasks
+// in flight keep the prompt they started with.
+func setupHotReload(cfg *config.Config, configPath string, baseCard *agent.AgentCard, handler *a2a.A2AHandler, geminiClient *profiler.GeminiClient) {
+	cardFile := &watchedFile{name: "server.agentCardFile", read: func(path string) (func(), error) {
+		card := baseCard
+		if path != "" {
+			var err error
+			if card, err = agent.LoadCard(*baseCard, path); err != nil {
+				return nil, err
+			}
+		}
+		return func() { handler.SetAgentCard(card) }, nil
+	}}
+	promptFile := &watchedFile{name: "gemini.promptTemplateFile", read: func(path string) (func(), error) {
+		var text []byte
+		if path != "" {
+			var err error
+			if text, err = os.ReadFile(path); err != nil {
+				return nil, fmt.Errorf("failed to read prompt template: %w", err)
+			}
+		}
+		prompt, err := profiler.ParseProfilePrompt(string(text))
+		if err != nil {
+			return nil, err
+		}
+		return func() { geminiClient.UseProfilePrompt(prompt) }, nil
+	}}
+
+	if err := cardFile.load(cfg.Server.AgentCardFile); err != nil {
+		fatal("Failed to load agent card file", "path", cfg.Server.AgentCardFile, "error", err)
+	}
+	if err := promptFile.load(cfg.Gemini.PromptTemplateFile); err != nil {
+		fatal("Failed to load prompt template file", "path", cfg.Gemini.PromptTemplateFile, "error", err)
+	}
+	onReload(cardFile.name, func(cfg *config.Config) (func(), error) {
+		if cfg.Server.AgentCardFile == cardFile.path && !cardFile.changed() {
+			return func() {}, nil
+		}
+		return cardFile.prepare(cfg.Server.AgentCardFile)
+	})
+	onReload(promptFile.name, func(cfg *config.Config) (func(), error) {
+		if cfg.Gemini.PromptTemplateFile == promptFile.path && !promptFile.changed() {
+			return func() {}, nil
+		}
+		return promptFile.prepare(cfg.Gemini.PromptTemplateFile)
+	})
+
+	ctx, stop := context.WithCancel(context.Background())
+	onShutdown(func(context.Context) { stop() })
+	go watchFiles(ctx, cardFile, promptFile)
+	go reloadOnHangup(ctx, configPath)
+}
+
+// watchFiles reloads each file when its modification time or size changes.
+// A file that fails to load keeps serving what was last applied.
+func watchFiles(ctx context.Context, files ...*watchedFile) {
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloadMu.Lock()
+		for _, file := range files {
+			if !file.changed() {
+				continue
+			}
+			if err := file.load(file.path); err != nil {
+				logger.Error("Failed to reload changed file; keeping the previous version", "setting", file.name, "path", file.path, "error", err)
+				// Don't retry until the file changes again
+				if info, err := os.Stat(file.path); err == nil {
+					file.modTime, file.size = info.ModTime(), info.Size()
+				}
+				continue
+			}
+			logger.Info("Reloaded changed file", "audit", true, "setting", file.name, "path", file.path)
+		}
+		reloadMu.Unlock()
+	}
+}
+
+// reloadOnHangup reloads the config on each SIGHUP
+func reloadOnHangup(ctx context.Context, configPath string) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangups:
+		}
+
+		applied, err := reloadConfig(configPath)
+		if err != nil {
+			logger.Error("Refused config reload on SIGHUP", "audit", true, "error", err)
+			continue
+		}
+		logger.Info("Config reloaded on SIGHUP", "audit", true, "applied", applied)
+	}
+}
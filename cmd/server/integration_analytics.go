@@ -0,0 +1,42 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/analytics"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestlog"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	registerIntegration("analytics", func(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine) {
+		personaLibrary := analytics.NewPersonaLibrary(analytics.DefaultLibrarySize)
+		clusterJob := analytics.NewClusterJob(personaLibrary, geminiClient)
+		clusterJob.Start(context.Background(), time.Duration(cfg.Analytics.ClusterInterval))
+		feedbackStats := analytics.NewFeedbackStats()
+		analyticsHandler := analytics.NewHandler(clusterJob, feedbackStats)
+
+		handlerConfig.PersonaLibrary = personaLibrary
+		handlerConfig.FeedbackStats = feedbackStats
+
+		// The reports quote tenants' ideas and a refresh spends on
+		// embeddings, so they answer only to the admin token
+		group := router.Group("/analytics", func(c *gin.Context) {
+			if !requestlog.Authorize(c, cfg.Auth.AdminToken) {
+				c.Abort()
+			}
+		})
+		group.GET("/clusters", analyticsHandler.ServeClusters)
+		group.GET("/feedback", analyticsHandler.ServeFeedback)
+		if cfg.Auth.AdminToken != "" {
+			handlerConfig.OperatorEndpoints["personaClusters"] = "/analytics/clusters"
+			handlerConfig.OperatorEndpoints["feedback"] = "/analytics/feedback"
+		}
+	})
+}
@@ -0,0 +1,22 @@
+//go:build !minimal
+
+package main
+
+import (
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/avatar"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	registerIntegration("avatars", func(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine) {
+		avatarStore := avatar.NewStore(avatar.DefaultTTL, avatar.DefaultMaxImages)
+
+		handlerConfig.AvatarGenerator = geminiClient
+		handlerConfig.AvatarStore = avatarStore
+
+		router.GET("/avatars/:id", avatarStore.ServeAvatar)
+	})
+}
@@ -0,0 +1,130 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/migrate"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profilestore"
+	"github.com/gin-gonic/gin"
+
+	// PostgreSQL and SQLite drivers, registered as "postgres" and "sqlite"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	registerIntegration("database", func(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine) {
+		settings := cfg.Database
+		if settings.URL == "" {
+			return
+		}
+
+		dsn, err := databaseDSN(settings)
+		if err != nil {
+			fatal("Failed to open the database", "error", err)
+		}
+		db, dialect, err := openDatabase(settings.Driver, dsn)
+		if err != nil {
+			fatal("Failed to open the database", "error", err)
+		}
+		db = rotateDatabaseCredentials(db, settings, dsn)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := db.PingContext(ctx); err != nil {
+			fatal("Failed to connect to the database", "driver", settings.Driver, "error", err)
+		}
+		// A SQLite file is the server's own, so its schema is kept current
+		if dialect == migrate.SQLite {
+			migrateDatabase(ctx, db, dialect)
+		} else {
+			warnPendingMigrations(ctx, db, dialect)
+		}
+
+		repository := profilestore.NewSQLRepository(db, dialect)
+		handlerConfig.ProfileRepository = repository
+		readiness.Add("database", repository.Check)
+		logger.Info("Saving generated profiles to the database", "driver", settings.Driver)
+
+		onShutdown(func(context.Context) {
+			if err := db.Close(); err != nil {
+				logger.Warn("Failed to close the database", "error", err)
+			}
+		})
+	})
+}
+
+// RotatedConnLifetime bounds how long a database connection lives when its
+// credentials come from a secret manager, so connections opened with
+// rotated-out credentials are replaced
+const RotatedConnLifetime = 5 * time.Minute
+
+// dsnConnector opens connections with the current DSN
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    atomic.Pointer[string]
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(*c.dsn.Load())
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// rotateDatabaseCredentials reopens db to take rotated credentials when
+// any of the database settings came from a secret manager: new
+// connections use the new ones, idle connections are closed and busy ones
+// retire within RotatedConnLifetime
+func rotateDatabaseCredentials(db *sql.DB, settings config.Database, dsn string) *sql.DB {
+	current := settings
+	fields := map[string]*string{
+		"database.url":      &current.URL,
+		"database.username": &current.Username,
+		"database.password": &current.Password,
+	}
+	rotating := false
+	for name := range fields {
+		if _, ok := resolvedSecrets[name]; ok {
+			rotating = true
+		}
+	}
+	if !rotating {
+		return db
+	}
+
+	connector := &dsnConnector{driver: db.Driver()}
+	connector.dsn.Store(&dsn)
+	maxOpen := db.Stats().MaxOpenConnections
+	db.Close()
+	db = sql.OpenDB(connector)
+	db.SetMaxOpenConns(maxOpen)
+	db.SetConnMaxLifetime(RotatedConnLifetime)
+
+	for name, field := range fields {
+		onSecretRotation(name, func(value string) error {
+			previous := *field
+			*field = value
+			dsn, err := databaseDSN(current)
+			if err != nil {
+				*field = previous
+				return err
+			}
+			connector.dsn.Store(&dsn)
+			// Closes the idle connections; 2 is database/sql's default
+			db.SetMaxIdleConns(0)
+			db.SetMaxIdleConns(2)
+			return nil
+		})
+	}
+	return db
+}
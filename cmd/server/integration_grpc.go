@@ -0,0 +1,59 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+	"net"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2agrpc"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func init() {
+	registerIntegration("grpc", func(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine) {
+		port := cfg.Server.GRPCPort
+		if port == "" {
+			return
+		}
+
+		listener, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			fatal("Failed to listen for gRPC", "port", port, "error", err)
+		}
+		options := []grpc.ServerOption{grpc.StatsHandler(otelgrpc.NewServerHandler())}
+		if serverTLS != nil {
+			options = append(options, grpc.Creds(credentials.NewTLS(serverTLS)))
+		}
+		server := grpc.NewServer(options...)
+
+		onHandler(func(handler *a2a.A2AHandler) {
+			a2agrpc.New(handler.RPCHandler()).Register(server)
+			go func() {
+				if err := server.Serve(listener); err != nil {
+					logger.Error("gRPC server stopped", "error", err)
+				}
+			}()
+			logger.Info("gRPC endpoint available", "service", a2agrpc.ServiceName, "port", port)
+		})
+
+		onShutdown(func(ctx context.Context) {
+			stopped := make(chan struct{})
+			go func() {
+				server.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				server.Stop()
+			}
+		})
+	})
+}
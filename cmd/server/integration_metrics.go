@@ -0,0 +1,18 @@
+//go:build !minimal
+
+package main
+
+import (
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/metrics"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	registerIntegration("metrics", func(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine) {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+		handlerConfig.OperatorEndpoints["metrics"] = "/metrics"
+	})
+}
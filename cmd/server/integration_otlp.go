@@ -0,0 +1,47 @@
+//go:build otlp && !minimal
+
+package main
+
+import (
+	"context"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// The OTLP exporter pulls in the OpenTelemetry SDK, so it is only built
+// with -tags otlp
+func init() {
+	registerIntegration("otlp", func(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine) {
+		endpoint := cfg.Tracing.Endpoint
+		if endpoint == "" {
+			return
+		}
+
+		exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(endpoint))
+		if err != nil {
+			fatal("Failed to create the OTLP exporter", "endpoint", endpoint, "error", err)
+		}
+		provider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+				semconv.ServiceName(cfg.Tracing.ServiceName))),
+		)
+		otel.SetTracerProvider(provider)
+		logger.Info("Exporting traces", "endpoint", endpoint, "service", cfg.Tracing.ServiceName)
+
+		// Flush the spans still batched once the server has drained
+		onShutdown(func(ctx context.Context) {
+			if err := provider.Shutdown(ctx); err != nil {
+				logger.Warn("Failed to flush traces", "error", err)
+			}
+		})
+	})
+}
@@ -0,0 +1,38 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redisstore"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	registerIntegration("redis", func(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine) {
+		settings := cfg.Stores
+		if settings.RedisURL == "" {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), redisstore.OperationTimeout)
+		defer cancel()
+		store, err := redisstore.Open(ctx, settings.RedisURL, settings.RedisKeyPrefix, time.Duration(settings.TTL))
+		if err != nil {
+			fatal("Failed to open the Redis task store", "error", err)
+		}
+		handlerConfig.TaskStore = store
+		readiness.Add("redis", store.Check)
+
+		onShutdown(func(context.Context) {
+			if err := store.Close(); err != nil {
+				logger.Warn("Failed to close the Redis task store", "error", err)
+			}
+		})
+	})
+}
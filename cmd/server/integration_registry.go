@@ -0,0 +1,46 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/registry"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	registerIntegration("registry", func(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine) {
+		registryURL := cfg.Registry.URL
+		if registryURL == "" {
+			return
+		}
+
+		registrar, err := registry.New(registry.Config{
+			URL:      registryURL,
+			Token:    cfg.Registry.Token,
+			AgentID:  cfg.Registry.AgentID,
+			Card:     handlerConfig.AgentCard,
+			Interval: time.Duration(cfg.Registry.HeartbeatInterval),
+		})
+		if err != nil {
+			fatal("Invalid registry configuration", "error", err)
+		}
+
+		ctx, stop := context.WithCancel(context.Background())
+		go registrar.Run(ctx)
+
+		onShutdown(func(shutdownCtx context.Context) {
+			stop()
+			if err := registrar.Deregister(shutdownCtx); err != nil {
+				logger.Warn("Failed to deregister from the agent registry", "error", err)
+				return
+			}
+			logger.Info("Deregistered from the agent registry", "url", registryURL)
+		})
+	})
+}
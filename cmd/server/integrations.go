@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/gin-gonic/gin"
+)
+
+// integration wires an optional feature into the server. It reads its
+// settings from cfg, and may fill in handler config and add routes.
+// Integrations register themselves from files excluded by the "minimal"
+// build tag.
+type integration func(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine)
+
+var integrations = map[string]integration{}
+
+func registerIntegration(name string, setup integration) {
+	integrations[name] = setup
+}
+
+// setupIntegrations runs every compiled-in integration in name order
+func setupIntegrations(cfg *config.Config, geminiClient *profiler.GeminiClient, handlerConfig *a2a.HandlerConfig, router *gin.Engine) {
+	names := make([]string, 0, len(integrations))
+	for name := range integrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		integrations[name](cfg, geminiClient, handlerConfig, router)
+	}
+	logger.Info("Integrations enabled", "integrations", names)
+}
+
+// handlerHooks run once the A2A handler is built
+var handlerHooks []func(handler *a2a.A2AHandler)
+
+// onHandler adds a hook for integrations that serve the A2A handler on
+// another transport
+func onHandler(hook func(handler *a2a.A2AHandler)) {
+	handlerHooks = append(handlerHooks, hook)
+}
+
+// runHandlerHooks hands the built A2A handler to the integrations
+func runHandlerHooks(handler *a2a.A2AHandler) {
+	for _, hook := range handlerHooks {
+		hook(handler)
+	}
+}
+
+// shutdownHooks run, last registered first, once the server has drained
+// after SIGINT or SIGTERM
+var shutdownHooks []func(ctx context.Context)
+
+// onShutdown adds a hook for integrations that need to clean up outside
+// the process, such as leaving a registry
+func onShutdown(hook func(ctx context.Context)) {
+	shutdownHooks = append(shutdownHooks, hook)
+}
+
+// ShutdownTimeout bounds how long the shutdown hooks may take together
+const ShutdownTimeout = 10 * time.Second
+
+// runShutdownHooks runs the shutdown hooks within ShutdownTimeout
+func runShutdownHooks() {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	for i := len(shutdownHooks) - 1; i >= 0; i-- {
+		shutdownHooks[i](ctx)
+	}
+}
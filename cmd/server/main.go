@@ -1,53 +1,66 @@
 package main
 
 import (
-	"log"
+	"fmt"
 	"os"
+	"strings"
 
-	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
-	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
-	"github.com/gin-gonic/gin"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
 )
 
-func main() {
-
-	// Get API key from environment
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		log.Fatal("GEMINI_API_KEY environment variable is required")
-	}
+var logger = logging.For("server")
 
-	// Initialize Gemini client
-	geminiClient, err := profiler.NewGeminiClient(apiKey)
-	if err != nil {
-		log.Fatalf("Failed to create Gemini client: %v", err)
-	}
-	defer geminiClient.Close()
-
-	a2aHandler := a2a.NewA2AHandler(geminiClient)
-
-	router := gin.Default()
+// command is a subcommand of the server binary
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
 
-	// Endpoints
-	router.GET("/.well-known/agent.json", a2aHandler.ServeAgentCard)
+var commands = []command{
+	{"serve", "Start the HTTP server (default)", runServe},
+	{"self-test", "Check configuration and run one profile generation against Gemini", runSelfTest},
+	{"migrate", "Apply, revert or list store schema migrations (up|down|status)", runMigrate},
+	{"seed-demo", "Send sample business ideas to a running server", runSeedDemo},
+	{"replay", "Run a saved request through the current code (mock or dry-run)", runReplay},
+	{"backup", "Back up persisted data", runBackup},
+}
 
-	router.POST("/a2a/profiler", a2aHandler.HandleProfiler)
+func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	router.GET("/health", func(c *gin.Context) {
-		c.String(200, "OK")
-	})
+	if name == "help" {
+		usage()
+		return
+	}
 
-	// server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(args); err != nil {
+				fatal("Command failed", "command", name, "error", err)
+			}
+			return
+		}
 	}
 
-	log.Printf("Customer Profiler Agent starting on port %s", port)
-	log.Printf("Agent card available at: http://localhost:%s/.well-known/agent.json", port)
-	log.Printf("A2A endpoint available at: http://localhost:%s/a2a/profiler", port)
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
 
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [command] [flags]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for a command's flags.\n", os.Args[0])
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...interface{}) {
+	logger.Error(msg, args...)
+	os.Exit(1)
 }
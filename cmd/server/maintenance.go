@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/migrate"
+)
+
+// errNoPersistentStore is returned by commands that need a database when
+// no database URL is configured
+var errNoPersistentStore = errors.New("no persistent store is configured; set DATABASE_URL or database.url")
+
+// databaseDSN is the configured database URL, with database.username and
+// database.password, if set, in place of its credentials
+func databaseDSN(settings config.Database) (string, error) {
+	if settings.URL == "" || (settings.Username == "" && settings.Password == "") {
+		return settings.URL, nil
+	}
+	// The URL may hold a password, so it is left out of the error
+	parsed, err := url.Parse(settings.URL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", errors.New("database.url must be a URL, such as postgres://db.internal/profiler, to take database.username and database.password")
+	}
+	username, password := settings.Username, settings.Password
+	if parsed.User != nil {
+		if username == "" {
+			username = parsed.User.Username()
+		}
+		if password == "" {
+			password, _ = parsed.User.Password()
+		}
+	}
+	parsed.User = url.UserPassword(username, password)
+	return parsed.String(), nil
+}
+
+// openDatabase connects to dsn with a database/sql driver
+func openDatabase(driver, dsn string) (*sql.DB, migrate.Dialect, error) {
+	if dsn == "" {
+		return nil, "", errNoPersistentStore
+	}
+
+	dialect, err := migrate.DialectForDriver(driver)
+	if err != nil {
+		return nil, "", err
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		if strings.Contains(err.Error(), "unknown driver") {
+			return nil, "", fmt.Errorf("database driver %q is not compiled into this binary", driver)
+		}
+		return nil, "", fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows one writer at a time; one connection queues writes
+	// instead of failing them with SQLITE_BUSY
+	if dialect == migrate.SQLite {
+		db.SetMaxOpenConns(1)
+	}
+	return db, dialect, nil
+}
+
+// runMigrate applies, reverts or lists the store schema migrations
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	configPath := configFlag(flags)
+	driver := flags.String("driver", "", "database/sql driver name, postgres or sqlite (default from the config)")
+	dsn := flags.String("dsn", "", "database connection string (default from the config or $DATABASE_URL)")
+	steps := flags.Int("steps", 1, "number of migrations to revert with down")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: migrate [flags] up|down|status\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	action := flags.Arg(0)
+	if action == "" {
+		action = "up"
+	}
+
+	cfg := loadConfig(*configPath)
+	if *driver == "" {
+		*driver = cfg.Database.Driver
+	}
+	if *dsn == "" {
+		configured, err := databaseDSN(cfg.Database)
+		if err != nil {
+			return err
+		}
+		*dsn = configured
+	}
+
+	db, dialect, err := openDatabase(*driver, *dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "up":
+		ran, err := migrator.Up(ctx)
+		for _, m := range ran {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(ran) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown migrate action %q", action)
+	}
+}
+
+// autoMigrate applies pending migrations at startup when the config asks to
+func autoMigrate(settings config.Database) {
+	if !settings.AutoMigrate {
+		return
+	}
+
+	dsn, err := databaseDSN(settings)
+	if err != nil {
+		fatal("Auto-migrate failed", "error", err)
+	}
+	db, dialect, err := openDatabase(settings.Driver, dsn)
+	if errors.Is(err, errNoPersistentStore) {
+		logger.Warn("Auto-migrate is on but no database URL is set; skipping migrations")
+		return
+	}
+	if err != nil {
+		fatal("Auto-migrate failed", "error", err)
+	}
+	defer db.Close()
+
+	migrateDatabase(context.Background(), db, dialect)
+}
+
+// migrateDatabase applies pending migrations, exiting if any fails
+func migrateDatabase(ctx context.Context, db *sql.DB, dialect migrate.Dialect) {
+	migrator, err := migrate.New(db, dialect)
+	if err != nil {
+		fatal("Auto-migrate failed", "error", err)
+	}
+	ran, err := migrator.Up(ctx)
+	if err != nil {
+		fatal("Auto-migrate failed", "error", err)
+	}
+	logger.Info("Auto-migrate finished", "applied", len(ran))
+}
+
+// warnPendingMigrations warns at startup about migrations the database
+// still lacks, whose tables saves would fail on
+func warnPendingMigrations(ctx context.Context, db *sql.DB, dialect migrate.Dialect) {
+	migrator, err := migrate.New(db, dialect)
+	if err != nil {
+		logger.Warn("Failed to check the database schema", "error", err)
+		return
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		logger.Warn("Failed to check the database schema", "error", err)
+		return
+	}
+	var pending []string
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, fmt.Sprintf("%04d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		logger.Warn("The database has pending migrations; run the migrate command or set AUTO_MIGRATE", "pending", pending)
+	}
+}
+
+// runBackup backs up persisted data
+func runBackup(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return errNoPersistentStore
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/ratelimit"
+)
+
+// setupRateLimit limits generation requests per client IP and per API key
+// when the config sets a limit
+func setupRateLimit(settings config.RateLimit, handlerConfig *a2a.HandlerConfig) {
+	if settings.IPPerMinute == 0 && settings.KeyPerMinute == 0 {
+		return
+	}
+
+	limits := &a2a.RateLimitConfig{}
+	if settings.IPPerMinute > 0 {
+		limits.PerIP = ratelimit.New(ratelimit.Limits{PerMinute: settings.IPPerMinute})
+	}
+	if settings.KeyPerMinute > 0 {
+		limits.PerKey = ratelimit.New(ratelimit.Limits{PerMinute: settings.KeyPerMinute})
+	}
+	handlerConfig.RateLimit = limits
+	logger.Info("Rate limits enabled",
+		"ip_per_minute", settings.IPPerMinute,
+		"key_per_minute", settings.KeyPerMinute)
+}
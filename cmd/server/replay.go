@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/gin-gonic/gin"
+)
+
+// runReplay runs a saved request body through the current handler code and
+// prints the response, to reproduce a reported output after code or prompt
+// changes
+func runReplay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	file := flags.String("file", "-", "request body to replay; - reads stdin")
+	mode := flags.String("mode", string(a2a.ReplayMock), "mock answers with a fixed persona; dry-run calls Gemini")
+	configPath := configFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	replayMode, err := a2a.ParseReplayMode(*mode)
+	if err != nil {
+		return err
+	}
+
+	// Keep gin's debug output off stdout, which carries the response
+	gin.SetMode(gin.ReleaseMode)
+
+	var body []byte
+	if *file == "-" {
+		body, err = io.ReadAll(os.Stdin)
+	} else {
+		body, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+
+	cfg := loadConfig(*configPath)
+	config := a2a.HandlerConfig{PolicyEnforcer: loadPolicies(cfg.Policy), Model: cfg.Gemini.Model}
+
+	var generator profiler.ProfileGenerator
+	if replayMode == a2a.ReplayDryRun {
+		geminiClient := newGeminiClient(cfg)
+		defer geminiClient.Close()
+		generator = geminiClient
+	}
+
+	resp := a2a.Replay(generator, config, body, replayMode)
+	defer resp.Body.Close()
+
+	fmt.Fprintf(os.Stderr, "HTTP %d\n", resp.StatusCode)
+	_, err = io.Copy(os.Stdout, resp.Body)
+	return err
+}
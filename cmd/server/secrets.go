@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/secrets"
+)
+
+// secretProvider opens the secret manager of a reference scheme from the
+// config. Providers register themselves from files excluded by the
+// "minimal" build tag.
+type secretProvider func(ctx context.Context, cfg *config.Config) (secrets.Provider, error)
+
+var secretProviders = map[string]secretProvider{}
+
+func registerSecretProvider(scheme string, open secretProvider) {
+	secretProviders[scheme] = open
+}
+
+// secretSettings are the settings that may be written as secret
+// references, keyed as the config file spells them
+func secretSettings(cfg *config.Config) map[string]*string {
+	return map[string]*string{
+		"gemini.apiKey":     &cfg.Gemini.APIKey,
+		"database.url":      &cfg.Database.URL,
+		"database.username": &cfg.Database.Username,
+		"database.password": &cfg.Database.Password,
+	}
+}
+
+// resolvedSecret is a setting read from a secret manager at startup
+type resolvedSecret struct {
+	ref      secrets.Ref
+	provider secrets.Provider
+	value    string
+}
+
+// resolvedSecrets are the settings resolveSecrets read, for rotateSecrets
+var resolvedSecrets = map[string]resolvedSecret{}
+
+// resolveSecrets replaces the settings written as secret references with
+// the secrets they name, exiting if one can't be read
+func resolveSecrets(cfg *config.Config) {
+	settings := secretSettings(cfg)
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx := context.Background()
+	providers := map[string]secrets.Provider{}
+	for _, name := range names {
+		setting := settings[name]
+		ref, ok := secrets.ParseRef(*setting)
+		if !ok {
+			continue
+		}
+
+		provider, ok := providers[ref.Scheme]
+		if !ok {
+			open := secretProviders[ref.Scheme]
+			if open == nil {
+				fatal("A setting is a secret reference, but this binary was built without its secret manager; build it without the minimal tag", "setting", name, "scheme", ref.Scheme)
+			}
+			var err error
+			if provider, err = open(ctx, cfg); err != nil {
+				fatal("Failed to open secret manager", "scheme", ref.Scheme, "error", err)
+			}
+			providers[ref.Scheme] = provider
+		}
+
+		value, err := secrets.Read(ctx, provider, ref)
+		if err != nil {
+			fatal("Failed to read secret", "setting", name, "ref", ref.String(), "error", err)
+		}
+		*setting = value
+		resolvedSecrets[name] = resolvedSecret{ref: ref, provider: provider, value: value}
+		logger.Info("Read setting from secret manager", "setting", name, "ref", ref.String())
+	}
+}
+
+// secretAppliers apply rotated values of resolved settings, by setting
+var secretAppliers = map[string]func(value string) error{}
+
+// onSecretRotation adds how a rotated value of a setting is applied
+func onSecretRotation(name string, apply func(value string) error) {
+	secretAppliers[name] = apply
+}
+
+// rotateSecrets reads the resolved settings again every rotation interval,
+// passing changed values to their appliers. Settings without one keep the
+// value they started with.
+func rotateSecrets(settings config.Secrets) {
+	interval := time.Duration(settings.RotationInterval)
+	if interval <= 0 {
+		return
+	}
+
+	names := make([]string, 0, len(resolvedSecrets))
+	for name := range resolvedSecrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rotator := new(secrets.Rotator)
+	for _, name := range names {
+		secret := resolvedSecrets[name]
+		apply := secretAppliers[name]
+		if apply == nil {
+			logger.Warn("Setting is read from a secret manager only at startup; restart to apply a rotated value", "setting", name)
+			continue
+		}
+		rotator.Add(name, secret.ref, secret.provider, secret.value, apply)
+	}
+	if rotator.Len() == 0 {
+		return
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	onShutdown(func(context.Context) { stop() })
+	go rotator.Run(ctx, interval)
+	logger.Info("Rotating secrets", "secrets", rotator.Len(), "interval", interval)
+}
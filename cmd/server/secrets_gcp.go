@@ -0,0 +1,17 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/secretmanager"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/secrets"
+)
+
+func init() {
+	registerSecretProvider(secrets.SchemeGCP, func(ctx context.Context, cfg *config.Config) (secrets.Provider, error) {
+		return secretmanager.New(ctx, cfg.Secrets.GCP.Project)
+	})
+}
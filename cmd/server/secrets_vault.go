@@ -0,0 +1,42 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/secrets"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/vault"
+)
+
+func init() {
+	registerSecretProvider(secrets.SchemeVault, func(ctx context.Context, cfg *config.Config) (secrets.Provider, error) {
+		settings := cfg.Secrets.Vault
+		if settings.Address == "" {
+			return nil, errors.New("secrets.vault.address (VAULT_ADDR) is not set")
+		}
+		client, err := vault.New(ctx, vault.Config{
+			Address:             settings.Address,
+			Namespace:           settings.Namespace,
+			AuthMethod:          settings.AuthMethod,
+			AuthMount:           settings.AuthMount,
+			Token:               settings.Token,
+			RoleID:              settings.RoleID,
+			SecretID:            settings.SecretID,
+			Role:                settings.Role,
+			KubernetesTokenFile: settings.KubernetesTokenFile,
+			CACertFile:          settings.CACertFile,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Keep the token and leases alive for as long as the server runs
+		renewCtx, stop := context.WithCancel(context.Background())
+		onShutdown(func(context.Context) { stop() })
+		go client.Run(renewCtx)
+		return client, nil
+	})
+}
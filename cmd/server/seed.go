@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2aclient"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+)
+
+// demoIdeas cover each fallback industry so the demo exercises a spread of personas
+var demoIdeas = []string{
+	"A subscription box of single-origin coffee from small East African farms",
+	"An online boutique for secondhand designer shoes",
+	"A mobile app with 15-minute home workouts for new parents",
+	"Weekend coding bootcamps for high school students",
+	"Invoicing software for freelance photographers",
+}
+
+// runSeedDemo sends the demo ideas to a running server, filling its persona
+// library, task store and request log for demos
+func runSeedDemo(args []string) error {
+	flags := flag.NewFlagSet("seed-demo", flag.ExitOnError)
+	baseURL := flags.String("url", "http://localhost:8080", "base URL of the running agent")
+	apiKey := flags.String("api-key", os.Getenv("API_KEY"), "API key sent in the X-API-Key header (default $API_KEY)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	header := make(http.Header)
+	if *apiKey != "" {
+		header.Set("X-API-Key", *apiKey)
+	}
+	client := a2aclient.New(a2aclient.Config{
+		URL:        strings.TrimRight(*baseURL, "/") + "/a2a/profiler",
+		HTTPClient: &http.Client{Timeout: 2 * time.Minute},
+		Header:     header,
+	})
+
+	failed := 0
+	for _, idea := range demoIdeas {
+		state, err := sendDemoIdea(client, idea)
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL %s: %v\n", idea, err)
+			continue
+		}
+		if state != a2a.StateCompleted {
+			failed++
+		}
+		fmt.Printf("%-9s %s\n", state, idea)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d demo ideas failed", failed, len(demoIdeas))
+	}
+	return nil
+}
+
+// sendDemoIdea sends one message/send request and returns the task state
+func sendDemoIdea(client *a2aclient.Client, idea string) (string, error) {
+	task, err := client.SendMessage(context.Background(), a2a.MessageParams{
+		Message: a2a.A2AMessage{
+			Kind:  "message",
+			Role:  a2a.RoleUser,
+			Parts: []a2a.MessagePart{a2a.TextPart(idea)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return task.Status.State, nil
+}
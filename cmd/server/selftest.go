@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+)
+
+// runSelfTest verifies the configuration and that Gemini answers a real
+// request, for use as a deploy smoke check
+func runSelfTest(args []string) error {
+	flags := flag.NewFlagSet("self-test", flag.ExitOnError)
+	idea := flags.String("idea", "a neighbourhood coffee shop with a co-working space", "business idea to profile")
+	timeout := flags.Duration("timeout", 60*time.Second, "how long to wait for Gemini")
+	configPath := configFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	fmt.Println("ok   config")
+
+	card, err := agent.BuildCard(a2a.AgentCardConfig(a2a.HandlerConfig{PublicBaseURL: cfg.Server.PublicBaseURL}))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("ok   agent card (version %s)\n", card.Version)
+
+	loadPolicies(cfg.Policy)
+	fmt.Println("ok   redaction and usage policies")
+
+	geminiClient := newGeminiClient(cfg)
+	defer geminiClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := geminiClient.GenerateCustomerProfiles(ctx, *idea, profiler.GenerateOptions{NoCache: true})
+	if err != nil {
+		return fmt.Errorf("profile generation: %w", err)
+	}
+	if resp.Degraded {
+		return fmt.Errorf("profile generation: served the %s fallback template instead of a model response", resp.FallbackIndustry)
+	}
+	fmt.Printf("ok   generated %d profile(s) with %s in %s\n", len(resp.Profiles), geminiClient.ModelName(), time.Since(start).Round(time.Millisecond))
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"flag"
+	"sync/atomic"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2aws"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/accesslog"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/health"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/policy"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestid"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestlog"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/tracing"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/agentkit"
+	"github.com/gin-gonic/gin"
+)
+
+// readiness holds the dependency checks of /readyz; setup code adds a check
+// for each dependency it connects
+var readiness = health.New()
+
+// runServe starts the HTTP server; it is the default command
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := configFlag(flags)
+	port := flags.String("port", "", "port to listen on (default from the config, $PORT or 8080)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := loadConfig(*configPath)
+	if *port != "" {
+		cfg.Server.Port = *port
+	}
+
+	usagePolicy := loadPolicies(cfg.Policy)
+	autoMigrate(cfg.Database)
+
+	geminiClient := newGeminiClient(cfg)
+	defer geminiClient.Close()
+	readiness.Add("gemini", geminiClient.Check)
+	onSecretRotation("gemini.apiKey", geminiClient.SetAPIKey)
+
+	requestLog := requestlog.New(requestlog.DefaultCapacity)
+
+	handlerConfig := a2a.HandlerConfig{
+		PromptOverrideKey: cfg.Auth.PromptOverrideKey,
+		PublicBaseURL:     cfg.Server.PublicBaseURL,
+		ExtendedCardToken: cfg.Auth.ExtendedCardToken,
+		DisclosureFooter:  cfg.Output.DisclosureFooter,
+		Model:             cfg.Gemini.Model,
+		RequestLog:        requestLog,
+		PolicyEnforcer:    usagePolicy,
+		BatchConcurrency:  cfg.Server.BatchConcurrency,
+		CompressDataAbove: cfg.Limits.DataCompressionThreshold,
+		RegenerateBelow:   cfg.Limits.FeedbackRegenerateBelow,
+		MethodAliases:     cfg.Methods.Aliases,
+		DisabledMethods:   cfg.Methods.Disabled,
+		RejectAliases:     cfg.Methods.RejectAliases,
+		SoftLimits:        cfg.Limits.Mode == "soft",
+		ReadOnly:          cfg.Server.ReadOnly,
+		ReadOnlyETA:       cfg.Server.ReadOnlyETA,
+		GenerationTimeout: time.Duration(cfg.Limits.GenerationTimeout),
+		MaxBodySize:       int64(cfg.Limits.MaxBodySize),
+		MaxJSONDepth:      cfg.Limits.MaxJSONDepth,
+		MaxMessageParts:   cfg.Limits.MaxMessageParts,
+		Workers:           cfg.Workers.Concurrency,
+		WorkerQueue:       cfg.Workers.QueueSize,
+		WhenBusy:          cfg.Workers.WhenBusy,
+		TrustedProxies:    cfg.Server.TrustedProxies,
+	}
+	setupDemo(cfg.Demo, &handlerConfig)
+	setupRateLimit(cfg.RateLimit, &handlerConfig)
+	setupAuth(cfg.Auth, &handlerConfig)
+	setupTLS(cfg.Server.TLS, cfg.Server.Port, &handlerConfig)
+	setupStores(cfg.Stores, cfg.Database, &handlerConfig)
+
+	handlerConfig.OperatorEndpoints = map[string]string{"policy": "/v1/policy"}
+	if cfg.Auth.DebugToken != "" {
+		handlerConfig.OperatorEndpoints["requestLog"] = "/debug/requests"
+	}
+	if cfg.Auth.PreferencesToken != "" {
+		handlerConfig.OperatorEndpoints["tenantPreferences"] = "/v1/tenants/{tenant}/preferences"
+	}
+	if cfg.Auth.AdminToken != "" {
+		handlerConfig.OperatorEndpoints["admin"] = "/admin/stats"
+	}
+
+	if path := cfg.Output.BrandingFile; path != "" {
+		branding, err := agent.LoadBranding(path)
+		if err != nil {
+			fatal("Failed to load branding", "error", err)
+		}
+		handlerConfig.Branding = branding
+	}
+
+	card, err := agent.BuildCard(a2a.AgentCardConfig(handlerConfig))
+	if err != nil {
+		return err
+	}
+	handlerConfig.AgentCard = card
+
+	tracing.Setup()
+	if cfg.Tracing.Endpoint != "" && integrations["otlp"] == nil {
+		logger.Warn("tracing.endpoint is set, but this binary was built without the otlp tag; spans will not be exported")
+	}
+
+	// Gin's own access log is replaced by the structured one
+	router := gin.New()
+	// Forwarding headers name the client only when a trusted proxy sent them
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return err
+	}
+	router.Use(gin.Recovery(), tracing.Middleware(), requestid.Middleware(), accesslog.Middleware())
+	// Request logging can be switched on and off through the admin API
+	requests := cfg.Logging.Requests
+	requestLogging := new(atomic.Bool)
+	requestLogging.Store(requests.Enabled)
+	router.Use(a2a.RequestLoggingMiddleware(a2a.RequestLoggingConfig{
+		Enabled:       requestLogging,
+		MaxBodyBytes:  requests.MaxBodyBytes,
+		SampleRate:    requests.SampleRate,
+		RedactHeaders: requests.RedactHeaders,
+		RedactFields:  requests.RedactFields,
+	}))
+	allowOrigin := setupCORS(cfg.CORS, router)
+
+	// Optional integrations add their routes and fill in handler config
+	setupIntegrations(cfg, geminiClient, &handlerConfig, router)
+	rotateSecrets(cfg.Secrets)
+
+	a2aHandler := a2a.NewA2AHandler(geminiClient, handlerConfig)
+	runHandlerHooks(a2aHandler)
+	setupAdmin(cfg, *configPath, requestLogging, a2aHandler, geminiClient, router)
+	setupHotReload(cfg, *configPath, card, a2aHandler, geminiClient)
+
+	// Endpoints
+	router.GET("/.well-known/agent.json", a2aHandler.ServeAgentCard)
+
+	router.POST("/a2a/profiler", a2aHandler.HandleProfiler)
+	router.GET("/a2a/ws", gin.WrapH(a2aws.New(a2aHandler.RPCHandler(), allowOrigin)))
+
+	// The same skills on the generic agentkit server, for comparing it
+	// with the full endpoint before sibling agents build on it
+	if path := cfg.Server.AgentkitPath; path != "" {
+		kit, err := agentkit.New(agentkit.Config{
+			Card:      card,
+			Skills:    a2aHandler.Skills(),
+			Tasks:     handlerConfig.TaskStore,
+			Logger:    logging.For("agentkit"),
+			RedactLog: redact.ForLog,
+		})
+		if err != nil {
+			return err
+		}
+		router.POST(path, a2aHandler.RequireAuth(), a2aHandler.GuardKit(), kit.ServeRPC)
+		logger.Info("agentkit endpoint available", "path", path)
+	}
+
+	router.GET("/v1/policy", usagePolicy.Handler)
+
+	if handlerConfig.ProfileRepository != nil {
+		router.GET("/api/v1/profiles", a2aHandler.ServeProfiles(cfg.Auth.AdminToken))
+		router.GET("/api/v1/profiles/:taskId", a2aHandler.ServeProfile(cfg.Auth.AdminToken))
+	}
+
+	router.GET("/debug/requests", requestLog.Handler(cfg.Auth.DebugToken))
+	router.POST("/debug/requests/:traceID/replay", a2aHandler.ServeReplay(cfg.Auth.DebugToken))
+
+	preferences := a2aHandler.ServePreferences(cfg.Auth.PreferencesToken)
+	router.GET("/v1/tenants/:tenant/preferences", preferences)
+	router.PUT("/v1/tenants/:tenant/preferences", preferences)
+	router.DELETE("/v1/tenants/:tenant/preferences", preferences)
+
+	router.GET("/health", func(c *gin.Context) {
+		c.String(200, "OK")
+	})
+	router.GET("/healthz", health.Liveness)
+	router.GET("/readyz", readiness.Readiness)
+
+	// server
+	base := serverScheme() + "://localhost:" + cfg.Server.Port
+	logger.Info("Customer Profiler Agent starting",
+		"port", cfg.Server.Port,
+		"agent_card", base+"/.well-known/agent.json",
+		"endpoint", base+"/a2a/profiler")
+
+	return listenAndServe(":"+cfg.Server.Port, router, serverTLS, a2aHandler, time.Duration(cfg.Server.ShutdownGracePeriod))
+}
+
+// loadPolicies applies the redaction policy and returns the usage policy
+// named in the config
+func loadPolicies(settings config.Policy) *policy.Policy {
+	if path := settings.RedactionFile; path != "" {
+		policy, err := redact.LoadPolicy(path)
+		if err != nil {
+			fatal("Failed to load redaction policy", "error", err)
+		}
+		redact.SetPolicy(policy)
+	}
+
+	usagePolicy := policy.DefaultPolicy()
+	if path := settings.UsageFile; path != "" {
+		loaded, err := policy.LoadPolicy(path)
+		if err != nil {
+			fatal("Failed to load usage policy", "error", err)
+		}
+		usagePolicy = loaded
+	}
+	return usagePolicy
+}
+
+// newGeminiClient builds the Gemini client from the config
+func newGeminiClient(cfg *config.Config) *profiler.GeminiClient {
+	if cfg.Gemini.APIKey == "" {
+		fatal("GEMINI_API_KEY environment variable or gemini.apiKey setting is required")
+	}
+
+	geminiClient, err := profiler.NewGeminiClient(cfg.Gemini.APIKey, profiler.ClientConfig{
+		CacheTTL:               time.Duration(cfg.Cache.TTL),
+		FallbackEnabled:        cfg.Gemini.FallbackEnabled,
+		SegmentConcurrency:     cfg.Gemini.SegmentConcurrency,
+		MaxInputTokens:         cfg.Gemini.MaxInputTokens,
+		MaxOutputTokensCeiling: cfg.Gemini.MaxOutputTokensCeiling,
+		Model:                  cfg.Gemini.Model,
+	})
+	if err != nil {
+		fatal("Failed to create Gemini client", "error", err)
+	}
+	return geminiClient
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+)
+
+// listenAndServe serves router on addr, over TLS when tlsConfig is set,
+// until SIGINT or SIGTERM, then shuts down gracefully: it stops accepting
+// connections and waits up to grace for in-flight requests and generations,
+// including background tasks, before canceling what is left. The shutdown
+// hooks run once it has drained.
+func listenAndServe(addr string, router http.Handler, tlsConfig *tls.Config, handler *a2a.A2AHandler, grace time.Duration) error {
+	// Canceling the base context ends requests that outlive the grace
+	// period, such as WebSocket connections, which Shutdown doesn't track
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+
+	server := &http.Server{
+		Addr:        addr,
+		Handler:     router,
+		TLSConfig:   tlsConfig,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	served := make(chan error, 1)
+	go func() {
+		if tlsConfig != nil {
+			// The certificate is already in tlsConfig
+			served <- server.ListenAndServeTLS("", "")
+			return
+		}
+		served <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("server failed to start: %w", err)
+	case sig := <-signals:
+		logger.Info("Received signal, draining", "signal", sig.String(), "grace_period", grace.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	var drained sync.WaitGroup
+	drained.Add(1)
+	go func() {
+		defer drained.Done()
+		if err := handler.Drain(ctx); err != nil {
+			logger.Warn("Generations did not finish within the grace period", "grace_period", grace.String(), "error", err)
+		}
+	}()
+
+	err := server.Shutdown(ctx)
+	// Canceled generations still answer their streams before the
+	// connections are closed
+	drained.Wait()
+	if err != nil {
+		logger.Warn("Requests did not finish within the grace period", "grace_period", grace.String(), "error", err)
+		server.Close()
+	}
+	if err := <-served; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Warn("Server stopped with an error", "error", err)
+	}
+	cancelBase()
+
+	runShutdownHooks()
+	logger.Info("Shutdown complete")
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+)
+
+// snapshotter is an in-memory store that can be saved to and loaded from disk
+type snapshotter interface {
+	Snapshot(path string) error
+	Restore(path string) (int, error)
+}
+
+// setupStores creates the in-memory task, context and preference stores. With
+// a snapshot directory set they are restored from the last snapshot, with
+// legacy task results upgraded, and written back every snapshot interval
+// and once more at shutdown. With a Redis URL set the redis integration
+// replaces the task store, which is then left out of snapshots. A database
+// URL needs the database integration, which saves generated profiles.
+func setupStores(settings config.Stores, database config.Database, handlerConfig *a2a.HandlerConfig) {
+	if settings.RedisURL != "" && integrations["redis"] == nil {
+		fatal("stores.redisUrl is set, but this binary was built without Redis support; build it without the minimal tag")
+	}
+	if database.URL != "" && integrations["database"] == nil {
+		fatal("database.url is set, but this binary was built without database drivers; build it without the minimal tag")
+	}
+
+	ttl := time.Duration(settings.TTL)
+
+	taskStore := a2a.NewMemoryTaskStore(a2a.DefaultTaskStoreCapacity, ttl)
+	contextStore := a2a.NewMemoryContextStore(a2a.DefaultContextStoreCapacity, ttl)
+	preferenceStore := a2a.NewMemoryPreferenceStore(a2a.DefaultPreferenceStoreCapacity)
+	handlerConfig.TaskStore = taskStore
+	handlerConfig.ContextStore = contextStore
+	handlerConfig.PreferenceStore = preferenceStore
+	handlerConfig.IdempotencyTTL = time.Duration(settings.IdempotencyTTL)
+
+	dir := settings.SnapshotDir
+	if dir == "" {
+		return
+	}
+	if handlerConfig.Demo != nil {
+		logger.Warn("Demo mode keeps nothing on disk; ignoring the store snapshot directory")
+		return
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		fatal("Failed to create the store snapshot directory", "error", err)
+	}
+
+	stores := map[string]snapshotter{
+		filepath.Join(dir, "contexts.json"):    contextStore,
+		filepath.Join(dir, "preferences.json"): preferenceStore,
+	}
+	if settings.RedisURL == "" {
+		stores[filepath.Join(dir, "tasks.json")] = taskStore
+	}
+	for path, store := range stores {
+		restored, err := store.Restore(path)
+		if err != nil {
+			fatal("Failed to restore store snapshot", "path", path, "error", err)
+		}
+		logger.Info("Restored store snapshot", "path", path, "entries", restored)
+	}
+	if upgraded := taskStore.UpgradeLegacyResults(); upgraded > 0 {
+		logger.Info("Upgraded legacy task results to the structured artifact format", "tasks", upgraded)
+	}
+
+	snapshot := func() {
+		for path, store := range stores {
+			if err := store.Snapshot(path); err != nil {
+				logger.Warn("Failed to snapshot store", "path", path, "error", err)
+			}
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(settings.SnapshotInterval))
+		defer ticker.Stop()
+		for range ticker.C {
+			snapshot()
+		}
+	}()
+
+	// Keep the tasks that finished while the server drained
+	onShutdown(func(context.Context) {
+		snapshot()
+		logger.Info("Wrote store snapshots", "dir", dir)
+	})
+}
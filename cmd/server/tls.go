@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/config"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// serverTLS is the TLS config of the HTTP and gRPC listeners, nil when they
+// serve plain text
+var serverTLS *tls.Config
+
+// setupTLS loads the server certificate, or sets up ACME to obtain one,
+// and the client CAs the config names. Client certificates are verified
+// when presented but not demanded by the handshake, so the agent card and
+// health check stay reachable; the A2A endpoint is what requires them.
+func setupTLS(settings config.TLS, httpsPort string, handlerConfig *a2a.HandlerConfig) {
+	var manager *autocert.Manager
+	switch {
+	case len(settings.AutocertHosts) > 0:
+		manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(settings.AutocertHosts...),
+			Cache:      autocert.DirCache(settings.AutocertCacheDir),
+			Email:      settings.AutocertEmail,
+		}
+		if url := settings.AutocertDirectoryURL; url != "" {
+			manager.Client = &acme.Client{DirectoryURL: url}
+		}
+		// The manager's config answers TLS-ALPN challenges on the HTTPS
+		// port, so certificates can be issued without the redirect port
+		serverTLS = manager.TLSConfig()
+		serverTLS.MinVersion = tls.VersionTLS12
+		logger.Info("Obtaining certificates over ACME", "hosts", settings.AutocertHosts, "cache_dir", settings.AutocertCacheDir)
+	case settings.CertFile != "":
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			fatal("Failed to load the TLS certificate", "error", err)
+		}
+		serverTLS = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	default:
+		return
+	}
+
+	if port := settings.RedirectPort; port != "" {
+		serveRedirect(port, httpsPort, manager)
+	}
+
+	if path := settings.ClientCAFile; path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			fatal("Failed to read the client CA bundle", "error", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			fatal("Client CA bundle holds no PEM certificates", "path", path)
+		}
+		serverTLS.ClientCAs = pool
+		serverTLS.ClientAuth = tls.VerifyClientCertIfGiven
+		handlerConfig.ClientCerts = true
+		logger.Info("Client certificates accepted", "client_ca_file", path)
+	}
+}
+
+// serverScheme is the URL scheme the server is reached by
+func serverScheme() string {
+	if serverTLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// serveRedirect serves plain HTTP on port, answering ACME HTTP challenges
+// when manager is set and redirecting every other request to HTTPS
+func serveRedirect(port, httpsPort string, manager *autocert.Manager) {
+	var handler http.Handler = redirectHandler(httpsPort)
+	if manager != nil {
+		handler = manager.HTTPHandler(handler)
+	}
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		fatal("Failed to listen for HTTP redirects", "port", port, "error", err)
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP redirect server stopped", "error", err)
+		}
+	}()
+	onShutdown(func(ctx context.Context) {
+		server.Shutdown(ctx)
+	})
+	logger.Info("Redirecting HTTP to HTTPS", "port", port)
+}
+
+// redirectHandler sends requests to the same URL over HTTPS on httpsPort
+func redirectHandler(httpsPort string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	}
+}
@@ -0,0 +1,547 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2aclient"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+)
+
+// conformanceIdea is the business idea of the tasks the suite generates
+const conformanceIdea = "A neighbourhood tool library where members borrow power tools by the day"
+
+// generationTimeout bounds each check that waits for a task to finish
+const generationTimeout = 3 * time.Minute
+
+// specStates are the task states the A2A specification defines
+var specStates = map[string]bool{
+	a2a.StateSubmitted: true, a2a.StateWorking: true, a2a.StateInputRequired: true, a2a.StateCompleted: true,
+	a2a.StateCanceled: true, a2a.StateFailed: true, a2a.StateRejected: true, "auth-required": true, "unknown": true,
+}
+
+// cardFields are the agent card fields the specification requires
+var cardFields = []string{"protocolVersion", "name", "description", "url", "version", "capabilities", "defaultInputModes", "defaultOutputModes", "skills"}
+
+// transports are the values the specification allows for preferredTransport
+var transports = map[string]bool{"JSONRPC": true, "GRPC": true, "HTTP+JSON": true}
+
+type checkStatus int
+
+const (
+	checkPassed checkStatus = iota
+	checkFailed
+	checkSkipped
+)
+
+// checkResult is the outcome of one requirement of the specification
+type checkResult struct {
+	category string
+	name     string
+	status   checkStatus
+	detail   string
+}
+
+// conformance validates a server against the A2A specification and
+// collects a result per requirement
+type conformance struct {
+	tc       *TestClient
+	client   *a2aclient.Client
+	generate bool
+
+	card    *agent.AgentCard
+	results []checkResult
+}
+
+// runConformance runs the conformance suite and prints its report. With
+// generate false, the checks that need the model to generate are skipped.
+func (tc *TestClient) runConformance(generate bool) {
+	printTestHeader("A2A Conformance")
+	run := &conformance{
+		tc:       tc,
+		client:   a2aclient.New(a2aclient.Config{URL: tc.baseURL + "/a2a/profiler", Header: tc.header}),
+		generate: generate,
+	}
+
+	run.checkAgentCard()
+	run.checkRequiredMethods()
+	run.checkErrorCodes()
+	run.checkTaskStates()
+	run.checkStreaming()
+
+	if !run.report() {
+		os.Exit(1)
+	}
+}
+
+func (run *conformance) pass(category, name string) {
+	run.results = append(run.results, checkResult{category: category, name: name, status: checkPassed})
+}
+
+func (run *conformance) fail(category, name, detail string) {
+	run.results = append(run.results, checkResult{category: category, name: name, status: checkFailed, detail: detail})
+}
+
+func (run *conformance) skip(category, name, reason string) {
+	run.results = append(run.results, checkResult{category: category, name: name, status: checkSkipped, detail: reason})
+}
+
+// check records a check that passes when problem is empty
+func (run *conformance) check(category, name, problem string) {
+	if problem != "" {
+		run.fail(category, name, problem)
+		return
+	}
+	run.pass(category, name)
+}
+
+// report prints the results by category and reports whether all checks
+// that ran passed
+func (run *conformance) report() bool {
+	printHeader("Compliance Report")
+
+	passed, failed, skipped := 0, 0, 0
+	category := ""
+	for _, result := range run.results {
+		if result.category != category {
+			category = result.category
+			fmt.Printf("\n%s%s%s\n", colorCyan, category, colorReset)
+		}
+		switch result.status {
+		case checkPassed:
+			passed++
+			printSuccess(result.name)
+		case checkFailed:
+			failed++
+			printError(fmt.Sprintf("%s: %s", result.name, result.detail))
+		case checkSkipped:
+			skipped++
+			fmt.Printf("%s- %s (skipped: %s)%s\n", colorYellow, result.name, result.detail, colorReset)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%sPassed: %d%s\n", colorGreen, passed, colorReset)
+	fmt.Printf("%sFailed: %d%s\n", colorRed, failed, colorReset)
+	fmt.Printf("%sSkipped: %d%s\n", colorYellow, skipped, colorReset)
+	if passed+failed > 0 {
+		fmt.Printf("Compliance: %d%% of %d checks\n", passed*100/(passed+failed), passed+failed)
+	}
+	return failed == 0
+}
+
+// checkAgentCard fetches the agent card and validates it against the
+// AgentCard schema
+func (run *conformance) checkAgentCard() {
+	const category = "Agent card"
+
+	resp, err := run.tc.client.Get(run.tc.baseURL + "/.well-known/agent.json")
+	if err != nil {
+		run.fail(category, "Served at /.well-known/agent.json", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		run.fail(category, "Served at /.well-known/agent.json", fmt.Sprintf("status %d", resp.StatusCode))
+		return
+	}
+	run.pass(category, "Served at /.well-known/agent.json")
+
+	problem := ""
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		problem = fmt.Sprintf("content type is %q", contentType)
+	}
+	run.check(category, "Served as application/json", problem)
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		run.fail(category, "Is a JSON object", err.Error())
+		return
+	}
+	var missing []string
+	for _, field := range cardFields {
+		if _, ok := raw[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	problem = ""
+	if len(missing) > 0 {
+		problem = "missing " + strings.Join(missing, ", ")
+	}
+	run.check(category, "Has the required fields", problem)
+
+	var card agent.AgentCard
+	if err := json.Unmarshal(body, &card); err != nil {
+		run.fail(category, "Matches the AgentCard schema", err.Error())
+		return
+	}
+	problem = ""
+	if err := card.Validate(); err != nil {
+		problem = err.Error()
+	}
+	run.check(category, "Matches the AgentCard schema", problem)
+
+	problem = ""
+	if card.URL == "" {
+		problem = "url is empty"
+	}
+	run.check(category, "Names its endpoint URL", problem)
+
+	problem = ""
+	if card.PreferredTransport != "" && !transports[card.PreferredTransport] {
+		problem = fmt.Sprintf("unknown transport %q", card.PreferredTransport)
+	}
+	run.check(category, "Names a known preferred transport", problem)
+
+	run.card = &card
+}
+
+// checkRequiredMethods probes each method the specification requires, and
+// those the card's capabilities promise, with requests that don't generate
+func (run *conformance) checkRequiredMethods() {
+	const category = "Required methods"
+	ctx := context.Background()
+	unknownTask := a2a.TaskIDParams{ID: "conformance-unknown-task"}
+
+	invalidMessage := a2a.MessageParams{Message: a2a.A2AMessage{Kind: "message", Role: a2a.RoleUser}}
+	run.expectMethod(category, "message/send", run.client.Call(ctx, "message/send", invalidMessage, nil))
+	run.expectMethod(category, "message/stream", run.client.Stream(ctx, invalidMessage, func(a2aclient.Event) error {
+		return a2aclient.ErrStopStream
+	}))
+	run.expectMethod(category, "tasks/get", run.client.Call(ctx, "tasks/get", unknownTask, nil))
+	run.expectMethod(category, "tasks/cancel", run.client.Call(ctx, "tasks/cancel", unknownTask, nil))
+
+	if run.card == nil || run.card.Capabilities.Streaming {
+		run.expectMethod(category, "tasks/resubscribe", run.client.Resubscribe(ctx, unknownTask.ID, "", func(a2aclient.Event) error {
+			return a2aclient.ErrStopStream
+		}))
+	} else {
+		run.skip(category, "tasks/resubscribe", "the card does not advertise streaming")
+	}
+
+	if run.card == nil || run.card.Capabilities.PushNotifications {
+		run.expectMethod(category, "tasks/pushNotificationConfig/get", run.client.Call(ctx, "tasks/pushNotificationConfig/get", unknownTask, nil))
+	} else {
+		run.skip(category, "tasks/pushNotificationConfig/get", "the card does not advertise push notifications")
+	}
+
+	if run.card != nil && run.card.SupportsAuthenticatedExtendedCard {
+		run.expectMethod(category, "agent/getAuthenticatedExtendedCard", run.client.Call(ctx, "agent/getAuthenticatedExtendedCard", nil, nil))
+	} else {
+		run.skip(category, "agent/getAuthenticatedExtendedCard", "the card does not advertise an extended card")
+	}
+}
+
+// expectMethod passes when the server answered a probe of method with
+// anything but method not found or a transport failure
+func (run *conformance) expectMethod(category, method string, err error) {
+	var rpcErr *a2aerrors.Error
+	switch {
+	case err == nil:
+		run.pass(category, method)
+	case errors.As(err, &rpcErr) && rpcErr.Code == a2aerrors.CodeMethodNotFound:
+		run.fail(category, method, "method not found")
+	case errors.As(err, &rpcErr):
+		run.pass(category, method)
+	default:
+		run.fail(category, method, err.Error())
+	}
+}
+
+// rpcEnvelope is a JSON-RPC response as sent, to check its framing
+type rpcEnvelope struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      interface{}      `json:"id"`
+	Result  json.RawMessage  `json:"result"`
+	Error   *a2aerrors.Error `json:"error"`
+}
+
+// post sends a raw body to the JSON-RPC endpoint and decodes the response
+func (run *conformance) post(body string) (*rpcEnvelope, error) {
+	resp, err := run.tc.client.Post(run.tc.baseURL+"/a2a/profiler", "application/json", strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	var envelope rpcEnvelope
+	if err := json.Unmarshal(bytes.TrimSpace(data), &envelope); err != nil {
+		return nil, fmt.Errorf("response is not a JSON-RPC object: %s", truncate(string(data), 120))
+	}
+	return &envelope, nil
+}
+
+// checkErrorCodes sends malformed and invalid requests and checks the
+// error codes of the answers
+func (run *conformance) checkErrorCodes() {
+	const category = "Error codes"
+	cases := []struct {
+		name string
+		body string
+		code a2aerrors.Code
+	}{
+		{"Parse error (-32700) for malformed JSON", `{"jsonrpc": "2.0", "method": "tasks/get", "params": `, a2aerrors.CodeParseError},
+		{"Invalid request (-32600) for a wrong jsonrpc version", `{"jsonrpc": "1.0", "id": "conformance-1", "method": "tasks/get", "params": {"id": "x"}}`, a2aerrors.CodeInvalidRequest},
+		{"Method not found (-32601) for an unknown method", `{"jsonrpc": "2.0", "id": "conformance-2", "method": "conformance/unknown", "params": {}}`, a2aerrors.CodeMethodNotFound},
+		{"Invalid params (-32602) for tasks/get without an id", `{"jsonrpc": "2.0", "id": "conformance-3", "method": "tasks/get", "params": {}}`, a2aerrors.CodeInvalidParams},
+		{"Task not found (-32001) for an unknown task", `{"jsonrpc": "2.0", "id": "conformance-4", "method": "tasks/get", "params": {"id": "conformance-unknown-task"}}`, a2aerrors.CodeTaskNotFound},
+	}
+	for _, tc := range cases {
+		envelope, err := run.post(tc.body)
+		switch {
+		case err != nil:
+			run.fail(category, tc.name, err.Error())
+		case envelope.Error == nil:
+			run.fail(category, tc.name, "the request succeeded")
+		case envelope.Error.Code != tc.code:
+			run.fail(category, tc.name, fmt.Sprintf("got %d: %s", envelope.Error.Code, envelope.Error.Message))
+		default:
+			run.pass(category, tc.name)
+		}
+	}
+
+	envelope, err := run.post(`{"jsonrpc": "2.0", "id": "conformance-5", "method": "tasks/get", "params": {"id": "conformance-unknown-task"}}`)
+	problem := ""
+	switch {
+	case err != nil:
+		problem = err.Error()
+	case envelope.JSONRPC != "2.0":
+		problem = fmt.Sprintf("jsonrpc is %q", envelope.JSONRPC)
+	case envelope.ID != "conformance-5":
+		problem = fmt.Sprintf("id is %v", envelope.ID)
+	case (envelope.Result != nil && string(envelope.Result) != "null") == (envelope.Error != nil):
+		problem = "the response must hold exactly one of result and error"
+	}
+	run.check(category, "Responses carry jsonrpc 2.0, the request id and one of result and error", problem)
+}
+
+// checkTaskStates follows tasks through their lifecycle and checks that
+// they only move forward and stay in a terminal state once reached
+func (run *conformance) checkTaskStates() {
+	const category = "Task states"
+	if !run.generate {
+		run.skip(category, "Task lifecycle", "generation is disabled")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), generationTimeout)
+	defer cancel()
+
+	blocking := false
+	task, err := run.client.SendMessage(ctx, conformanceMessage(&blocking))
+	if err != nil {
+		run.fail(category, "Non-blocking message/send returns a task", err.Error())
+		return
+	}
+	problem := ""
+	switch {
+	case task.ID == "" || task.ContextID == "":
+		problem = "the task has no id or contextId"
+	case task.Kind != "task":
+		problem = fmt.Sprintf("kind is %q", task.Kind)
+	case !specStates[task.Status.State]:
+		problem = fmt.Sprintf("unknown state %q", task.Status.State)
+	}
+	run.check(category, "Non-blocking message/send returns a task", problem)
+
+	states := []string{task.Status.State}
+	for !a2a.IsTerminalState(task.Status.State) && task.Status.State != a2a.StateInputRequired {
+		select {
+		case <-ctx.Done():
+			run.fail(category, "Task reaches a final state", fmt.Sprintf("still %s after %s", task.Status.State, generationTimeout))
+			return
+		case <-time.After(time.Second):
+		}
+		if task, err = run.client.GetTask(ctx, task.ID, 0); err != nil {
+			run.fail(category, "Task reaches a final state", err.Error())
+			return
+		}
+		states = append(states, task.Status.State)
+	}
+	run.pass(category, "Task reaches a final state")
+	run.check(category, "States are defined by the specification and only move forward", checkTransitions(states))
+
+	again, err := run.client.GetTask(ctx, task.ID, 0)
+	problem = ""
+	switch {
+	case err != nil:
+		problem = err.Error()
+	case again.Status.State != task.Status.State:
+		problem = fmt.Sprintf("state changed from %s to %s", task.Status.State, again.Status.State)
+	}
+	run.check(category, "Final state is stable", problem)
+
+	_, err = run.client.CancelTask(ctx, task.ID)
+	var rpcErr *a2aerrors.Error
+	problem = ""
+	switch {
+	case err == nil:
+		problem = "the finished task was canceled"
+	case !errors.As(err, &rpcErr):
+		problem = err.Error()
+	case rpcErr.Code != a2aerrors.CodeTaskNotCancelable:
+		problem = fmt.Sprintf("got %d: %s", rpcErr.Code, rpcErr.Message)
+	}
+	run.check(category, "Task not cancelable (-32002) for a finished task", problem)
+
+	running, err := run.client.SendMessage(ctx, conformanceMessage(&blocking))
+	if err != nil {
+		run.fail(category, "tasks/cancel cancels a running task", err.Error())
+		return
+	}
+	if a2a.IsTerminalState(running.Status.State) {
+		run.skip(category, "tasks/cancel cancels a running task", "the task finished before it could be canceled")
+		return
+	}
+	canceled, err := run.client.CancelTask(ctx, running.ID)
+	problem = ""
+	switch {
+	case errors.As(err, &rpcErr) && rpcErr.Code == a2aerrors.CodeTaskNotCancelable:
+		run.skip(category, "tasks/cancel cancels a running task", "the task finished before it could be canceled")
+		return
+	case err != nil:
+		problem = err.Error()
+	case canceled.Status.State != a2a.StateCanceled:
+		problem = fmt.Sprintf("state is %s", canceled.Status.State)
+	}
+	run.check(category, "tasks/cancel cancels a running task", problem)
+}
+
+// checkStreaming streams a task and checks the order of its events
+func (run *conformance) checkStreaming() {
+	const category = "Streaming"
+	if run.card != nil && !run.card.Capabilities.Streaming {
+		run.skip(category, "Event ordering", "the card does not advertise streaming")
+		return
+	}
+	if !run.generate {
+		run.skip(category, "Event ordering", "generation is disabled")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), generationTimeout)
+	defer cancel()
+
+	var events []a2aclient.Event
+	err := run.client.Stream(ctx, conformanceMessage(nil), func(event a2aclient.Event) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		run.fail(category, "message/stream ends with a final event", err.Error())
+		return
+	}
+	last := events[len(events)-1]
+	problem := ""
+	if !a2a.IsTerminalState(last.Status.Status.State) && last.Status.Status.State != a2a.StateInputRequired {
+		problem = fmt.Sprintf("the final event has state %s", last.Status.Status.State)
+	}
+	run.check(category, "message/stream ends with a final event", problem)
+
+	problem = ""
+	if events[0].Status == nil {
+		problem = "the first event is an artifact update"
+	} else if a2a.IsTerminalState(events[0].Status.Status.State) {
+		problem = fmt.Sprintf("the first event is already %s", events[0].Status.Status.State)
+	}
+	run.check(category, "Stream opens with a status update", problem)
+
+	taskID, contextID := eventIDs(events[0])
+	var states []string
+	artifacts := map[string]bool{}
+	problem = ""
+	for i, event := range events {
+		id, ctxID := eventIDs(event)
+		if id != taskID || ctxID != contextID {
+			problem = fmt.Sprintf("event %d belongs to task %s in context %s", i+1, id, ctxID)
+			break
+		}
+		if event.Status != nil {
+			states = append(states, event.Status.Status.State)
+		} else {
+			artifacts[event.Artifact.Artifact.ArtifactID] = true
+		}
+	}
+	run.check(category, "Events share one taskId and contextId", problem)
+
+	problem = ""
+	for i, event := range events[:len(events)-1] {
+		if event.Final() {
+			problem = fmt.Sprintf("event %d of %d is final", i+1, len(events))
+		}
+	}
+	run.check(category, "Only the last event is final", problem)
+	run.check(category, "Status updates only move forward", checkTransitions(states))
+
+	task, err := run.client.GetTask(ctx, taskID, 0)
+	problem = ""
+	if err != nil {
+		problem = err.Error()
+	} else {
+		if task.Status.State != last.Status.Status.State {
+			problem = fmt.Sprintf("tasks/get has state %s, the stream ended with %s", task.Status.State, last.Status.Status.State)
+		}
+		for _, artifact := range task.Artifacts {
+			delete(artifacts, artifact.ArtifactID)
+		}
+		for id := range artifacts {
+			problem = fmt.Sprintf("streamed artifact %s is missing from the task", id)
+		}
+	}
+	run.check(category, "Streamed task matches tasks/get", problem)
+}
+
+// checkTransitions returns a problem with a sequence of observed states,
+// or "" if every state is defined and none follows a terminal state
+func checkTransitions(states []string) string {
+	for i, state := range states {
+		if !specStates[state] {
+			return fmt.Sprintf("unknown state %q", state)
+		}
+		if i > 0 && a2a.IsTerminalState(states[i-1]) && state != states[i-1] {
+			return fmt.Sprintf("moved from terminal state %s to %s", states[i-1], state)
+		}
+	}
+	return ""
+}
+
+func eventIDs(event a2aclient.Event) (taskID, contextID string) {
+	if event.Status != nil {
+		return event.Status.TaskID, event.Status.ContextID
+	}
+	return event.Artifact.TaskID, event.Artifact.ContextID
+}
+
+func conformanceMessage(blocking *bool) a2a.MessageParams {
+	return a2a.MessageParams{
+		Message: a2a.A2AMessage{
+			Kind:  "message",
+			Role:  a2a.RoleUser,
+			Parts: []a2a.MessagePart{a2a.TextPart(conformanceIdea)},
+		},
+		Configuration: a2a.MessageConfiguration{
+			Blocking:            blocking,
+			AcceptedOutputModes: []string{"text", "data"},
+		},
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -10,6 +12,10 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2aclient"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
 )
 
 const (
@@ -25,24 +31,49 @@ const (
 type TestClient struct {
 	baseURL string
 	client  *http.Client
+	// header is sent with every request, e.g. X-API-Key
+	header http.Header
 }
 
-func NewTestClient(baseURL string) *TestClient {
+// NewTestClient returns a client of the agent at baseURL, sending apiKey
+// in the X-API-Key header if it is set
+func NewTestClient(baseURL, apiKey string) *TestClient {
+	header := make(http.Header)
+	if apiKey != "" {
+		header.Set("X-API-Key", apiKey)
+	}
 	return &TestClient{
 		baseURL: baseURL,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: headerTransport{header: header},
 		},
+		header: header,
 	}
 }
 
+// headerTransport adds its header to each request
+type headerTransport struct {
+	header http.Header
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, values := range t.header {
+		req.Header[key] = values
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 func main() {
 	baseURL := flag.String("url", "http://localhost:8080", "Base URL of the agent")
-	testType := flag.String("test", "all", "Test type: all, health, agent-card, profile, custom")
+	testType := flag.String("test", "all", "Test type: all, health, agent-card, profile, custom, conformance")
 	businessIdea := flag.String("idea", "", "Business idea for profile generation (for custom test)")
+	generate := flag.Bool("generate", true, "Run the conformance checks that generate profiles (for conformance test)")
+	apiKey := flag.String("api-key", os.Getenv("API_KEY"), "API key sent in the X-API-Key header (default $API_KEY)")
 	flag.Parse()
 
-	client := NewTestClient(*baseURL)
+	client := NewTestClient(*baseURL, *apiKey)
 
 	printHeader("Customer Profiler Agent - Test Suite")
 	fmt.Printf("%sBase URL: %s%s\n\n", colorCyan, *baseURL, colorReset)
@@ -62,9 +93,11 @@ func main() {
 			os.Exit(1)
 		}
 		client.testCustomProfile(*businessIdea)
+	case "conformance":
+		client.runConformance(*generate)
 	default:
 		printError(fmt.Sprintf("Unknown test type: %s", *testType))
-		fmt.Println("\nAvailable tests: all, health, agent-card, profile, custom")
+		fmt.Println("\nAvailable tests: all, health, agent-card, profile, custom, conformance")
 		os.Exit(1)
 	}
 }
@@ -184,106 +217,65 @@ func (tc *TestClient) testCustomProfile(businessIdea string) bool {
 	fmt.Printf("POST %s\n", url)
 	fmt.Printf("%sBusiness Idea:%s %s\n\n", colorCyan, colorReset, businessIdea)
 
-	// Create JSON-RPC request
-	request := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      fmt.Sprintf("test-%d", time.Now().Unix()),
-		"method":  "agent/task",
-		"params": map[string]interface{}{
-			"message": map[string]interface{}{
-				"kind": "message",
-				"role": "user",
-				"parts": []map[string]interface{}{
-					{
-						"kind": "text",
-						"text": businessIdea,
-					},
-				},
-			},
-			"configuration": map[string]interface{}{
-				"blocking":            true,
-				"acceptedOutputModes": []string{"text", "data"},
-			},
+	blocking := true
+	params := a2a.MessageParams{
+		Message: a2a.A2AMessage{
+			Kind:  "message",
+			Role:  a2a.RoleUser,
+			Parts: []a2a.MessagePart{a2a.TextPart(businessIdea)},
+		},
+		Configuration: a2a.MessageConfiguration{
+			Blocking:            &blocking,
+			AcceptedOutputModes: []string{"text", "data"},
 		},
 	}
 
-	jsonData, _ := json.MarshalIndent(request, "", "  ")
-	fmt.Printf("%sRequest:%s\n", colorYellow, colorReset)
+	jsonData, _ := json.MarshalIndent(params, "", "  ")
+	fmt.Printf("%sRequest params:%s\n", colorYellow, colorReset)
 	fmt.Println(string(jsonData))
 	fmt.Println()
 
-	resp, err := tc.client.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	client := a2aclient.New(a2aclient.Config{URL: url, HTTPClient: tc.client})
+	task, err := client.SendMessage(context.Background(), params)
 	if err != nil {
-		printError(fmt.Sprintf("Request failed: %v", err))
-		return false
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		printError(fmt.Sprintf("Expected status 200, got %d", resp.StatusCode))
-		fmt.Printf("Response: %s\n", string(body))
-		return false
-	}
-
-	// Parse JSON-RPC response
-	var response map[string]interface{}
-	if err := json.Unmarshal(body, &response); err != nil {
-		printError(fmt.Sprintf("Invalid JSON response: %v", err))
-		return false
-	}
-
-	// Check for errors
-	if errObj, ok := response["error"]; ok {
-		printError("Request returned an error")
-		errJSON, _ := json.MarshalIndent(errObj, "", "  ")
-		fmt.Println(string(errJSON))
-		return false
-	}
-
-	// Check result
-	result, ok := response["result"].(map[string]interface{})
-	if !ok {
-		printError("Invalid result format")
-		return false
-	}
-
-	// Check task status
-	status, ok := result["status"].(map[string]interface{})
-	if !ok {
-		printError("Invalid status format")
+		var rpcErr *a2aerrors.Error
+		if !errors.As(err, &rpcErr) {
+			printError(fmt.Sprintf("Request failed: %v", err))
+			return false
+		}
+		printError(fmt.Sprintf("Request returned an error: %v", rpcErr))
+		if rpcErr.Data != nil && rpcErr.Data.Field != "" {
+			fmt.Printf("Invalid field: %s\n", rpcErr.Data.Field)
+		}
+		if rpcErr.Retryable() {
+			fmt.Println("The error is transient; try again later")
+		}
 		return false
 	}
 
-	state, _ := status["state"].(string)
-	if state != "completed" {
-		printError(fmt.Sprintf("Expected state 'completed', got '%s'", state))
+	if task.Status.State != a2a.StateCompleted {
+		printError(fmt.Sprintf("Expected state 'completed', got '%s'", task.Status.State))
 		return false
 	}
 
 	printSuccess("Profile generation completed successfully")
 
 	// Display the response message
-	if msg, ok := status["message"].(map[string]interface{}); ok {
-		if parts, ok := msg["parts"].([]interface{}); ok {
-			fmt.Printf("\n%sGenerated Profile:%s\n", colorGreen, colorReset)
-			fmt.Println(strings.Repeat("=", 80))
-			for _, part := range parts {
-				if p, ok := part.(map[string]interface{}); ok {
-					if text, ok := p["text"].(string); ok {
-						fmt.Println(text)
-					}
-				}
+	if msg := task.Status.Message; msg != nil {
+		fmt.Printf("\n%sGenerated Profile:%s\n", colorGreen, colorReset)
+		fmt.Println(strings.Repeat("=", 80))
+		for _, part := range msg.Parts {
+			if text, ok := part.Text.(string); ok {
+				fmt.Println(text)
 			}
-			fmt.Println(strings.Repeat("=", 80))
 		}
+		fmt.Println(strings.Repeat("=", 80))
 	}
 
 	// Display artifacts if any
-	if artifacts, ok := result["artifacts"].([]interface{}); ok && len(artifacts) > 0 {
+	if len(task.Artifacts) > 0 {
 		fmt.Printf("\n%sArtifacts:%s\n", colorPurple, colorReset)
-		artifactsJSON, _ := json.MarshalIndent(artifacts, "", "  ")
+		artifactsJSON, _ := json.MarshalIndent(task.Artifacts, "", "  ")
 		fmt.Println(string(artifactsJSON))
 	}
 
@@ -0,0 +1,190 @@
+package a2a
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// Names of the security schemes in the agent card
+const (
+	apiKeyScheme     = "apiKey"
+	bearerScheme     = "bearer"
+	clientCertScheme = "mutualTLS"
+)
+
+// authenticate identifies the caller by its client certificate, API key or
+// JWT bearer token, when any is required, and puts the identity in the
+// request context.
+// In demo mode callers without credentials are let through as anonymous
+// demo users.
+func (h *A2AHandler) authenticate(c *gin.Context) *rpcError {
+	if !h.requiresAuth() {
+		return nil
+	}
+	ctx := c.Request.Context()
+	if _, ok := auth.FromContext(ctx); ok {
+		return nil
+	}
+
+	var cert *x509.Certificate
+	if h.config.ClientCerts {
+		cert = clientCertificate(c)
+	}
+	key := c.GetHeader(APIKeyHeader)
+	token, hasToken := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	hasToken = hasToken && h.config.BearerTokens != nil
+
+	var identity auth.Identity
+	switch {
+	case cert != nil:
+		identity = auth.Identity{Name: certificateName(cert), Method: "mtls"}
+	case key != "" && h.config.APIKeys != nil:
+		name, ok := h.config.APIKeys.Lookup(key)
+		if !ok {
+			logger.WarnContext(ctx, "Rejected request with an unknown API key", "client_ip", c.ClientIP())
+			return unauthenticated("The API key is not valid")
+		}
+		identity = auth.Identity{Name: name, Method: "api_key"}
+	case hasToken:
+		var err error
+		identity, err = h.config.BearerTokens.Verify(ctx, token)
+		if err != nil {
+			logger.WarnContext(ctx, "Rejected bearer token", "client_ip", c.ClientIP(), "error", err)
+			if !errors.Is(err, auth.ErrInvalidToken) {
+				return &rpcError{status: http.StatusServiceUnavailable, code: CodeInternalError, message: "Failed to verify the bearer token"}
+			}
+			return unauthenticated("The bearer token is not valid")
+		}
+	case h.config.Demo != nil:
+		return nil
+	default:
+		logger.WarnContext(ctx, "Rejected request without credentials", "client_ip", c.ClientIP())
+		return unauthenticated("Authentication required: " + h.credentialHint())
+	}
+
+	c.Request = c.Request.WithContext(auth.NewContext(ctx, identity))
+	return nil
+}
+
+// requiresAuth reports whether callers must present credentials
+func (h *A2AHandler) requiresAuth() bool {
+	return h.config.APIKeys != nil || h.config.BearerTokens != nil || h.config.ClientCerts
+}
+
+// credentialHint names the credentials a caller may present
+func (h *A2AHandler) credentialHint() string {
+	var accepted []string
+	if h.config.APIKeys != nil {
+		accepted = append(accepted, "an API key in the "+APIKeyHeader+" header")
+	}
+	if h.config.BearerTokens != nil {
+		accepted = append(accepted, "a JWT bearer token")
+	}
+	if h.config.ClientCerts {
+		accepted = append(accepted, "a TLS client certificate")
+	}
+	return "send " + strings.Join(accepted, " or ")
+}
+
+// clientCertificate returns the client certificate the TLS handshake
+// verified, or nil
+func clientCertificate(c *gin.Context) *x509.Certificate {
+	state := c.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// certificateName names the caller of a client certificate by its common
+// name, or else its first URI or DNS name, as SPIFFE IDs have no common name
+func certificateName(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	}
+	return cert.SerialNumber.String()
+}
+
+// unauthenticated is the 401 error of a caller without valid credentials
+func unauthenticated(message string) *rpcError {
+	return &rpcError{status: http.StatusUnauthorized, code: CodeUnauthorized, message: message}
+}
+
+// extendedCardRequest reports whether body calls
+// agent/getAuthenticatedExtendedCard, which checks its own token
+func (h *A2AHandler) extendedCardRequest(body []byte) bool {
+	var envelope struct {
+		Method string `json:"method"`
+	}
+	if json.Unmarshal(body, &envelope) != nil {
+		return false
+	}
+	method, _, ok := h.methods.Resolve(envelope.Method)
+	return ok && method == "agent/getAuthenticatedExtendedCard"
+}
+
+// RequireAuth authenticates callers of routes outside HandleProfiler, such
+// as the agentkit endpoint, answering failures with a JSON-RPC error
+func (h *A2AHandler) RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rpcErr := h.authenticate(c); rpcErr != nil {
+			h.sendError(c, "", rpcErr)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// securitySchemes declares the accepted credentials in the agent card,
+// each an alternative; demo mode also accepts anonymous callers
+func securitySchemes(config HandlerConfig, cardConfig *agent.CardConfig) {
+	schemes := make(map[string]agent.SecurityScheme)
+	if config.APIKeys != nil {
+		schemes[apiKeyScheme] = agent.SecurityScheme{
+			Type:        "apiKey",
+			Description: "API key issued by the operator",
+			Name:        APIKeyHeader,
+			In:          "header",
+		}
+	}
+	if config.BearerTokens != nil {
+		schemes[bearerScheme] = agent.SecurityScheme{
+			Type:         "http",
+			Description:  "JWT issued by the operator's identity provider",
+			Scheme:       "bearer",
+			BearerFormat: "JWT",
+		}
+	}
+	if config.ClientCerts {
+		schemes[clientCertScheme] = agent.SecurityScheme{
+			Type:        "mutualTLS",
+			Description: "TLS client certificate signed by a CA the operator trusts",
+		}
+	}
+	if len(schemes) == 0 {
+		return
+	}
+
+	cardConfig.SecuritySchemes = schemes
+	for _, name := range []string{clientCertScheme, apiKeyScheme, bearerScheme} {
+		if _, ok := schemes[name]; ok {
+			cardConfig.Security = append(cardConfig.Security, map[string][]string{name: {}})
+		}
+	}
+	if config.Demo != nil {
+		cardConfig.Security = append(cardConfig.Security, map[string][]string{})
+	}
+}
@@ -0,0 +1,80 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+// avatarConcurrency bounds concurrent image generations per request
+const avatarConcurrency = 2
+
+// createAvatarArtifact renders one avatar per persona and returns them as
+// FileParts pointing at the avatar store. Avatars are best effort: personas
+// whose image fails are logged and left out, and nil is returned if none succeed.
+func (h *A2AHandler) createAvatarArtifact(ctx context.Context, baseURL string, profiles []models.CustomerProfile) *Artifact {
+	if h.config.AvatarGenerator == nil || h.config.AvatarStore == nil {
+		logger.WarnContext(ctx, "Avatars requested but avatar generation is not configured")
+		return nil
+	}
+
+	parts := make([]*MessagePart, len(profiles))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(avatarConcurrency)
+
+	for i, profile := range profiles {
+		group.Go(func() error {
+			image, mimeType, err := h.config.AvatarGenerator.GenerateAvatar(groupCtx, profile)
+			if err != nil {
+				logger.WarnContext(ctx, "Failed to generate avatar", "persona", i+1, "error", err)
+				return nil
+			}
+
+			id := h.config.AvatarStore.Put(image, mimeType)
+			part := FilePart(fmt.Sprintf("persona-%d-avatar", i+1), mimeType, fmt.Sprintf("%s/avatars/%s", baseURL, id))
+			parts[i] = &part
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	var fileParts []MessagePart
+	for _, part := range parts {
+		if part != nil {
+			fileParts = append(fileParts, *part)
+		}
+	}
+
+	if len(fileParts) == 0 {
+		return nil
+	}
+
+	return &Artifact{
+		ArtifactID: uuid.New().String(),
+		Name:       "Persona Avatars",
+		Parts:      fileParts,
+		Metadata: map[string]interface{}{
+			MetadataDisclosure: disclosureMetadata(profiler.AvatarModelName, Timestamp()),
+		},
+	}
+}
+
+// avatarBaseURL is the public base URL for avatar links, preferring the
+// configured base URL over the request's own host
+func (h *A2AHandler) avatarBaseURL(r *http.Request) string {
+	base := h.config.PublicBaseURL
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = fmt.Sprintf("%s://%s", scheme, r.Host)
+	}
+	return base
+}
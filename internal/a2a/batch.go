@@ -0,0 +1,151 @@
+package a2a
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// MaxBatchSize caps how many requests a JSON-RPC batch may hold
+	MaxBatchSize = 20
+	// DefaultBatchConcurrency bounds how many requests of a batch run at once
+	DefaultBatchConcurrency = 4
+)
+
+// isBatch reports whether a request body is a JSON-RPC batch array
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// handleBatch runs each request of a JSON-RPC batch as if it had been sent
+// on its own and answers with the responses in request order. Notifications
+// get no entry; a batch of only notifications gets 204 No Content.
+func (h *A2AHandler) handleBatch(c *gin.Context, body []byte) {
+	c.Set(ctxKeyMethod, "batch")
+
+	var requests []json.RawMessage
+	if err := json.Unmarshal(body, &requests); err != nil {
+		h.sendErrorResponse(c, "", "Invalid batch request", CodeParseError)
+		return
+	}
+	if len(requests) == 0 {
+		h.sendErrorResponse(c, "", "Batch request must not be empty", CodeInvalidRequest)
+		return
+	}
+	if len(requests) > MaxBatchSize {
+		h.sendErrorResponse(c, "", fmt.Sprintf("Batch request holds %d requests; the limit is %d", len(requests), MaxBatchSize), CodeInvalidRequest)
+		return
+	}
+
+	logger.DebugContext(c.Request.Context(), "Handling JSON-RPC batch", "requests", len(requests))
+
+	concurrency := h.config.BatchConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	responses := make([]json.RawMessage, len(requests))
+
+	var group errgroup.Group
+	group.SetLimit(concurrency)
+	for i, request := range requests {
+		group.Go(func() error {
+			responses[i] = h.runBatchRequest(c, request)
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	c.Set(ctxKeyOutcome, fmt.Sprintf("batch of %d", len(requests)))
+
+	answered := responses[:0]
+	for _, response := range responses {
+		if response != nil {
+			answered = append(answered, response)
+		}
+	}
+	if len(answered) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.JSON(http.StatusOK, answered)
+}
+
+// runBatchRequest handles one request of a batch in its own gin context,
+// keeping the original headers, and returns the JSON-RPC response it wrote,
+// or nil for a notification
+func (h *A2AHandler) runBatchRequest(c *gin.Context, request json.RawMessage) json.RawMessage {
+	if isNotification(request) {
+		h.startNotification(c, request)
+		return nil
+	}
+
+	var envelope JSONRPCRequest
+	if err := json.Unmarshal(request, &envelope); err != nil {
+		return batchError("", "Invalid request", CodeInvalidRequest)
+	}
+	if method, _, _ := h.methods.Resolve(envelope.Method); method == "message/stream" || method == "tasks/resubscribe" {
+		return batchError(envelope.ID, fmt.Sprintf("%s cannot be used in a batch", envelope.Method), CodeInvalidRequest)
+	}
+
+	req := c.Request.Clone(c.Request.Context())
+	// A header key would be shared by every entry; entries set their own in
+	// their metadata
+	req.Header.Del(IdempotencyKeyHeader)
+	req.Body = io.NopCloser(bytes.NewReader(request))
+	req.ContentLength = int64(len(request))
+
+	response := h.serveInternal(req).Body.Bytes()
+	if !json.Valid(response) {
+		logger.ErrorContext(c.Request.Context(), "Batch request produced an invalid response", "id", envelope.ID)
+		return batchError(envelope.ID, "Failed to process request", CodeInternalError)
+	}
+	return response
+}
+
+// serveInternal runs an in-process request through HandleProfiler and
+// records the response
+func (h *A2AHandler) serveInternal(req *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	h.RPCHandler().ServeHTTP(recorder, req)
+	return recorder
+}
+
+// RPCHandler serves JSON-RPC requests POSTed to any path through
+// HandleProfiler, for transports outside the Gin router such as gRPC
+func (h *A2AHandler) RPCHandler() http.Handler {
+	h.internalOnce.Do(func() {
+		h.internal = gin.New()
+		// Transports copy the caller's headers, so forwarding headers
+		// count only from the same proxies as on the main router
+		if err := h.internal.SetTrustedProxies(h.config.TrustedProxies); err != nil {
+			logger.Error("Invalid trusted proxies; trusting none", "error", err)
+			_ = h.internal.SetTrustedProxies(nil)
+		}
+		h.internal.POST("/a2a/profiler", h.HandleProfiler)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.URL.Path = "/a2a/profiler"
+		h.internal.ServeHTTP(w, req)
+	})
+}
+
+// batchError encodes a JSON-RPC error response for a batch entry
+func batchError(id, message string, code a2aerrors.Code) json.RawMessage {
+	response, _ := json.Marshal(JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   (&rpcError{code: code, message: message}).object(),
+	})
+	return response
+}
@@ -0,0 +1,102 @@
+package a2a
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default limits on the shape of requests, so a huge or deeply nested
+// payload can't exhaust memory before it is rejected
+const (
+	// DefaultMaxBodySize fits an inline document of document.MaxSize once
+	// base64-encoded
+	DefaultMaxBodySize = 16 << 20
+	// DefaultMaxJSONDepth allows the deepest valid request, a data part
+	// holding a structured conversation, with room to spare
+	DefaultMaxJSONDepth = 32
+	// DefaultMaxMessageParts bounds the parts of one message
+	DefaultMaxMessageParts = 20
+)
+
+// readBody reads the request body, once, refusing bodies over the size or
+// nesting limit before anything decodes them
+func (h *A2AHandler) readBody(c *gin.Context) ([]byte, *rpcError) {
+	limit := h.config.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	if c.Request.ContentLength > limit {
+		return nil, bodyTooLarge(limit)
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, bodyTooLarge(limit)
+		}
+		logger.ErrorContext(c.Request.Context(), "Failed to read request body", "error", err)
+		return nil, &rpcError{code: CodeParseError, message: "Failed to read request body"}
+	}
+
+	depth := h.config.MaxJSONDepth
+	if depth <= 0 {
+		depth = DefaultMaxJSONDepth
+	}
+	if exceedsDepth(body, depth) {
+		return nil, &rpcError{code: CodeInvalidRequest, message: fmt.Sprintf("Request nests JSON deeper than %d levels", depth)}
+	}
+	return body, nil
+}
+
+// bodyTooLarge is the 413 error of a body over limit bytes
+func bodyTooLarge(limit int64) *rpcError {
+	return &rpcError{
+		status:  http.StatusRequestEntityTooLarge,
+		code:    CodeInvalidRequest,
+		message: fmt.Sprintf("Request body is larger than %d bytes", limit),
+	}
+}
+
+// exceedsDepth reports whether body nests arrays and objects deeper than
+// limit. It scans the raw bytes, skipping strings, so it runs before the
+// decoder recurses; malformed JSON is left for the decoder to report.
+func exceedsDepth(body []byte, limit int) bool {
+	depth := 0
+	inString, escaped := false, false
+	for _, b := range body {
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+		case b == '"':
+			inString = true
+		case b == '{' || b == '[':
+			depth++
+			if depth > limit {
+				return true
+			}
+		case b == '}' || b == ']':
+			depth--
+		}
+	}
+	return false
+}
+
+// maxMessageParts is the configured part limit of a message
+func (h *A2AHandler) maxMessageParts() int {
+	if h.config.MaxMessageParts <= 0 {
+		return DefaultMaxMessageParts
+	}
+	return h.config.MaxMessageParts
+}
@@ -0,0 +1,55 @@
+package a2a
+
+import (
+	"net/http"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+)
+
+// MetadataBranding is the artifact metadata key of the tenant branding
+// that HTML and PDF renderers downstream should apply
+const MetadataBranding = "branding"
+
+// defaultAgentName names the agent in text output of unbranded tenants
+const defaultAgentName = "Customer Profiler"
+
+// tenantQueryParam selects a tenant's branded agent card for clients that
+// fetch the card without headers
+const tenantQueryParam = "tenant"
+
+// branding returns the branding of the request's tenant, named by the
+// X-Tenant-ID header or the tenant query parameter, or nil
+func (h *A2AHandler) branding(r *http.Request) *agent.Branding {
+	if len(h.config.Branding) == 0 {
+		return nil
+	}
+	tenant := r.Header.Get(TenantHeader)
+	if tenant == "" {
+		tenant = r.URL.Query().Get(tenantQueryParam)
+	}
+	brand, ok := h.config.Branding[tenant]
+	if !ok {
+		return nil
+	}
+	return &brand
+}
+
+// agentName is the name text output signs with
+func (r renderOptions) agentName() string {
+	if r.brand != nil {
+		return r.brand.Name
+	}
+	return defaultAgentName
+}
+
+// brandingMetadata describes a branding for artifact metadata
+func brandingMetadata(brand agent.Branding) map[string]interface{} {
+	metadata := map[string]interface{}{"name": brand.Name}
+	if brand.LogoURL != "" {
+		metadata["logoUrl"] = brand.LogoURL
+	}
+	if brand.AccentColor != "" {
+		metadata["accentColor"] = brand.AccentColor
+	}
+	return metadata
+}
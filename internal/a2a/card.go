@@ -0,0 +1,69 @@
+package a2a
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/document"
+	"github.com/gin-gonic/gin"
+)
+
+// AgentCardConfig describes this endpoint for agent.BuildCard. Without a
+// PublicBaseURL the card's URL is derived from each request.
+func AgentCardConfig(config HandlerConfig) agent.CardConfig {
+	cardConfig := agent.CardConfig{
+		InputModes:   append([]string{"text", "data"}, document.Supported...),
+		OutputModes:  []string{"text", "data"},
+		ExtendedCard: config.ExtendedCardToken != "",
+	}
+	if config.PublicBaseURL != "" {
+		cardConfig.URL = strings.TrimSuffix(config.PublicBaseURL, "/") + "/a2a/profiler"
+	}
+	securitySchemes(config, &cardConfig)
+	return cardConfig
+}
+
+// SetAgentCard replaces the card served at /.well-known/agent.json and
+// extended by agent/getAuthenticatedExtendedCard. Requests in flight keep
+// the card they started with.
+func (h *A2AHandler) SetAgentCard(card *agent.AgentCard) {
+	h.card.Store(card)
+}
+
+// agentCard returns the public card with its URL filled in for the
+// request, branded for the calling tenant
+func (h *A2AHandler) agentCard(c *gin.Context) agent.AgentCard {
+	card := *h.card.Load()
+	if card.URL == "" {
+		card.URL = h.avatarBaseURL(c.Request) + "/a2a/profiler"
+	}
+	if brand := h.branding(c.Request); brand != nil {
+		card = brand.Apply(card)
+	}
+	return card
+}
+
+// handleExtendedCard answers agent/getAuthenticatedExtendedCard for callers
+// presenting ExtendedCardToken as a bearer token
+func (h *A2AHandler) handleExtendedCard(c *gin.Context, rpcReq JSONRPCRequest) {
+	if h.config.ExtendedCardToken == "" || h.card.Load() == nil {
+		h.sendErrorResponse(c, rpcReq.ID, "Authenticated extended card is not configured", CodeExtendedCardNotConfigured)
+		return
+	}
+
+	presented := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(h.config.ExtendedCardToken)) != 1 {
+		logger.WarnContext(c.Request.Context(), "Rejected extended card request", "client_ip", c.ClientIP())
+		h.sendErrorResponse(c, rpcReq.ID, "Unauthorized", CodeUnauthorized)
+		return
+	}
+
+	base := h.avatarBaseURL(c.Request)
+	endpoints := make(map[string]string, len(h.config.OperatorEndpoints))
+	for name, path := range h.config.OperatorEndpoints {
+		endpoints[name] = base + path
+	}
+
+	h.sendSuccessResponse(c, rpcReq.ID, agent.ExtendedCard(h.agentCard(c), endpoints))
+}
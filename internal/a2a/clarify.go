@@ -0,0 +1,115 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// fillerWords say nothing about the business on their own; an idea made
+// only of them is too vague to profile
+var fillerWords = map[string]bool{
+	"a": true, "an": true, "the": true, "my": true, "our": true, "your": true, "this": true, "that": true,
+	"for": true, "of": true, "with": true, "about": true, "on": true, "in": true, "and": true, "or": true,
+	"hi": true, "hello": true, "hey": true, "thanks": true, "please": true, "help": true, "me": true, "us": true,
+	"i": true, "we": true, "you": true, "it": true, "is": true, "am": true, "are": true, "can": true, "could": true,
+	"have": true, "want": true, "need": true, "do": true, "make": true, "to": true, "start": true, "new": true,
+	"what": true, "how": true, "some": true,
+	"business": true, "idea": true, "startup": true, "company": true, "product": true,
+	"customer": true, "customers": true, "profile": true, "profiles": true, "persona": true, "personas": true,
+	"generate": true, "create": true, "something": true, "anything": true, "test": true,
+}
+
+// clarificationFor returns the question to ask when an idea is empty or too
+// vague to profile, or "" when profiles can be generated
+func clarificationFor(idea string) string {
+	if idea == "" {
+		return "What is your business idea? Describe what you sell and who you sell it to."
+	}
+	if len(meaningfulWords(idea)) == 0 {
+		return fmt.Sprintf("%q doesn't tell me enough about the business yet. What product or service do you offer, and who is it for?", idea)
+	}
+	return ""
+}
+
+// meaningfulWords are the words of text that aren't filler
+func meaningfulWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if len([]rune(word)) > 1 && !fillerWords[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// inputRequiredResult pauses a task with a clarifying question; the user's
+// reply with the same taskId resumes it
+func inputRequiredResult(taskID, question string) TaskResult {
+	return TaskResult{
+		ID:   taskID,
+		Kind: "task",
+		Status: TaskStatus{
+			State:     StateInputRequired,
+			Timestamp: Timestamp(),
+			Message: &A2AMessage{
+				Kind:      "message",
+				Role:      RoleAgent,
+				MessageID: uuid.New().String(),
+				TaskID:    taskID,
+				Parts:     []MessagePart{TextPart(question)},
+			},
+		},
+	}
+}
+
+// resumeTask returns the ID to run a message's task under. A message whose
+// taskId names an input-required task answers its question and continues
+// that task; any other taskId is ignored and the task gets a new ID,
+// remembered under rpcID.
+func (h *A2AHandler) resumeTask(ctx context.Context, rpcID string, msgParams *MessageParams) string {
+	taskID := msgParams.Message.TaskID
+	if taskID == "" {
+		return h.newTaskID(rpcID)
+	}
+	taskID = h.resolveTaskID(taskID)
+
+	task, ok, err := h.config.TaskStore.Get(taskID)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to load task, starting a new task", "task_id", taskID, "error", err)
+		return h.newTaskID(rpcID)
+	}
+	if !ok || task.Status.State != StateInputRequired {
+		return h.newTaskID(rpcID)
+	}
+
+	logger.InfoContext(ctx, "Resuming input-required task", "task_id", taskID)
+	if msgParams.Message.ContextID == "" {
+		msgParams.Message.ContextID = task.ContextID
+	}
+	msgParams.resumed = &task
+	return task.ID
+}
+
+// clarifiedIdea joins the meaningful parts of the user's earlier messages
+// in a resumed task with their reply
+func (h *A2AHandler) clarifiedIdea(resumed TaskResult, reply string) string {
+	var parts []string
+	for _, msg := range resumed.History {
+		if msg.Role != RoleUser {
+			continue
+		}
+		if idea := h.extractBusinessIdea(msg); len(meaningfulWords(idea)) > 0 {
+			parts = append(parts, idea)
+		}
+	}
+	if reply != "" {
+		parts = append(parts, reply)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,100 @@
+package a2a
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// DataEncodingGzipBase64 is the encoding part metadata declares for a
+// compressed data part: its data is the base64 of the gzipped JSON
+const DataEncodingGzipBase64 = "gzip+base64"
+
+// compressDataParts compresses the large data parts of a task's status
+// message and artifacts when CompressDataAbove is set
+func (h *A2AHandler) compressDataParts(task *TaskResult) {
+	threshold := h.config.CompressDataAbove
+	if threshold <= 0 {
+		return
+	}
+	if task.Status.Message != nil {
+		compressParts(task.Status.Message.Parts, threshold)
+	}
+	for _, artifact := range task.Artifacts {
+		compressParts(artifact.Parts, threshold)
+	}
+}
+
+// compressParts replaces data parts whose JSON exceeds threshold bytes with
+// their gzip+base64 encoding. Parts that fail to encode are left as they are.
+func compressParts(parts []MessagePart, threshold int) {
+	for i, part := range parts {
+		if part.Kind != "data" || part.Data == nil {
+			continue
+		}
+		if _, done := part.Metadata["encoding"]; done {
+			continue
+		}
+
+		raw, err := json.Marshal(part.Data)
+		if err != nil || len(raw) <= threshold {
+			continue
+		}
+		encoded, err := gzipBase64(raw)
+		if err != nil {
+			logger.Warn("Failed to compress data part", "error", err)
+			continue
+		}
+
+		metadata := make(map[string]interface{}, len(part.Metadata)+3)
+		for key, value := range part.Metadata {
+			metadata[key] = value
+		}
+		metadata["encoding"] = DataEncodingGzipBase64
+		metadata["mimeType"] = "application/json"
+		metadata["originalSize"] = len(raw)
+
+		parts[i] = MessagePart{Kind: "data", Data: encoded, Metadata: metadata}
+	}
+}
+
+func gzipBase64(raw []byte) (string, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(raw); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decodeDataPart unmarshals a data part into v, decompressing it first if
+// compressParts encoded it
+func decodeDataPart(part MessagePart, v interface{}) error {
+	if part.Metadata["encoding"] != DataEncodingGzipBase64 {
+		raw, err := json.Marshal(part.Data)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(raw, v)
+	}
+
+	encoded, ok := part.Data.(string)
+	if !ok {
+		return fmt.Errorf("%s data part is not a string", DataEncodingGzipBase64)
+	}
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return json.NewDecoder(reader).Decode(v)
+}
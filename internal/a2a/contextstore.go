@@ -0,0 +1,67 @@
+package a2a
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+)
+
+// DefaultContextStoreCapacity bounds how many conversations the in-memory
+// context store keeps
+const DefaultContextStoreCapacity = 1000
+
+// ContextStore keeps the latest profiles of each conversation so a
+// follow-up message with the same contextId refines them
+type ContextStore interface {
+	// Save replaces the profiles recorded for the context
+	Save(contextID string, profiles *models.ProfileResponse) error
+	// Get returns the latest profiles of the context; ok is false if it is unknown
+	Get(contextID string) (profiles *models.ProfileResponse, ok bool, err error)
+}
+
+// MemoryContextStore is a bounded in-memory ContextStore. A conversation
+// expires ttl after its last turn, and the oldest is forgotten once full.
+// Contexts are lost on restart unless snapshots are written with Snapshot.
+type MemoryContextStore struct {
+	store *memoryStore[*models.ProfileResponse]
+}
+
+// NewMemoryContextStore creates a store holding up to capacity
+// conversations; a zero ttl keeps them until they are evicted
+func NewMemoryContextStore(capacity int, ttl time.Duration) *MemoryContextStore {
+	if capacity <= 0 {
+		capacity = DefaultContextStoreCapacity
+	}
+	return &MemoryContextStore{store: newMemoryStore(capacity, ttl, copyProfiles)}
+}
+
+func (s *MemoryContextStore) Save(contextID string, profiles *models.ProfileResponse) error {
+	s.store.set(contextID, profiles)
+	return nil
+}
+
+func (s *MemoryContextStore) Get(contextID string) (*models.ProfileResponse, bool, error) {
+	profiles, ok := s.store.get(contextID)
+	return profiles, ok, nil
+}
+
+// Snapshot writes the stored conversations to path
+func (s *MemoryContextStore) Snapshot(path string) error {
+	return s.store.snapshot(path)
+}
+
+// Restore loads conversations from a snapshot written by Snapshot and
+// returns how many were restored. A missing file restores nothing.
+func (s *MemoryContextStore) Restore(path string) (int, error) {
+	return s.store.restore(path)
+}
+
+// copyProfiles copies the persona slice a refinement rewrites
+func copyProfiles(profiles *models.ProfileResponse) *models.ProfileResponse {
+	if profiles == nil {
+		return nil
+	}
+	copied := *profiles
+	copied.Profiles = append([]models.CustomerProfile(nil), profiles.Profiles...)
+	return &copied
+}
@@ -0,0 +1,33 @@
+package a2a
+
+// MetadataCorrelation is the params metadata key of a caller's correlation
+// object. It is echoed back untouched in task metadata, so it also reaches
+// tasks/get and push notifications.
+const MetadataCorrelation = "correlation"
+
+// validateCorrelation checks that a message's correlation metadata, if
+// any, is a JSON object
+func validateCorrelation(msgParams MessageParams) *rpcError {
+	raw, ok := msgParams.Metadata[MetadataCorrelation]
+	if !ok || raw == nil {
+		return nil
+	}
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return invalidParam(MetadataCorrelation, MetadataCorrelation+" must be an object")
+	}
+	return nil
+}
+
+// withCorrelation copies the message's correlation object into the task's
+// metadata. A reply resuming an input-required task without one keeps the
+// task's earlier correlation.
+func withCorrelation(result TaskResult, msgParams MessageParams) TaskResult {
+	correlation, ok := msgParams.Metadata[MetadataCorrelation].(map[string]interface{})
+	if !ok && msgParams.resumed != nil {
+		correlation, ok = msgParams.resumed.Metadata[MetadataCorrelation].(map[string]interface{})
+	}
+	if !ok {
+		return result
+	}
+	return withTaskMetadata(result, MetadataCorrelation, correlation)
+}
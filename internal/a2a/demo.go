@@ -0,0 +1,68 @@
+package a2a
+
+import (
+	"fmt"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// DemoMaxPersonas is the default persona cap of demo mode
+const DemoMaxPersonas = 2
+
+// MetadataDemo is the artifact metadata key marking output of a demo
+const MetadataDemo = "demo"
+
+// demoNotice is the watermark appended to the text of demo output
+const demoNotice = "Demo output from a public evaluation instance of the Customer Profiler. Not for production use."
+
+// DemoConfig runs the agent as a public demo that anyone can try without
+// spending much of the model budget
+type DemoConfig struct {
+	// Limiter admits generation requests by client IP
+	Limiter *ratelimit.Limiter
+	// MaxPersonas caps personaCount; zero uses DemoMaxPersonas
+	MaxPersonas int
+}
+
+func (d *DemoConfig) maxPersonas() int {
+	if d.MaxPersonas <= 0 {
+		return DemoMaxPersonas
+	}
+	return min(d.MaxPersonas, profiler.MaxPersonaCount)
+}
+
+// admitDemo rate limits the generation requests of demo mode by client
+// IP, which forwarding headers set only from trusted proxies
+func (h *A2AHandler) admitDemo(c *gin.Context, method string) *rpcError {
+	if h.config.Demo == nil || h.config.Demo.Limiter == nil {
+		return nil
+	}
+
+	ip := h.clientIP(c)
+	allowed, retryAfter := h.config.Demo.Limiter.Allow(ip)
+	if allowed {
+		return nil
+	}
+
+	logger.WarnContext(c.Request.Context(), "Rate limited in demo mode", "method", method, "client_ip", ip)
+	return rateLimited(method, "Demo request limit reached", retryAfter)
+}
+
+// limitDemo caps the persona count of a demo request, with a warning
+func (h *A2AHandler) limitDemo(opts *profiler.GenerateOptions, warnings *limitWarnings) {
+	if h.config.Demo == nil {
+		return
+	}
+	limit := h.config.Demo.maxPersonas()
+	if opts.PersonaCount > limit {
+		*warnings = append(*warnings, fmt.Sprintf("%s: the demo generates at most %d; generating %d", MetadataPersonaCount, limit, limit))
+		opts.PersonaCount = limit
+	}
+}
+
+// demoWatermark renders the demo notice as closing lines of text output
+func demoWatermark(f flavor) string {
+	return "\n" + f.rule + "\n" + f.italic(f.escape(demoNotice)) + "\n"
+}
@@ -0,0 +1,39 @@
+package a2a
+
+import (
+	"fmt"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/locale"
+)
+
+// MetadataDisclosure is the artifact metadata key holding the AI-generation disclosure
+const MetadataDisclosure = "disclosure"
+
+// disclosureProvider is the vendor of every model the agent calls
+const disclosureProvider = "Google"
+
+// disclosureMetadata describes how an artifact was produced. An empty model
+// means the content came from a bundled template rather than a model.
+func disclosureMetadata(model, generatedAt string) map[string]interface{} {
+	disclosure := map[string]interface{}{
+		"aiGenerated":  model != "",
+		"agentVersion": agent.Version(),
+		"generatedAt":  generatedAt,
+	}
+	if model != "" {
+		disclosure["provider"] = disclosureProvider
+		disclosure["model"] = model
+	}
+	return disclosure
+}
+
+// disclosureFooter renders the disclosure as a closing line for text output,
+// naming the agent as agentName
+func disclosureFooter(agentName, model, generatedAt string, headings locale.Headings, f flavor) string {
+	line := fmt.Sprintf("%s %s · %s", agentName, agent.Version(), generatedAt)
+	if model != "" {
+		line = fmt.Sprintf("%s · %s %s · %s", headings.AIGenerated, disclosureProvider, model, line)
+	}
+	return "\n" + f.rule + "\n" + f.italic(f.escape(line)) + "\n"
+}
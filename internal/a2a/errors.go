@@ -0,0 +1,67 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestid"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+)
+
+// rpcError is a JSON-RPC error raised while processing a request
+type rpcError struct {
+	code    a2aerrors.Code
+	message string
+	data    ErrorData
+	// status is the HTTP status of the response; zero means 200, as for
+	// most errors
+	status int
+	// retryAfter, when set, is sent as the Retry-After header
+	retryAfter time.Duration
+}
+
+// object builds the wire error. Data is only sent when it says more than
+// the code does.
+func (e *rpcError) object() *JSONRPCError {
+	data := e.data
+	data.Retryable = data.Retryable || e.code.Retryable()
+
+	obj := &JSONRPCError{Code: e.code, Message: e.message}
+	if data != (ErrorData{}) {
+		obj.Data = &data
+	}
+	return obj
+}
+
+// objectFor builds the wire error of the request ctx belongs to, naming
+// its request ID so support can find it in the logs
+func (e *rpcError) objectFor(ctx context.Context) *JSONRPCError {
+	withID := *e
+	withID.data.RequestID = requestid.FromContext(ctx)
+	return withID.object()
+}
+
+// invalidParam is a validation failure of the named parameter
+func invalidParam(field, message string) *rpcError {
+	return &rpcError{code: CodeInvalidParams, message: message, data: ErrorData{Field: field}}
+}
+
+// taskNotFound is returned for an unknown or expired task ID
+func taskNotFound(taskID string) *rpcError {
+	return &rpcError{
+		code:    CodeTaskNotFound,
+		message: fmt.Sprintf("Task not found: %s", taskID),
+		data:    ErrorData{TaskID: taskID},
+	}
+}
+
+// taskStoreError is returned when the task store fails; the request may
+// succeed once the store recovers
+func taskStoreError(taskID string) *rpcError {
+	return &rpcError{
+		code:    CodeInternalError,
+		message: "Failed to load task",
+		data:    ErrorData{TaskID: taskID},
+	}
+}
@@ -0,0 +1,124 @@
+package a2a
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/analytics"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/metrics"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/gin-gonic/gin"
+)
+
+// MetadataFeedback is the task metadata key of the end user's rating
+const MetadataFeedback = "feedback"
+
+// MetadataPromptVariant is the task metadata key naming the prompt a task
+// was generated with, so its feedback can be compared across prompts
+const MetadataPromptVariant = "promptVariant"
+
+// Prompt variants: the built-in prompt, or one extended by a prompt override
+const (
+	PromptVariantDefault  = "default"
+	PromptVariantOverride = "override"
+)
+
+// MaxFeedbackCommentLength caps the characters of a feedback comment
+const MaxFeedbackCommentLength = 1000
+
+// promptVariant names the prompt a prepared task generates with
+func promptVariant(task *preparedTask) string {
+	if task.opts.PromptOverride != "" {
+		return PromptVariantOverride
+	}
+	return PromptVariantDefault
+}
+
+// handleFeedback records an end user's rating of a completed task's
+// profiles in the task's metadata and the feedback analytics. Each task
+// takes one rating; a low one with a comment may start a refinement.
+func (h *A2AHandler) handleFeedback(c *gin.Context, rpcReq JSONRPCRequest) {
+	paramsJSON, err := json.Marshal(rpcReq.Params)
+	if err != nil {
+		h.sendErrorResponse(c, rpcReq.ID, "Failed to parse parameters", CodeInvalidParams)
+		return
+	}
+
+	var params TaskFeedbackParams
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		h.sendErrorResponse(c, rpcReq.ID, "Invalid parameters", CodeInvalidParams)
+		return
+	}
+	if params.ID == "" {
+		h.sendError(c, rpcReq.ID, invalidParam("id", "Invalid parameters: id is required"))
+		return
+	}
+	if params.Rating < analytics.MinRating || params.Rating > analytics.MaxRating {
+		h.sendError(c, rpcReq.ID, invalidParam("rating", fmt.Sprintf("rating must be from %d to %d", analytics.MinRating, analytics.MaxRating)))
+		return
+	}
+	if utf8.RuneCountInString(params.Comment) > MaxFeedbackCommentLength {
+		h.sendError(c, rpcReq.ID, invalidParam("comment", fmt.Sprintf("comment must be at most %d characters", MaxFeedbackCommentLength)))
+		return
+	}
+	params.ID = h.resolveTaskID(params.ID)
+
+	// Serialize submissions so a task can't be rated twice at once
+	h.feedbackMu.Lock()
+	defer h.feedbackMu.Unlock()
+
+	task, ok, err := h.config.TaskStore.Get(params.ID)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Failed to load task", "task_id", params.ID, "error", err)
+		h.sendError(c, rpcReq.ID, taskStoreError(params.ID))
+		return
+	}
+	if !ok {
+		h.sendError(c, rpcReq.ID, taskNotFound(params.ID))
+		return
+	}
+	if task.Status.State != StateCompleted {
+		h.sendError(c, rpcReq.ID, &rpcError{
+			code:    CodeInvalidParams,
+			message: fmt.Sprintf("Only completed tasks take feedback: %s is %s", params.ID, task.Status.State),
+			data:    ErrorData{Field: "id", TaskID: params.ID},
+		})
+		return
+	}
+	if _, rated := task.Metadata[MetadataFeedback]; rated {
+		h.sendError(c, rpcReq.ID, &rpcError{
+			code:    CodeInvalidParams,
+			message: fmt.Sprintf("Feedback was already submitted for task %s", params.ID),
+			data:    ErrorData{Field: "id", TaskID: params.ID},
+		})
+		return
+	}
+
+	feedback := Feedback{
+		Rating:      params.Rating,
+		Comment:     redact.ForStore(params.Comment),
+		SubmittedAt: Timestamp(),
+	}
+	if h.shouldRegenerate(feedback) {
+		feedback.RegenerationTaskID = h.regenerate(c, task, feedback.Comment)
+	}
+	task = withTaskMetadata(task, MetadataFeedback, feedback)
+	if err := h.config.TaskStore.Save(task); err != nil {
+		logger.ErrorContext(c.Request.Context(), "Failed to store feedback", "task_id", params.ID, "error", err)
+		h.sendError(c, rpcReq.ID, taskStoreError(params.ID))
+		return
+	}
+
+	variant, _ := task.Metadata[MetadataPromptVariant].(string)
+	if variant == "" {
+		variant = PromptVariantDefault
+	}
+	metrics.ObserveFeedback(variant, params.Rating)
+	if h.config.FeedbackStats != nil {
+		h.config.FeedbackStats.Record(variant, params.Rating, params.Comment != "")
+	}
+	logger.InfoContext(c.Request.Context(), "Task rated", "task_id", params.ID, "rating", params.Rating, "prompt_variant", variant)
+
+	h.sendSuccessResponse(c, rpcReq.ID, task)
+}
@@ -0,0 +1,152 @@
+package a2a
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/document"
+)
+
+// documentText extracts the text of a message's file parts, fetching files
+// sent by URI. Documents the agent can't read are rejected.
+func (h *A2AHandler) documentText(ctx context.Context, msg A2AMessage) (string, *rpcError) {
+	var texts []string
+	for i, part := range msg.Parts {
+		if part.Kind != "file" || part.File == nil {
+			continue
+		}
+		field := fmt.Sprintf("message.parts[%d].file", i)
+
+		data, mimeType, rpcErr := h.fileBytes(ctx, *part.File, field)
+		if rpcErr != nil {
+			return "", rpcErr
+		}
+
+		text, err := document.Extract(part.File.Name, mimeType, data)
+		if errors.Is(err, document.ErrUnsupported) {
+			return "", &rpcError{
+				code:    CodeContentTypeNotSupported,
+				message: fmt.Sprintf("Cannot read the file: %v (supported: %s)", err, strings.Join(document.Supported, ", ")),
+				data:    ErrorData{Field: field},
+			}
+		}
+		if err != nil {
+			return "", invalidParam(field, fmt.Sprintf("Failed to read document: %v", err))
+		}
+
+		logger.InfoContext(ctx, "Extracted document text", "document", part.File.Name, "characters", len(text))
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// fileBytes returns a file part's content and MIME type, decoding inline
+// bytes or downloading the URI
+func (h *A2AHandler) fileBytes(ctx context.Context, file FileContent, field string) ([]byte, string, *rpcError) {
+	switch {
+	case file.Bytes != "":
+		if base64.StdEncoding.DecodedLen(len(file.Bytes)) > document.MaxSize {
+			return nil, "", invalidParam(field, fmt.Sprintf("Document is larger than %d bytes", document.MaxSize))
+		}
+		data, err := base64.StdEncoding.DecodeString(file.Bytes)
+		if err != nil {
+			return nil, "", invalidParam(field, "File bytes must be base64-encoded")
+		}
+		return data, file.MimeType, nil
+	case file.URI != "":
+		return h.downloadFile(ctx, file, field)
+	default:
+		return nil, "", invalidParam(field, "A file part needs bytes or a uri")
+	}
+}
+
+// downloadFile fetches a document over http(s)
+func (h *A2AHandler) downloadFile(ctx context.Context, file FileContent, field string) ([]byte, string, *rpcError) {
+	parsed, err := url.Parse(file.URI)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, "", invalidParam(field, "File uri must be an absolute http(s) URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, "", invalidParam(field, "File uri must be an absolute http(s) URL")
+	}
+	resp, err := h.fileClient.Do(req)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to download document", "error", err)
+		return nil, "", invalidParam(field, "Failed to download the file")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", invalidParam(field, fmt.Sprintf("Downloading the file returned HTTP %d", resp.StatusCode))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, document.MaxSize+1))
+	if err != nil {
+		return nil, "", invalidParam(field, "Failed to download the file")
+	}
+	if len(data) > document.MaxSize {
+		return nil, "", invalidParam(field, fmt.Sprintf("Document is larger than %d bytes", document.MaxSize))
+	}
+
+	mimeType := file.MimeType
+	if mimeType == "" {
+		mimeType = resp.Header.Get("Content-Type")
+	}
+	return data, mimeType, nil
+}
+
+// newPublicClient connects to public addresses only, so URLs callers hand
+// the agent, file URIs and push webhooks, can't reach services on its own
+// network. Redirects are dialed through the same check.
+func newPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("refusing to connect to %s", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("refusing to follow a redirect to %s", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// maxRedirects is how many redirects newPublicClient follows, as many as
+// http.Client does by default
+const maxRedirects = 10
+
+// publicIP tells whether ip is reachable on the internet rather than a
+// loopback, private, link-local or unspecified address
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsUnspecified()
+}
@@ -0,0 +1,238 @@
+package a2a
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/locale"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+)
+
+// DefaultFormat is the text flavor used when a request doesn't pick one
+const DefaultFormat = "markdown"
+
+// flavor renders the building blocks of profile text for one kind of chat
+// frontend. Every func receives text that is already escaped.
+type flavor struct {
+	escape func(text string) string
+	title  func(text string) string
+	bold   func(text string) string
+	italic func(text string) string
+	quote  func(text string) string
+	// link receives the raw title and URI and escapes them itself
+	link   func(title, uri string) string
+	bullet string
+	rule   string
+}
+
+func identity(text string) string { return text }
+
+func wrap(open, close string) func(string) string {
+	return func(text string) string { return open + text + close }
+}
+
+// slackEscaper escapes the characters Slack mrkdwn treats as control characters
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// flavors are the text formats selectable with the format metadata field
+var flavors = map[string]flavor{
+	"markdown": {
+		escape: identity,
+		title:  wrap("# ", ""),
+		bold:   wrap("**", "**"),
+		italic: wrap("_", "_"),
+		quote:  wrap("> ", ""),
+		link: func(title, uri string) string {
+			return fmt.Sprintf("[%s](%s)", title, uri)
+		},
+		bullet: "- ",
+		rule:   "---",
+	},
+	"plain": {
+		escape: identity,
+		title:  identity,
+		bold:   identity,
+		italic: identity,
+		quote:  identity,
+		link: func(title, uri string) string {
+			if title == uri {
+				return uri
+			}
+			return fmt.Sprintf("%s (%s)", title, uri)
+		},
+		bullet: "- ",
+		rule:   "----------",
+	},
+	"slack": {
+		escape: slackEscaper.Replace,
+		title:  wrap("*", "*"),
+		bold:   wrap("*", "*"),
+		italic: wrap("_", "_"),
+		quote:  wrap("> ", ""),
+		link: func(title, uri string) string {
+			return fmt.Sprintf("<%s|%s>", uri, slackEscaper.Replace(title))
+		},
+		bullet: "• ",
+		rule:   "──────────",
+	},
+	"telegram": {
+		escape: html.EscapeString,
+		title:  wrap("<b>", "</b>"),
+		bold:   wrap("<b>", "</b>"),
+		italic: wrap("<i>", "</i>"),
+		quote:  wrap("<blockquote>", "</blockquote>"),
+		link: func(title, uri string) string {
+			return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(uri), html.EscapeString(title))
+		},
+		bullet: "• ",
+		rule:   "──────────",
+	},
+}
+
+// resolveFlavor looks up a text format by name; empty means DefaultFormat
+func resolveFlavor(name string) (flavor, *rpcError) {
+	if name == "" {
+		name = DefaultFormat
+	}
+	f, ok := flavors[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(flavors))
+		for known := range flavors {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return flavor{}, &rpcError{
+			code:    CodeInvalidParams,
+			message: fmt.Sprintf("Unsupported %s %q (use one of: %s)", MetadataFormat, name, strings.Join(names, ", ")),
+			data:    ErrorData{Field: MetadataFormat},
+		}
+	}
+	return f, nil
+}
+
+// label renders a section label such as "Demographics:"
+func (f flavor) label(text string) string {
+	return f.bold(f.escape(text) + ":")
+}
+
+func (h *A2AHandler) formatProfileResponse(profileResp *models.ProfileResponse, render renderOptions) string {
+	headings, f := render.loc.Headings, render.flavor
+	if len(profileResp.Profiles) == 0 {
+		return f.escape(headings.NoProfiles)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(f.title(f.escape(fmt.Sprintf("%s: %s", headings.ProfileFor, profileResp.BusinessIdea))) + "\n\n")
+
+	if profileResp.Degraded {
+		builder.WriteString(f.quote(f.label(headings.TemplatePersona)+" "+f.escape(profileResp.Summary)) + "\n\n")
+	} else if render.summaryFirst && profileResp.Summary != "" {
+		writeSummary(&builder, profileResp, headings, f)
+		builder.WriteString("\n" + f.rule + "\n\n")
+	}
+
+	for i, profile := range profileResp.Profiles {
+		if i > 0 {
+			builder.WriteString("\n" + f.rule + "\n\n")
+		}
+		writeProfile(&builder, profile, headings, f)
+	}
+
+	for _, journey := range profileResp.Journeys {
+		builder.WriteString("\n" + f.rule + "\n\n")
+		writeJourney(&builder, journey, headings, f)
+	}
+
+	if len(profileResp.Sources) > 0 {
+		builder.WriteString("\n" + f.rule + "\n\n" + f.label(headings.Sources) + "\n")
+		for _, source := range profileResp.Sources {
+			title := source.Title
+			if title == "" {
+				title = source.URI
+			}
+			builder.WriteString(f.bullet + f.link(title, source.URI) + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// writeSummary formats the market summary and keywords
+func writeSummary(builder *strings.Builder, profileResp *models.ProfileResponse, headings locale.Headings, f flavor) {
+	builder.WriteString(f.label(headings.Summary) + " " + f.escape(profileResp.Summary) + "\n")
+	if len(profileResp.Keywords) > 0 {
+		builder.WriteString("\n" + f.label(headings.Keywords) + " " + f.escape(strings.Join(profileResp.Keywords, ", ")) + "\n")
+	}
+}
+
+// writeProfile formats one persona
+func writeProfile(builder *strings.Builder, profile models.CustomerProfile, headings locale.Headings, f flavor) {
+	if profile.FitWarning != "" {
+		builder.WriteString(f.label(headings.FitWarning) + " " + f.escape(profile.FitWarning) + "\n\n")
+	}
+	builder.WriteString(f.label(headings.Demographics) + "\n")
+	for _, field := range [][2]string{
+		{headings.Age, profile.Age},
+		{headings.Gender, profile.Gender},
+		{headings.Location, profile.Location},
+		{headings.Occupation, profile.Occupation},
+		{headings.Income, profile.Income},
+	} {
+		builder.WriteString(fmt.Sprintf("%s%s: %s\n", f.bullet, f.escape(field[0]), f.escape(field[1])))
+	}
+	for _, key := range sortedKeys(profile.Custom) {
+		builder.WriteString(fmt.Sprintf("%s%s: %s\n", f.bullet, f.escape(fieldLabel(key)), f.escape(profile.Custom[key])))
+	}
+
+	for _, section := range []struct {
+		heading string
+		items   []string
+	}{
+		{headings.PainPoints, profile.PainPoints},
+		{headings.Motivations, profile.Motivations},
+		{headings.Interests, profile.Interests},
+		{headings.PreferredChannels, profile.PreferredChannels},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		builder.WriteString("\n" + f.label(section.heading) + "\n")
+		for _, item := range section.items {
+			builder.WriteString(f.bullet + f.escape(strings.TrimSpace(item)) + "\n")
+		}
+	}
+}
+
+// writeJourney formats one persona's journey map, a section per stage
+func writeJourney(builder *strings.Builder, journey models.Journey, headings locale.Headings, f flavor) {
+	builder.WriteString(f.label(fmt.Sprintf("%s (%s %d)", headings.Journey, headings.Persona, journey.Persona)) + "\n")
+	for _, stage := range journey.Stages {
+		builder.WriteString("\n" + f.label(fieldLabel(stage.Stage)) + " " + f.escape(stage.Goal) + "\n")
+		for _, field := range [][2]string{
+			{headings.Touchpoints, strings.Join(stage.Touchpoints, ", ")},
+			{headings.PainPoint, stage.PainPoint},
+			{headings.Opportunity, stage.Opportunity},
+		} {
+			if field[1] != "" {
+				builder.WriteString(fmt.Sprintf("%s%s: %s\n", f.bullet, f.escape(field[0]), f.escape(field[1])))
+			}
+		}
+	}
+}
+
+// fieldLabel turns a custom field key such as "tech_savviness" into a label
+func fieldLabel(key string) string {
+	label := strings.ReplaceAll(key, "_", " ")
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
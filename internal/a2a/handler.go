@@ -3,234 +3,818 @@ package a2a
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/accesslog"
 	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/analytics"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/auth"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/avatar"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/latency"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/locale"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/metrics"
 	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/policy"
 	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profilestore"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestlog"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/tracing"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/agentkit"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type A2AHandler struct {
-	geminiClient *profiler.GeminiClient
+	generator    profiler.ProfileGenerator
+	config       HandlerConfig
+	running      *runningTasks
+	streaming    *streamingTasks
+	requestTasks *requestTasks
+	idempotency  *idempotencyKeys
+	workers      *workerPool
+	requests     requestStats
+	// card is the served agent card, swapped in whole by SetAgentCard
+	card atomic.Pointer[agent.AgentCard]
+
+	push       *pushConfigs
+	pushClient *http.Client
+	fileClient *http.Client
+
+	methods *agentkit.Methods
+
+	// feedbackMu serializes feedback submissions
+	feedbackMu sync.Mutex
+
+	// internal serves in-process requests for batches and replays
+	internalOnce sync.Once
+	internal     *gin.Engine
 }
 
-func NewA2AHandler(geminiClient *profiler.GeminiClient) *A2AHandler {
-	return &A2AHandler{
-		geminiClient: geminiClient,
-	}
+// HandlerConfig holds optional handler behaviour configured at startup
+type HandlerConfig struct {
+	// PromptOverrideKey enables the promptOverride metadata field for callers
+	// presenting it in the X-Prompt-Override-Key header. Empty disables overrides.
+	PromptOverrideKey string
+	// PersonaLibrary, if set, records every generated persona for analytics
+	PersonaLibrary *analytics.PersonaLibrary
+	// ProfileRepository, if set, keeps every generation's profiles with
+	// their artifacts, caller and timing
+	ProfileRepository profilestore.Repository
+	// FeedbackStats, if set, aggregates the ratings submitted with
+	// tasks/feedback for analytics
+	FeedbackStats *analytics.FeedbackStats
+	// RegenerateBelow, if set, refines a task's profiles automatically when
+	// it is rated below this with a comment, taking the comment as guidance
+	RegenerateBelow int
+	// AvatarGenerator and AvatarStore enable the avatars metadata flag
+	AvatarGenerator profiler.AvatarGenerator
+	AvatarStore     *avatar.Store
+	// PublicBaseURL is the externally reachable base URL used in avatar
+	// links; empty derives it from the request host.
+	PublicBaseURL string
+	// DisclosureFooter appends an AI-generation notice to the profile text;
+	// artifacts always carry it in their metadata
+	DisclosureFooter bool
+	// Model is the Gemini model named in disclosures; empty means
+	// profiler.ModelName
+	Model string
+	// PolicyEnforcer, if set, can refuse ideas before any model call
+	PolicyEnforcer policy.Enforcer
+	// RequestLog, if set, records every request for the debug endpoint
+	RequestLog *requestlog.Log
+	// TaskStore keeps finished tasks for tasks/get; nil uses a
+	// MemoryTaskStore of DefaultTaskStoreCapacity and DefaultStoreTTL.
+	TaskStore TaskStore
+	// IdempotencyTTL is how long idempotency keys are remembered; zero uses
+	// DefaultIdempotencyTTL. Replays also need the task still in TaskStore.
+	IdempotencyTTL time.Duration
+	// Workers bounds how many generations run at once, and WorkerQueue how
+	// many more may wait for a worker; requests beyond both get a 429. Zero
+	// workers runs every generation at once. WhenBusy is BusyWait, the
+	// default, or BusyAsync.
+	Workers     int
+	WorkerQueue int
+	WhenBusy    string
+	// BatchConcurrency bounds how many requests of a JSON-RPC batch run at
+	// once; zero uses DefaultBatchConcurrency and 1 runs them in order.
+	BatchConcurrency int
+	// MaxBodySize, MaxJSONDepth and MaxMessageParts bound the size of a
+	// request body, how deep its JSON nests and how many parts its message
+	// has; zero uses the defaults
+	MaxBodySize     int64
+	MaxJSONDepth    int
+	MaxMessageParts int
+	// DryRun suppresses outbound side effects: push notifications are not
+	// delivered. Replays use it.
+	DryRun bool
+	// ContextStore keeps each conversation's latest profiles for follow-up
+	// refinements; nil uses a MemoryContextStore of DefaultContextStoreCapacity
+	// and DefaultStoreTTL.
+	ContextStore ContextStore
+	// CompressDataAbove gzips and base64-encodes data parts whose JSON is
+	// larger than this many bytes; zero leaves them uncompressed
+	CompressDataAbove int
+	// MethodAliases maps alternative method names to the methods they call;
+	// nil uses DefaultMethodAliases and an empty map accepts no aliases
+	MethodAliases map[string]string
+	// DisabledMethods are methods or aliases answered as unknown, so
+	// deprecated names can be sunset
+	DisabledMethods []string
+	// RejectAliases refuses requests sent with any alias, naming the method
+	// to use instead. Otherwise aliases work but their responses carry a
+	// deprecation notice.
+	RejectAliases bool
+	// PreferenceStore keeps the defaults applied to each tenant's
+	// generations; nil uses a MemoryPreferenceStore of
+	// DefaultPreferenceStoreCapacity.
+	PreferenceStore PreferenceStore
+	// AgentCard is served at /.well-known/agent.json; nil builds one from
+	// AgentCardConfig. SetAgentCard replaces it while running.
+	AgentCard *agent.AgentCard
+	// ExtendedCardToken enables agent/getAuthenticatedExtendedCard for
+	// callers presenting it as a bearer token. Empty disables the method.
+	ExtendedCardToken string
+	// OperatorEndpoints are paths of operator routes, by name, listed in
+	// the extended card
+	OperatorEndpoints map[string]string
+	// Branding white-labels the agent card and generated text for tenants,
+	// by the tenant ID of their X-Tenant-ID header
+	Branding map[string]agent.Branding
+	// Demo, if set, runs the agent as a public demo: generation is rate
+	// limited per client IP, persona counts are capped, output is
+	// watermarked, and avatars and push notifications are turned off
+	Demo *DemoConfig
+	// RateLimit, if set, limits generation requests per client IP and per
+	// API key
+	RateLimit *RateLimitConfig
+	// TrustedProxies are the proxies whose forwarding headers name the
+	// client; with none, the client IP is the connection's remote address
+	TrustedProxies []string
+	// APIKeys and BearerTokens, if set, require every request to present
+	// a key from APIKeys in the X-API-Key header or a bearer token that
+	// BearerTokens accepts, except anonymous callers in demo mode
+	APIKeys      auth.KeyStore
+	BearerTokens auth.TokenVerifier
+	// ClientCerts requires credentials like APIKeys, and accepts a TLS
+	// client certificate the server verified as one
+	ClientCerts bool
+	// ReadOnly refuses generation and the methods that write tasks with a
+	// maintenance error while retrieval keeps working, e.g. during store
+	// migrations
+	ReadOnly bool
+	// ReadOnlyETA, if set, tells refused callers when to retry
+	ReadOnlyETA time.Time
+	// GenerationTimeout, if set, bounds each generation; tasks that run
+	// out of time fail with the deadline_exceeded reason
+	GenerationTimeout time.Duration
+	// SoftLimits clamps values over the persona count, input length and
+	// custom field limits and reports a warning instead of rejecting them
+	SoftLimits bool
 }
 
-// RequestLoggingMiddleware logs all incoming requests
-func RequestLoggingMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Read the body
-		bodyBytes, _ := io.ReadAll(c.Request.Body)
-
-		// Log the raw request
-		log.Printf("=== INCOMING REQUEST ===")
-		log.Printf("Method: %s", c.Request.Method)
-		log.Printf("Path: %s", c.Request.URL.Path)
-		log.Printf("Headers: %v", c.Request.Header)
-		log.Printf("Body: %s", string(bodyBytes))
-		log.Printf("========================")
-
-		// Restore the body for the handler
-		c.Request.Body = io.NopCloser(strings.NewReader(string(bodyBytes)))
-
-		c.Next()
+// PersonaArtifactThreshold is the persona count from which each persona
+// gets its own artifact instead of sharing one
+const PersonaArtifactThreshold = 4
+
+// MetadataLatency is the task metadata key of the per-stage latency
+// breakdown, in milliseconds
+const MetadataLatency = "latency"
+
+// DefaultGenerationTimeout bounds generations unless the config says
+// otherwise; several personas with avatars fit comfortably
+const DefaultGenerationTimeout = 3 * time.Minute
+
+// MetadataFailureReason is the task metadata key saying why a failed task
+// failed, e.g. FailureDeadlineExceeded
+const MetadataFailureReason = "failureReason"
+
+// Failure reasons of failed tasks
+const (
+	FailureDeadlineExceeded = "deadline_exceeded"
+	FailureModeration       = "moderation"
+	FailureTruncatedOutput  = "truncated_output"
+	FailureInputTooLarge    = "input_too_large"
+	FailureInputUnusable    = "input_unusable"
+	FailureGeneration       = "generation_error"
+)
 
-		// Log response status
-		log.Printf("=== RESPONSE ===")
-		log.Printf("Status: %d", c.Writer.Status())
-		log.Printf("================")
+func NewA2AHandler(generator profiler.ProfileGenerator, config HandlerConfig) *A2AHandler {
+	if config.TaskStore == nil {
+		config.TaskStore = NewMemoryTaskStore(DefaultTaskStoreCapacity, DefaultStoreTTL)
 	}
+	if config.ContextStore == nil {
+		config.ContextStore = NewMemoryContextStore(DefaultContextStoreCapacity, DefaultStoreTTL)
+	}
+	if config.PreferenceStore == nil {
+		config.PreferenceStore = NewMemoryPreferenceStore(DefaultPreferenceStoreCapacity)
+	}
+	if config.Demo != nil {
+		config.DisclosureFooter = true
+		config.DryRun = true
+		config.AvatarGenerator = nil
+	}
+	if config.AgentCard == nil {
+		card, err := agent.BuildCard(AgentCardConfig(config))
+		if err != nil {
+			logger.Error("Failed to build the agent card", "error", err)
+		}
+		config.AgentCard = card
+	}
+	h := &A2AHandler{
+		generator:    generator,
+		config:       config,
+		running:      newRunningTasks(),
+		streaming:    newStreamingTasks(),
+		requestTasks: newRequestTasks(DefaultTaskStoreCapacity),
+		idempotency:  newIdempotencyKeys(DefaultTaskStoreCapacity, config.IdempotencyTTL),
+		workers:      newWorkerPool(config.Workers, config.WorkerQueue),
+
+		push:       newPushConfigs(DefaultTaskStoreCapacity),
+		pushClient: newPublicClient(10 * time.Second),
+		fileClient: newPublicClient(30 * time.Second),
+	}
+	h.card.Store(config.AgentCard)
+	h.registerMethods()
+	return h
 }
 
 // HandleProfiler processes A2A messages
 func (h *A2AHandler) HandleProfiler(c *gin.Context) {
-	// Read and log the raw body first
-	bodyBytes, err := io.ReadAll(c.Request.Body)
-	if err != nil {
-		log.Printf("ERROR: Failed to read request body: %v", err)
-		h.sendErrorResponse(c, "", "Failed to read request body", -32700)
+	defer h.beginTrace(c)()
+
+	// The body is read once here; everything after decodes bodyBytes
+	bodyBytes, rpcErr := h.readBody(c)
+	if rpcErr != nil {
+		h.sendError(c, "", rpcErr)
 		return
 	}
 
-	log.Printf("=== RAW REQUEST BODY ===")
-	log.Printf("%s", string(bodyBytes))
-	log.Printf("========================")
+	// The request log drops larger bodies, so don't copy them
+	if len(bodyBytes) <= requestlog.MaxRequestSize {
+		c.Set(ctxKeyRequest, string(bodyBytes))
+	}
+
+	if h.requiresAuth() && !h.extendedCardRequest(bodyBytes) {
+		if rpcErr := h.authenticate(c); rpcErr != nil {
+			h.sendError(c, "", rpcErr)
+			return
+		}
+	}
 
-	// Restore body for JSON parsing
-	c.Request.Body = io.NopCloser(strings.NewReader(string(bodyBytes)))
+	debugBody(c.Request.Context(), "Request body", bodyBytes)
 
-	// Try to parse as raw JSON first to see structure
-	var rawJSON map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &rawJSON); err == nil {
-		log.Printf("=== PARSED JSON STRUCTURE ===")
-		prettyJSON, _ := json.MarshalIndent(rawJSON, "", "  ")
-		log.Printf("%s", string(prettyJSON))
-		log.Printf("============================")
+	if isBatch(bodyBytes) {
+		h.handleBatch(c, bodyBytes)
+		return
 	}
 
-	// Restore body again for binding
-	c.Request.Body = io.NopCloser(strings.NewReader(string(bodyBytes)))
+	if isNotification(bodyBytes) {
+		h.handleNotification(c, bodyBytes)
+		return
+	}
 
 	// Parse JSON-RPC request
-	var rpcReq JSONRPCRequest
-	if err := c.ShouldBindJSON(&rpcReq); err != nil {
-		log.Printf("ERROR: Failed to decode request as JSON-RPC: %v", err)
-		log.Printf("Trying alternative direct message parsing...")
+	rpcReq, err := decodeRequest(bodyBytes)
+	if err != nil {
+		logger.WarnContext(c.Request.Context(), "Failed to decode request as JSON-RPC; trying it as a direct message", "error", err)
 
 		// Try parsing without JSON-RPC wrapper
 		h.handleDirectMessage(c, bodyBytes)
 		return
 	}
 
-	log.Printf("=== PARSED RPC REQUEST ===")
-	log.Printf("JSONRPC: %s", rpcReq.JSONRPC)
-	log.Printf("ID: %s", rpcReq.ID)
-	log.Printf("Method: %s", rpcReq.Method)
-	log.Printf("Params: %+v", rpcReq.Params)
-	log.Printf("==========================")
+	logger.DebugContext(c.Request.Context(), "Parsed JSON-RPC request", "jsonrpc", rpcReq.JSONRPC, "id", rpcReq.ID, "method", rpcReq.Method)
 
 	// Validate JSON-RPC version
 	if rpcReq.JSONRPC != "2.0" {
-		log.Printf("WARN: Invalid JSON-RPC version: %s", rpcReq.JSONRPC)
-		h.sendErrorResponse(c, rpcReq.ID, "Invalid JSON-RPC version", -32600)
+		logger.WarnContext(c.Request.Context(), "Invalid JSON-RPC version", "jsonrpc", rpcReq.JSONRPC)
+		h.sendErrorResponse(c, rpcReq.ID, "Invalid JSON-RPC version", CodeInvalidRequest)
 		return
 	}
 
-	// Handle different methods
-	switch rpcReq.Method {
-	case "agent/task":
-		h.handleTask(c, rpcReq)
-	case "message/send":
-		h.handleTask(c, rpcReq)
-	default:
-		log.Printf("ERROR: Unknown method: %s", rpcReq.Method)
-		h.sendErrorResponse(c, rpcReq.ID, fmt.Sprintf("Method not found: %s", rpcReq.Method), -32601)
+	c.Set(ctxKeyMethod, rpcReq.Method)
+
+	method, handler, ok := h.methods.Resolve(rpcReq.Method)
+	if !ok {
+		logger.WarnContext(c.Request.Context(), "Unknown method", "method", rpcReq.Method)
+		h.sendError(c, rpcReq.ID, &rpcError{
+			code:    CodeMethodNotFound,
+			message: fmt.Sprintf("Method not found: %s", rpcReq.Method),
+			data:    ErrorData{Method: rpcReq.Method},
+		})
+		return
+	}
+	if method != rpcReq.Method {
+		if rpcErr := h.deprecatedAlias(c, rpcReq.Method, method); rpcErr != nil {
+			h.sendError(c, rpcReq.ID, rpcErr)
+			return
+		}
+	}
+	tracing.SetAttributes(c.Request.Context(), semconv.RPCSystemKey.String("jsonrpc"), semconv.RPCMethod(method))
+	if rpcErr := h.guardWrite(c, rpcReq.Method, method); rpcErr != nil {
+		h.sendError(c, rpcReq.ID, rpcErr)
+		return
+	}
+	handler(c, rpcReq)
+}
+
+// guardWrite refuses a write method in read-only mode or while draining,
+// and applies the demo and rate limits to it. requested is the method as
+// the caller named it, method what it resolved to.
+func (h *A2AHandler) guardWrite(c *gin.Context, requested, method string) *rpcError {
+	if !writeMethods[method] {
+		return nil
 	}
+	if h.refusesWrites() {
+		return h.maintenanceError(c.Request.Context(), requested)
+	}
+	return h.admitWrite(c, requested)
+}
+
+// decodeRequest parses a JSON-RPC request. Its params are kept as raw JSON,
+// so each method decodes them straight into its own params type.
+func decodeRequest(body []byte) (JSONRPCRequest, error) {
+	var envelope struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      string          `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return JSONRPCRequest{}, err
+	}
+	rpcReq := JSONRPCRequest{JSONRPC: envelope.JSONRPC, ID: envelope.ID, Method: envelope.Method}
+	if len(envelope.Params) > 0 {
+		rpcReq.Params = envelope.Params
+	}
+	return rpcReq, nil
 }
 
 // handleDirectMessage tries to handle message without JSON-RPC wrapper
 func (h *A2AHandler) handleDirectMessage(c *gin.Context, bodyBytes []byte) {
-	log.Printf("STATE: ATTEMPTING DIRECT MESSAGE PARSE")
+	logger.DebugContext(c.Request.Context(), "Parsing direct message")
+	c.Set(ctxKeyMethod, "direct-message")
 
 	var msgParams MessageParams
 	if err := json.Unmarshal(bodyBytes, &msgParams); err != nil {
-		log.Printf("ERROR: Failed to parse as direct message: %v", err)
-		h.sendErrorResponse(c, "", "Invalid request format", -32700)
+		logger.WarnContext(c.Request.Context(), "Failed to parse as direct message", "error", err)
+		h.sendErrorResponse(c, "", "Invalid request format", CodeParseError)
 		return
 	}
 
-	log.Printf("Successfully parsed as direct message")
+	logger.DebugContext(c.Request.Context(), "Parsed direct message")
 
-	// Extract business idea
-	businessIdea := h.extractBusinessIdea(msgParams.Message)
-	log.Printf("Extracted business idea: '%s'", businessIdea)
-
-	if businessIdea == "" {
-		result := h.createErrorTaskResult(
-			"direct-message",
-			"Please provide a business idea to generate customer profiles.",
-		)
-		h.sendSuccessResponse(c, "direct-message", result)
+	if h.refusesWrites() {
+		h.sendError(c, "", h.maintenanceError(c.Request.Context(), "direct-message"))
 		return
 	}
-
-	log.Printf("STATE: Calling Gemini client to generate profiles for: %s", businessIdea)
-	// Generate customer profiles
-	ctx := context.Background()
-	profileResp, err := h.geminiClient.GenerateCustomerProfiles(ctx, businessIdea)
-	if err != nil {
-		log.Printf("ERROR: Failed to generate profiles: %v", err)
-		result := h.createErrorTaskResult(
-			"direct-message",
-			fmt.Sprintf("Failed to generate customer profiles: %v", err),
-		)
-		h.sendSuccessResponse(c, "direct-message", result)
+	if rpcErr := h.admitWrite(c, "direct-message"); rpcErr != nil {
+		h.sendError(c, "", rpcErr)
 		return
 	}
 
-	log.Printf("STATE: Profile generation succeeded. Sending StateCompleted TaskResult.")
-	// Create successful task result
-	result := h.createSuccessTaskResult("direct-message", profileResp)
-	h.sendSuccessResponse(c, "direct-message", result)
+	h.processMessage(c, "direct-message", msgParams)
 }
 
 func (h *A2AHandler) handleTask(c *gin.Context, rpcReq JSONRPCRequest) {
-	log.Printf("STATE: HANDLING JSON-RPC TASK")
+	logger.DebugContext(c.Request.Context(), "Handling JSON-RPC task", "id", rpcReq.ID)
 
 	// Parse message parameters
 	paramsJSON, err := json.Marshal(rpcReq.Params)
 	if err != nil {
-		log.Printf("ERROR: Failed to marshal params: %v", err)
-		h.sendErrorResponse(c, rpcReq.ID, "Failed to parse parameters", -32602)
+		logger.WarnContext(c.Request.Context(), "Failed to marshal params", "error", err)
+		h.sendErrorResponse(c, rpcReq.ID, "Failed to parse parameters", CodeInvalidParams)
 		return
 	}
 
 	var msgParams MessageParams
 	if err := json.Unmarshal(paramsJSON, &msgParams); err != nil {
-		log.Printf("ERROR: Failed to unmarshal params: %v", err)
-		log.Printf("Params structure: %+v", rpcReq.Params)
-		h.sendErrorResponse(c, rpcReq.ID, "Invalid parameters", -32602)
+		logger.WarnContext(c.Request.Context(), "Failed to unmarshal params", "error", err)
+		h.sendErrorResponse(c, rpcReq.ID, "Invalid parameters", CodeInvalidParams)
+		return
+	}
+
+	h.processMessage(c, rpcReq.ID, msgParams)
+}
+
+// processMessage generates profiles for a parsed message and writes the task result
+func (h *A2AHandler) processMessage(c *gin.Context, rpcID string, msgParams MessageParams) {
+	key, rpcErr := idempotencyKey(c, msgParams)
+	if rpcErr != nil {
+		h.sendError(c, rpcID, rpcErr)
+		return
+	}
+	var claim *idempotentRequest
+	if key != "" {
+		var answered bool
+		if claim, answered = h.claimIdempotencyKey(c, rpcID, key, msgParams); answered {
+			return
+		}
+	}
+
+	taskID := h.resumeTask(c.Request.Context(), rpcID, &msgParams)
+	if claim != nil {
+		defer claim.finish(taskID)
+	}
+	ensureContextID(&msgParams.Message)
+
+	prepared, early, rpcErr := h.prepareTask(ginTaskRequest(c), taskID, msgParams)
+	if rpcErr != nil {
+		h.sendError(c, rpcID, rpcErr)
+		return
+	}
+	if early != nil {
+		h.finishTask(c, rpcID, msgParams, *early)
+		return
+	}
+	if rpcErr := h.admitTask(c.Request.Context(), c.GetString(ctxKeyMethod), prepared); rpcErr != nil {
+		h.sendError(c, rpcID, rpcErr)
 		return
 	}
 
+	// Blocking requests that would wait for a worker are answered right
+	// away too, when the busy mode says so
+	blocking := msgParams.Configuration.Blocking
+	if (blocking != nil && !*blocking) || (prepared.worker.waiting() && h.config.WhenBusy == BusyAsync) {
+		h.processMessageAsync(c, rpcID, prepared, msgParams)
+		return
+	}
+
+	ctx, _, done := h.startTask(c.Request.Context(), false, taskID, msgParams)
+	defer done()
+	h.finishTask(c, rpcID, msgParams, h.executeTask(ctx, prepared, nil))
+}
+
+// runTask generates profiles for a parsed message and returns the finished
+// task. Generation stops when the client goes away, unless detach is set.
+// Working-state updates are stored as generation advances, so tasks/get
+// and replicas following the task see them, and passed to progress if it
+// is set, possibly from several goroutines.
+func (h *A2AHandler) runTask(c *gin.Context, taskID string, msgParams MessageParams, detach bool, progress func(TaskStatus)) (TaskResult, *rpcError) {
+	prepared, early, rpcErr := h.prepareTask(ginTaskRequest(c), taskID, msgParams)
+	if rpcErr != nil {
+		return TaskResult{}, rpcErr
+	}
+	if early != nil {
+		return *early, nil
+	}
+	if rpcErr := h.admitTask(c.Request.Context(), c.GetString(ctxKeyMethod), prepared); rpcErr != nil {
+		return TaskResult{}, rpcErr
+	}
+
+	ctx, working, done := h.startTask(c.Request.Context(), detach, taskID, msgParams)
+	defer done()
+
+	store := h.storeProgress(working)
+	return h.executeTask(ctx, prepared, func(status TaskStatus) {
+		store(status)
+		if progress != nil {
+			progress(status)
+		}
+	}), nil
+}
+
+// ensureContextID starts a new conversation for a message without a contextId
+func ensureContextID(msg *A2AMessage) {
+	if msg.ContextID == "" {
+		msg.ContextID = uuid.New().String()
+	}
+}
+
+// preparedTask is a validated request ready to generate without the gin
+// context, so it can run after the HTTP request has been answered
+type preparedTask struct {
+	taskID        string
+	contextID     string
+	businessIdea  string
+	opts          profiler.GenerateOptions
+	render        renderOptions
+	wantAvatars   bool
+	avatarBaseURL string
+	latency       *latency.Breakdown
+	warnings      limitWarnings
+	// worker is the task's claim on a worker, set by admitTask
+	worker *workerTicket
+	// caller, tenant and started describe the request for the profile
+	// repository, and response is set once profiles are generated
+	caller   string
+	tenant   string
+	started  time.Time
+	response *models.ProfileResponse
+}
+
+// taskRequest is what preparing a task reads of the HTTP request that
+// carried it, so agentkit skills can prepare tasks without a gin context
+type taskRequest struct {
+	// http supplies the headers and host; its context carries the caller
+	http     *http.Request
+	clientIP string
+	// noteIdea, when set, is given the business idea for the request log
+	noteIdea func(idea string)
+}
+
+// ginTaskRequest describes the request of a gin handler
+func ginTaskRequest(c *gin.Context) taskRequest {
+	return taskRequest{
+		http:     c.Request,
+		clientIP: c.ClientIP(),
+		noteIdea: func(idea string) { c.Set(ctxKeyIdea, idea) },
+	}
+}
+
+// prepareTask validates a message and resolves its options. A non-nil
+// early result means the task ended without needing generation.
+func (h *A2AHandler) prepareTask(req taskRequest, taskID string, msgParams MessageParams) (*preparedTask, *TaskResult, *rpcError) {
+	ctx, span := tracer.Start(req.http.Context(), "a2a.parse", trace.WithAttributes(attribute.String("a2a.task_id", taskID)))
+	defer span.End()
+
+	// The steps of parsing log and call out under the parse span
+	req.http = req.http.WithContext(ctx)
+
+	prepared, early, rpcErr := h.parseTask(req, taskID, msgParams)
+	if rpcErr != nil {
+		span.SetStatus(codes.Error, rpcErr.message)
+	}
+	return prepared, early, rpcErr
+}
+
+// parseTask does the work of prepareTask within its span
+func (h *A2AHandler) parseTask(req taskRequest, taskID string, msgParams MessageParams) (*preparedTask, *TaskResult, *rpcError) {
+	ctx := req.http.Context()
+	contextID := msgParams.Message.ContextID
+	breakdown := latency.New()
+	started := time.Now()
+
+	if rpcErr := validateMessage(msgParams.Message); rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+	if parts, limit := len(msgParams.Message.Parts), h.maxMessageParts(); parts > limit {
+		return nil, nil, invalidParam("message.parts", fmt.Sprintf("message.parts holds %d parts; the limit is %d", parts, limit))
+	}
+	if msgParams.Configuration.HistoryLength < 0 {
+		return nil, nil, invalidParam("configuration.historyLength", "historyLength must not be negative")
+	}
+	if rpcErr := validateCorrelation(msgParams); rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+
+	if pushConfig := msgParams.Configuration.PushNotificationConfig; pushConfig != nil {
+		if rpcErr := h.registerPush(taskID, *pushConfig); rpcErr != nil {
+			return nil, nil, rpcErr
+		}
+	}
+
 	// Extract business idea from user message
 	businessIdea := h.extractBusinessIdea(msgParams.Message)
-	log.Printf("Extracted business idea: '%s'", businessIdea)
-
-	if businessIdea == "" {
-		log.Printf("WARN: No business idea found in message")
-		result := h.createErrorTaskResult(
-			rpcReq.ID,
-			"Please provide a business idea to generate customer profiles.",
-		)
-		h.sendSuccessResponse(c, rpcReq.ID, result)
-		return
+	documents, rpcErr := h.documentText(ctx, msgParams.Message)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+	if documents != "" {
+		businessIdea = strings.TrimSpace(businessIdea + "\n\n" + documents)
+	}
+	if msgParams.resumed != nil {
+		businessIdea = h.clarifiedIdea(*msgParams.resumed, businessIdea)
 	}
+	logger.DebugContext(ctx, "Extracted business idea", "idea", redact.ForLog(businessIdea))
+	if req.noteIdea != nil {
+		req.noteIdea(businessIdea)
+	}
+
+	skill, route, rpcErr := resolveSkill(msgParams.Metadata)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+
+	// Referenced tasks take the place of the conversation's profiles
+	var warnings limitWarnings
+	previous, rpcErr := h.referencedProfiles(ctx, msgParams.Message, &warnings)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+	if previous == nil {
+		previous = h.previousProfiles(ctx, contextID)
+	}
+	stopGuard := breakdown.Track(latency.StageGuard)
+	if skill != agent.SkillJourneyMapping || previous == nil {
+		if question := clarificationFor(businessIdea); question != "" {
+			logger.InfoContext(ctx, "Business idea missing or too vague, asking for input", "task_id", taskID)
+			result := inputRequiredResult(taskID, question)
+			result.ContextID = contextID
+			return nil, &result, nil
+		}
+	}
+	stopGuard()
 
-	log.Printf("STATE: Calling Gemini client to generate profiles for: %s", businessIdea)
+	opts, rpcErr := h.generateOptions(req, msgParams, &warnings)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+	if rpcErr := route(&opts, previous); rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+	applied, rpcErr := applyMessageMetadata(msgParams, &opts)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
 
-	// Generate customer profiles
-	ctx := context.Background()
-	profileResp, err := h.geminiClient.GenerateCustomerProfiles(ctx, businessIdea)
+	loc, err := locale.Resolve(msgParams.Configuration.Language)
 	if err != nil {
-		log.Printf("ERROR: Failed to generate profiles: %v", err)
-		result := h.createErrorTaskResult(
-			rpcReq.ID,
-			fmt.Sprintf("Failed to generate customer profiles: %v", err),
-		)
-		h.sendSuccessResponse(c, rpcReq.ID, result)
-		return
+		return nil, nil, invalidParam("configuration.language", err.Error())
+	}
+	if !loc.IsEnglish() {
+		opts.Language = loc.Name
 	}
 
-	log.Printf("Successfully generated %d profile(s)", len(profileResp.Profiles))
+	modes, rpcErr := parseOutputModes(msgParams.Configuration.AcceptedOutputModes)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
 
-	log.Printf("STATE: Profile generation succeeded. Sending StateCompleted TaskResult.")
-	// Create successful task result
-	result := h.createSuccessTaskResult(rpcReq.ID, profileResp)
+	summaryFirst, rpcErr := metadataBool(msgParams.Metadata, MetadataSummaryFirst)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+	opts.Summary = summaryFirst
+
+	formatName, rpcErr := metadataString(msgParams.Metadata, MetadataFormat)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+	textFlavor, rpcErr := resolveFlavor(formatName)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+
+	wantAvatars, rpcErr := metadataBool(msgParams.Metadata, MetadataAvatars)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+
+	if h.config.PolicyEnforcer != nil {
+		stopGuard := breakdown.Track(latency.StageGuard)
+		err := h.config.PolicyEnforcer.Check(ctx, businessIdea)
+		stopGuard()
+		if err != nil {
+			logger.WarnContext(ctx, "Usage policy refused request", "task_id", taskID, "error", err)
+			result := h.createErrorTaskResult(taskID, policyErrorMessage(err))
+			result.Status.State = StateRejected
+			result.ContextID = contextID
+			return nil, &result, nil
+		}
+	}
+
+	breakdown.Add(latency.StageParse, breakdown.Total()-breakdown.Get(latency.StageGuard))
+	opts.Latency = breakdown
+
+	return &preparedTask{
+		taskID:       taskID,
+		contextID:    contextID,
+		businessIdea: businessIdea,
+		opts:         opts,
+		render: renderOptions{
+			loc:          loc,
+			modes:        modes,
+			summaryFirst: summaryFirst,
+			flavor:       textFlavor,
+			applied:      applied,
+			brand:        h.branding(req.http),
+		},
+		wantAvatars:   wantAvatars,
+		avatarBaseURL: h.avatarBaseURL(req.http),
+		latency:       breakdown,
+		warnings:      warnings,
+		caller:        callerOf(ctx),
+		tenant:        req.http.Header.Get(TenantHeader),
+		started:       started,
+	}, nil, nil
+}
+
+// executeTask generates profiles for a prepared task; ctx comes from startTask
+func (h *A2AHandler) executeTask(ctx context.Context, task *preparedTask, progress func(TaskStatus)) TaskResult {
+	ctx, span := tracer.Start(ctx, "a2a.generate", trace.WithAttributes(attribute.String("a2a.task_id", task.taskID)))
+	defer span.End()
+
+	result := h.generateTask(ctx, task, progress)
+	span.SetAttributes(attribute.String("a2a.task_state", result.Status.State))
+	if result.Status.State == StateFailed {
+		span.SetStatus(codes.Error, "task failed")
+	}
+	result.ContextID = task.contextID
+
+	for _, stage := range latency.Stages {
+		metrics.ObserveStageDuration(stage, task.latency.Get(stage))
+	}
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Metadata[MetadataLatency] = task.latency.Milliseconds()
+	result.Metadata[MetadataPromptVariant] = promptVariant(task)
+	if len(task.warnings) > 0 {
+		result.Metadata[MetadataWarnings] = task.warnings
+	}
+	if task.response != nil && result.Status.State == StateCompleted {
+		h.recordProfiles(ctx, task, result)
+	}
+	return result
+}
+
+func (h *A2AHandler) generateTask(ctx context.Context, task *preparedTask, progress func(TaskStatus)) TaskResult {
+	taskID := task.taskID
+	opts := task.opts
+
+	logger.DebugContext(ctx, "Generating profiles", "task_id", taskID, "idea", redact.ForLog(task.businessIdea))
+
+	if progress != nil {
+		reporter := newProgressReporter(taskID, progress, ProgressInterval)
+		defer reporter.close()
+		opts.Phase = reporter.phase
+		opts.Progress = reporter.personas
+	}
+
+	// A queued task waits for a worker; one that runs out of time or is
+	// canceled while queued ends as if it had during generation
+	var profileResp *models.ProfileResponse
+	queued := task.worker.waiting()
+	err := task.worker.wait(ctx)
+	defer task.worker.release()
+	if err == nil {
+		if queued && progress != nil {
+			progress(TaskStatus{State: StateWorking, Timestamp: Timestamp()})
+		}
+		// Generate customer profiles
+		profileResp, err = h.generator.GenerateCustomerProfiles(ctx, task.businessIdea, opts)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		logger.WarnContext(ctx, "Task ran out of time during generation", "task_id", taskID, "timeout", h.config.GenerationTimeout.String())
+		result := h.createErrorTaskResult(taskID, fmt.Sprintf("Generating the profiles took longer than the %s limit. Please try again, or ask for fewer personas.", h.config.GenerationTimeout))
+		return withTaskMetadata(result, MetadataFailureReason, FailureDeadlineExceeded)
+	}
+	if ctx.Err() != nil {
+		logger.InfoContext(ctx, "Task was canceled during generation", "task_id", taskID)
+		return h.createCanceledTaskResult(taskID)
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to generate profiles", "task_id", taskID, "error", err)
+		result := h.createErrorTaskResult(taskID, generationErrorMessage(err))
+		return withTaskMetadata(result, MetadataFailureReason, generationFailureReason(err))
+	}
+
+	logger.InfoContext(ctx, "Generated profiles", "task_id", taskID, "profiles", len(profileResp.Profiles))
+	task.warnings = append(task.warnings, profileResp.Warnings...)
+	task.response = profileResp
 
-	h.sendSuccessResponse(c, rpcReq.ID, result)
+	stopEnrich := task.latency.Track(latency.StageEnrich)
+
+	if h.config.PersonaLibrary != nil {
+		h.config.PersonaLibrary.Add(profileResp)
+	}
+
+	if !profileResp.Degraded {
+		if err := h.config.ContextStore.Save(task.contextID, profileResp); err != nil {
+			logger.WarnContext(ctx, "Failed to store context", "context_id", task.contextID, "error", err)
+		}
+	}
+
+	var extraArtifacts []Artifact
+	if task.wantAvatars && !profileResp.Degraded {
+		if avatars := h.createAvatarArtifact(ctx, task.avatarBaseURL, profileResp.Profiles); avatars != nil {
+			extraArtifacts = append(extraArtifacts, *avatars)
+		}
+	}
+
+	stopEnrich()
+
+	// Create successful task result
+	defer task.latency.Track(latency.StageFormat)()
+	return h.createSuccessTaskResult(taskID, profileResp, task.render, extraArtifacts...)
 }
 
-// ServeAgentCard serves the agent card using Gin
+// ServeAgentCard serves the agent card using Gin. A card without a URL
+// gets the endpoint of the host the request was sent to.
 func (h *A2AHandler) ServeAgentCard(c *gin.Context) {
-	if err := agent.LoadAgentCard(); err != nil {
-		log.Printf("ERROR: Error loading agent card: %v", err)
+	if h.card.Load() == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Agent card not available"})
 		return
 	}
 
-	log.Printf("Serving agent card")
-	c.Data(http.StatusOK, "application/json", agent.AgentCardData)
+	logger.DebugContext(c.Request.Context(), "Serving agent card")
+	c.JSON(http.StatusOK, h.agentCard(c))
 }
 
 func (h *A2AHandler) extractBusinessIdea(msg A2AMessage) string {
@@ -262,7 +846,7 @@ func (h *A2AHandler) extractBusinessIdea(msg A2AMessage) string {
 			default:
 				dataBytes, err = json.Marshal(v)
 				if err != nil {
-					log.Printf("WARN: Failed to marshal data part: %v", err)
+					logger.Warn("Failed to marshal data part", "error", err)
 					continue
 				}
 			}
@@ -270,7 +854,7 @@ func (h *A2AHandler) extractBusinessIdea(msg A2AMessage) string {
 			// If we have bytes, unmarshal them
 			if len(dataBytes) > 0 {
 				if err := json.Unmarshal(dataBytes, &dataArray); err != nil {
-					log.Printf("WARN: Failed to unmarshal data part: %v", err)
+					logger.Warn("Failed to unmarshal data part", "error", err)
 					continue
 				}
 			}
@@ -304,46 +888,209 @@ func (h *A2AHandler) extractBusinessIdea(msg A2AMessage) string {
 	}
 
 	result := strings.TrimSpace(strings.Join(texts, " "))
-	log.Printf("extractBusinessIdea: '%s'", result)
 	return result
 }
 
-func (h *A2AHandler) createSuccessTaskResult(taskID string, profileResp *models.ProfileResponse) TaskResult {
-	responseText := h.formatProfileResponse(profileResp)
+func (h *A2AHandler) createSuccessTaskResult(taskID string, profileResp *models.ProfileResponse, render renderOptions, extraArtifacts ...Artifact) TaskResult {
+	loc, modes := render.loc, render.modes
+
+	generatedAt := Timestamp()
+	model := h.config.Model
+	if model == "" {
+		model = profiler.ModelName
+	}
+	if profileResp.Degraded {
+		model = ""
+	}
+
+	responseText := h.formatProfileResponse(profileResp, render)
+	if h.config.DisclosureFooter {
+		responseText += disclosureFooter(render.agentName(), model, generatedAt, loc.Headings, render.flavor)
+	}
+	if h.config.Demo != nil {
+		responseText += demoWatermark(render.flavor)
+	}
 
 	artifactID := uuid.New().String()
 	messageID := uuid.New().String()
-	contextID := uuid.New().String()
 
-	return TaskResult{
-		ID:        taskID,
-		ContextID: contextID,
-		Kind:      "task",
+	artifactMetadata := map[string]interface{}{
+		"locale":           loc.Tag,
+		MetadataDisclosure: disclosureMetadata(model, generatedAt),
+	}
+	if profileResp.Reproducible {
+		artifactMetadata["reproducible"] = true
+		artifactMetadata["promptHash"] = profileResp.PromptHash
+	}
+	if profileResp.Degraded {
+		artifactMetadata["degraded"] = true
+		artifactMetadata["fallbackIndustry"] = profileResp.FallbackIndustry
+	}
+	if profileResp.InputCondensed {
+		artifactMetadata["inputCondensed"] = true
+	}
+	if profileResp.Refinement != "" {
+		artifactMetadata["refined"] = true
+	}
+	if len(profileResp.Sources) > 0 {
+		artifactMetadata["sources"] = profileResp.Sources
+	}
+	if profileResp.Readback != nil {
+		artifactMetadata["readback"] = profileResp.Readback
+	}
+	for key, value := range render.applied {
+		artifactMetadata[key] = value
+	}
+	if render.brand != nil {
+		artifactMetadata[MetadataBranding] = brandingMetadata(*render.brand)
+	}
+	if h.config.Demo != nil {
+		artifactMetadata[MetadataDemo] = true
+	}
+
+	var artifacts []Artifact
+	if len(profileResp.Profiles) >= PersonaArtifactThreshold {
+		artifacts = h.createPersonaArtifacts(profileResp, render, artifactMetadata, model, generatedAt)
+	} else {
+		artifacts = []Artifact{
+			{
+				ArtifactID: artifactID,
+				Name:       "Customer Profile Data",
+				Parts:      modes.parts(responseText, profileResp),
+				Metadata:   artifactMetadata,
+			},
+		}
+	}
+
+	if profileResp.InterestGraph != nil {
+		graph := h.createInterestGraphArtifact(profileResp.InterestGraph)
+		graph.Metadata = map[string]interface{}{
+			MetadataDisclosure: disclosureMetadata(model, generatedAt),
+		}
+		artifacts = append(artifacts, graph)
+	}
+
+	structured := h.createStructuredArtifact(profileResp)
+	structured.Metadata = artifactMetadata
+	artifacts = append(artifacts, structured)
+	artifacts = append(artifacts, extraArtifacts...)
+
+	result := TaskResult{
+		ID:   taskID,
+		Kind: "task",
 		Status: TaskStatus{
 			State:     StateCompleted,
-			Timestamp: Timestamp(),
+			Timestamp: generatedAt,
 			Message: &A2AMessage{
 				Kind:      "message",
 				Role:      RoleAgent,
 				MessageID: messageID,
 				TaskID:    taskID,
-				Parts: []MessagePart{
-					TextPart(responseText),
-				},
+				Parts:     modes.parts(responseText, profileResp),
 			},
 		},
-		Artifacts: []Artifact{
-			{
-				ArtifactID: artifactID,
-				Name:       "Customer Profile Data",
-				Parts: []MessagePart{
-					TextPart(responseText),
-				},
-			},
+		Artifacts: artifacts,
+	}
+	h.compressDataParts(&result)
+	return result
+}
+
+// createPersonaArtifacts emits each persona as its own artifact so clients
+// can render and export them separately. Every artifact carries the shared
+// metadata plus its persona number. Journey maps follow in one more artifact.
+func (h *A2AHandler) createPersonaArtifacts(profileResp *models.ProfileResponse, render renderOptions, metadata map[string]interface{}, model, generatedAt string) []Artifact {
+	headings, modes := render.loc.Headings, render.modes
+
+	var artifacts []Artifact
+	if render.summaryFirst && profileResp.Summary != "" {
+		var builder strings.Builder
+		writeSummary(&builder, profileResp, headings, render.flavor)
+		artifacts = append(artifacts, Artifact{
+			ArtifactID: uuid.New().String(),
+			Name:       headings.Summary,
+			Parts: modes.parts(builder.String(), map[string]interface{}{
+				"summary":  profileResp.Summary,
+				"keywords": profileResp.Keywords,
+			}),
+			Metadata: metadata,
+		})
+	}
+
+	for i, profile := range profileResp.Profiles {
+		name := fmt.Sprintf("%s %d", headings.Persona, i+1)
+
+		var builder strings.Builder
+		builder.WriteString(render.flavor.title(render.flavor.escape(fmt.Sprintf("%s: %s", name, profileResp.BusinessIdea))) + "\n\n")
+		writeProfile(&builder, profile, headings, render.flavor)
+		text := builder.String()
+		if h.config.DisclosureFooter {
+			text += disclosureFooter(render.agentName(), model, generatedAt, headings, render.flavor)
+		}
+		if h.config.Demo != nil {
+			text += demoWatermark(render.flavor)
+		}
+
+		personaMetadata := make(map[string]interface{}, len(metadata)+2)
+		for key, value := range metadata {
+			personaMetadata[key] = value
+		}
+		personaMetadata["persona"] = i + 1
+		personaMetadata["personaCount"] = len(profileResp.Profiles)
+
+		artifacts = append(artifacts, Artifact{
+			ArtifactID: uuid.New().String(),
+			Name:       name,
+			Parts:      modes.parts(text, profile),
+			Metadata:   personaMetadata,
+		})
+	}
+
+	if len(profileResp.Journeys) > 0 {
+		var builder strings.Builder
+		for i, journey := range profileResp.Journeys {
+			if i > 0 {
+				builder.WriteString("\n" + render.flavor.rule + "\n\n")
+			}
+			writeJourney(&builder, journey, headings, render.flavor)
+		}
+		artifacts = append(artifacts, Artifact{
+			ArtifactID: uuid.New().String(),
+			Name:       headings.Journey,
+			Parts:      modes.parts(builder.String(), map[string]interface{}{"journeys": profileResp.Journeys}),
+			Metadata:   metadata,
+		})
+	}
+	return artifacts
+}
+
+// createInterestGraphArtifact wraps the persona overlap graph for visualization clients
+func (h *A2AHandler) createInterestGraphArtifact(graph *models.InterestGraph) Artifact {
+	return Artifact{
+		ArtifactID: uuid.New().String(),
+		Name:       "Persona Interest Graph",
+		Parts: []MessagePart{
+			DataPart(map[string]interface{}{
+				"nodes": graph.Nodes,
+				"edges": graph.Edges,
+			}),
 		},
 	}
 }
 
+// StructuredArtifactName names the artifact holding the full ProfileResponse
+const StructuredArtifactName = "Customer Profile JSON"
+
+// createStructuredArtifact wraps the full ProfileResponse in a data part
+// whatever the accepted output modes, so downstream agents never have to
+// parse the rendered text
+func (h *A2AHandler) createStructuredArtifact(profileResp *models.ProfileResponse) Artifact {
+	return Artifact{
+		ArtifactID: uuid.New().String(),
+		Name:       StructuredArtifactName,
+		Parts:      []MessagePart{DataPart(profileResp)},
+	}
+}
+
 func (h *A2AHandler) createErrorTaskResult(taskID string, errorMsg string) TaskResult {
 	return TaskResult{
 		ID:   taskID,
@@ -362,56 +1109,74 @@ func (h *A2AHandler) createErrorTaskResult(taskID string, errorMsg string) TaskR
 	}
 }
 
-func (h *A2AHandler) formatProfileResponse(profileResp *models.ProfileResponse) string {
-	if len(profileResp.Profiles) == 0 {
-		return "No customer profiles generated."
+// workingStatus is an in-progress status carrying a short agent message
+func workingStatus(taskID, text string) TaskStatus {
+	return TaskStatus{
+		State:     StateWorking,
+		Timestamp: Timestamp(),
+		Message: &A2AMessage{
+			Kind:      "message",
+			Role:      RoleAgent,
+			MessageID: uuid.New().String(),
+			TaskID:    taskID,
+			Parts:     []MessagePart{TextPart(text)},
+		},
 	}
+}
 
-	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("# Customer Profile for: %s\n\n", profileResp.BusinessIdea))
-
-	for i, profile := range profileResp.Profiles {
-		if i > 0 {
-			builder.WriteString("\n---\n\n")
-		}
-
-		builder.WriteString("**Demographics:**\n")
-		builder.WriteString(fmt.Sprintf("- Age: %s\n", profile.Age))
-		builder.WriteString(fmt.Sprintf("- Gender: %s\n", profile.Gender))
-		builder.WriteString(fmt.Sprintf("- Location: %s\n", profile.Location))
-		builder.WriteString(fmt.Sprintf("- Occupation: %s\n", profile.Occupation))
-		builder.WriteString(fmt.Sprintf("- Income: %s\n", profile.Income))
-
-		if len(profile.PainPoints) > 0 {
-			builder.WriteString("\n**Pain Points:**\n")
-			for _, pp := range profile.PainPoints {
-				builder.WriteString(fmt.Sprintf("- %s\n", strings.TrimSpace(pp)))
-			}
-		}
-
-		if len(profile.Motivations) > 0 {
-			builder.WriteString("\n**Motivations:**\n")
-			for _, m := range profile.Motivations {
-				builder.WriteString(fmt.Sprintf("- %s\n", strings.TrimSpace(m)))
-			}
-		}
+func (h *A2AHandler) createCanceledTaskResult(taskID string) TaskResult {
+	return TaskResult{
+		ID:   taskID,
+		Kind: "task",
+		Status: TaskStatus{
+			State:     StateCanceled,
+			Timestamp: Timestamp(),
+		},
+	}
+}
 
-		if len(profile.Interests) > 0 {
-			builder.WriteString("\n**Interests:**\n")
-			for _, interest := range profile.Interests {
-				builder.WriteString(fmt.Sprintf("- %s\n", strings.TrimSpace(interest)))
-			}
-		}
+// policyErrorMessage explains a usage policy refusal to the user
+func policyErrorMessage(err error) string {
+	var violation *policy.Violation
+	if errors.As(err, &violation) {
+		return fmt.Sprintf("This request is not allowed by this deployment's usage policy: %s.", violation.Reason)
+	}
+	return "This request could not be checked against this deployment's usage policy. Please try again later."
+}
 
-		if len(profile.PreferredChannels) > 0 {
-			builder.WriteString("\n**Preferred Channels:**\n")
-			for _, channel := range profile.PreferredChannels {
-				builder.WriteString(fmt.Sprintf("- %s\n", strings.TrimSpace(channel)))
-			}
-		}
+// generationFailureReason classifies a generation failure for the task
+// metadata, matching generationErrorMessage
+func generationFailureReason(err error) string {
+	var moderation *profiler.ModerationError
+	switch {
+	case errors.As(err, &moderation):
+		return FailureModeration
+	case errors.Is(err, profiler.ErrTruncatedOutput):
+		return FailureTruncatedOutput
+	case errors.Is(err, profiler.ErrInputTooLarge):
+		return FailureInputTooLarge
+	case errors.Is(err, profiler.ErrInputUnusable):
+		return FailureInputUnusable
+	default:
+		return FailureGeneration
 	}
+}
 
-	return builder.String()
+// generationErrorMessage explains a generation failure to the user
+func generationErrorMessage(err error) string {
+	var moderation *profiler.ModerationError
+	switch {
+	case errors.As(err, &moderation):
+		return fmt.Sprintf("This request was blocked by content safety filters (%s). Please rephrase your business idea.", moderation.Reason)
+	case errors.Is(err, profiler.ErrTruncatedOutput):
+		return "The generated profile was too long to complete. Please try a shorter or more focused business idea."
+	case errors.Is(err, profiler.ErrInputTooLarge):
+		return "Your business idea is too long to process. Please send a summary of a few paragraphs instead of the full plan."
+	case errors.Is(err, profiler.ErrInputUnusable):
+		return "Your message doesn't contain a readable business idea. Please describe your business in words."
+	default:
+		return fmt.Sprintf("Failed to generate customer profiles: %v", err)
+	}
 }
 
 func (h *A2AHandler) sendSuccessResponse(c *gin.Context, id string, result interface{}) {
@@ -421,27 +1186,44 @@ func (h *A2AHandler) sendSuccessResponse(c *gin.Context, id string, result inter
 		Result:  result,
 	}
 
-	log.Printf("=== SENDING RESPONSE (Status 200) ===")
-	responseJSON, _ := json.MarshalIndent(response, "", "  ")
-	log.Printf("%s", string(responseJSON))
-	log.Printf("====================================")
+	if task, ok := result.(TaskResult); ok {
+		accesslog.AddTask(c.Request.Context(), task.ID)
+		c.Set(ctxKeyOutcome, task.Status.State)
+		if notice, deprecated := c.Get(ctxKeyDeprecation); deprecated {
+			response.Result = withTaskMetadata(task, MetadataDeprecation, notice)
+		}
+	}
+
+	if logger.Enabled(c.Request.Context(), slog.LevelDebug) {
+		responseJSON, _ := json.Marshal(response)
+		debugBody(c.Request.Context(), "Sending response", responseJSON, "id", id)
+	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-func (h *A2AHandler) sendErrorResponse(c *gin.Context, id string, message string, code int) {
+func (h *A2AHandler) sendErrorResponse(c *gin.Context, id string, message string, code a2aerrors.Code) {
+	h.sendError(c, id, &rpcError{code: code, message: message})
+}
+
+// sendError writes a JSON-RPC error response carrying the error's data
+func (h *A2AHandler) sendError(c *gin.Context, id string, rpcErr *rpcError) {
 	response := JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
-		Error: map[string]interface{}{
-			"code":    code,
-			"message": message,
-		},
+		Error:   rpcErr.objectFor(c.Request.Context()),
 	}
 
-	log.Printf("=== SENDING RPC ERROR RESPONSE (Status 200) ===")
-	log.Printf("Code: %d, Message: %s", code, message)
-	log.Printf("==============================================")
+	c.Set(ctxKeyOutcome, fmt.Sprintf("error %d", rpcErr.code))
 
-	c.JSON(http.StatusOK, response)
+	logger.InfoContext(c.Request.Context(), "Sending JSON-RPC error", "id", id, "code", int(rpcErr.code), "message", rpcErr.message)
+
+	status := http.StatusOK
+	if rpcErr.status != 0 {
+		status = rpcErr.status
+	}
+	if rpcErr.retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(rpcErr.retryAfter.Seconds()))))
+	}
+	c.JSON(status, response)
 }
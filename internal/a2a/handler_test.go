@@ -0,0 +1,261 @@
+package a2a_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/testutil"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+	"github.com/gin-gonic/gin"
+)
+
+const testIdea = "a subscription box of treats for dogs"
+
+// rpcResponse is a JSON-RPC response with its result left undecoded
+type rpcResponse struct {
+	ID     string           `json:"id"`
+	Result json.RawMessage  `json:"result"`
+	Error  *a2aerrors.Error `json:"error"`
+}
+
+// testServer serves a handler backed by generator the way the server
+// mounts the profiler endpoint
+type testServer struct {
+	t      *testing.T
+	router *gin.Engine
+}
+
+func newTestServer(t *testing.T, generator profiler.ProfileGenerator) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	handler := a2a.NewA2AHandler(generator, a2a.HandlerConfig{})
+	router := gin.New()
+	router.POST("/a2a/profiler", handler.HandleProfiler)
+	return &testServer{t: t, router: router}
+}
+
+// call sends one JSON-RPC request and decodes the response
+func (s *testServer) call(method string, params interface{}) rpcResponse {
+	s.t.Helper()
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": "1", "method": method, "params": params})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/a2a/profiler", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+
+	var resp rpcResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		s.t.Fatalf("%s: decoding response %q: %v", method, recorder.Body.String(), err)
+	}
+	return resp
+}
+
+// task calls method and decodes the task it returns
+func (s *testServer) task(method string, params interface{}) a2a.TaskResult {
+	s.t.Helper()
+	resp := s.call(method, params)
+	if resp.Error != nil {
+		s.t.Fatalf("%s: unexpected error %d: %s", method, resp.Error.Code, resp.Error.Message)
+	}
+	var task a2a.TaskResult
+	if err := json.Unmarshal(resp.Result, &task); err != nil {
+		s.t.Fatalf("%s: decoding task %s: %v", method, resp.Result, err)
+	}
+	return task
+}
+
+func sendParams(idea string, blocking bool) map[string]interface{} {
+	return map[string]interface{}{
+		"message": map[string]interface{}{
+			"kind":      "message",
+			"role":      "user",
+			"messageId": "message-1",
+			"parts":     []map[string]interface{}{{"kind": "text", "text": idea}},
+		},
+		"configuration": map[string]interface{}{"blocking": blocking},
+	}
+}
+
+func TestMessageSendCompletesWithProfiles(t *testing.T) {
+	generator := testutil.NewMockGenerator()
+	server := newTestServer(t, generator)
+
+	task := server.task("message/send", sendParams(testIdea, true))
+
+	if task.Status.State != a2a.StateCompleted {
+		t.Fatalf("state = %s, want %s", task.Status.State, a2a.StateCompleted)
+	}
+	if task.ID == "" || task.ContextID == "" {
+		t.Errorf("task has no ID or context ID: %+v", task)
+	}
+	if len(task.Artifacts) == 0 {
+		t.Error("completed task has no artifacts")
+	}
+	calls := generator.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("generator called %d times, want 1", len(calls))
+	}
+	if calls[0].BusinessIdea != testIdea {
+		t.Errorf("generator got idea %q, want %q", calls[0].BusinessIdea, testIdea)
+	}
+}
+
+func TestMessageSendModelErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator *testutil.MockGenerator
+		wantState string
+		wantMeta  map[string]interface{}
+	}{
+		{
+			name:      "unavailable model fails the task",
+			generator: &testutil.MockGenerator{Err: errors.New("model unavailable")},
+			wantState: a2a.StateFailed,
+			wantMeta:  map[string]interface{}{a2a.MetadataFailureReason: a2a.FailureGeneration},
+		},
+		{
+			name:      "truncated output fails with its reason",
+			generator: &testutil.MockGenerator{Err: profiler.ErrTruncatedOutput},
+			wantState: a2a.StateFailed,
+			wantMeta:  map[string]interface{}{a2a.MetadataFailureReason: a2a.FailureTruncatedOutput},
+		},
+		{
+			name:      "fallback template completes the task",
+			generator: &testutil.MockGenerator{Response: profiler.FallbackProfiles(testIdea)},
+			wantState: a2a.StateCompleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, tt.generator)
+
+			task := server.task("message/send", sendParams(testIdea, true))
+
+			if task.Status.State != tt.wantState {
+				t.Fatalf("state = %s, want %s", task.Status.State, tt.wantState)
+			}
+			for key, want := range tt.wantMeta {
+				if got := task.Metadata[key]; got != want {
+					t.Errorf("metadata %s = %v, want %v", key, got, want)
+				}
+			}
+			if tt.wantState == a2a.StateFailed && task.Status.Message == nil {
+				t.Error("failed task has no status message for the caller")
+			}
+		})
+	}
+}
+
+func TestFallbackTemplateIsMarkedDegraded(t *testing.T) {
+	server := newTestServer(t, &testutil.MockGenerator{Response: profiler.FallbackProfiles(testIdea)})
+
+	task := server.task("message/send", sendParams(testIdea, true))
+
+	degraded := false
+	for _, artifact := range task.Artifacts {
+		if artifact.Metadata["degraded"] == true {
+			degraded = true
+		}
+	}
+	if !degraded {
+		t.Errorf("no artifact of a fallback template is marked degraded: %+v", task.Artifacts)
+	}
+}
+
+func TestTasksGet(t *testing.T) {
+	server := newTestServer(t, testutil.NewMockGenerator())
+	sent := server.task("message/send", sendParams(testIdea, true))
+
+	t.Run("returns a stored task", func(t *testing.T) {
+		got := server.task("tasks/get", map[string]interface{}{"id": sent.ID})
+		if got.ID != sent.ID || got.Status.State != sent.Status.State {
+			t.Errorf("got task %s in %s, want %s in %s", got.ID, got.Status.State, sent.ID, sent.Status.State)
+		}
+		if len(got.History) == 0 {
+			t.Error("stored task has no history")
+		}
+	})
+
+	t.Run("limits history", func(t *testing.T) {
+		got := server.task("tasks/get", map[string]interface{}{"id": sent.ID, "historyLength": 1})
+		if len(got.History) != 1 {
+			t.Errorf("history has %d messages, want 1", len(got.History))
+		}
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		resp := server.call("tasks/get", map[string]interface{}{"id": "no-such-task"})
+		if resp.Error == nil || resp.Error.Code != a2a.CodeTaskNotFound {
+			t.Errorf("error = %+v, want code %d", resp.Error, a2a.CodeTaskNotFound)
+		}
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		resp := server.call("tasks/get", map[string]interface{}{})
+		if resp.Error == nil || resp.Error.Code != a2a.CodeInvalidParams {
+			t.Errorf("error = %+v, want code %d", resp.Error, a2a.CodeInvalidParams)
+		}
+	})
+}
+
+func TestTasksCancel(t *testing.T) {
+	t.Run("cancels a running task", func(t *testing.T) {
+		generator := &testutil.MockGenerator{Block: make(chan struct{})}
+		defer close(generator.Block)
+		server := newTestServer(t, generator)
+
+		working := server.task("message/send", sendParams(testIdea, false))
+		if working.Status.State != a2a.StateWorking && working.Status.State != a2a.StateSubmitted {
+			t.Fatalf("non-blocking send returned a task in %s", working.Status.State)
+		}
+		waitFor(t, func() bool { return len(generator.Calls()) == 1 })
+
+		canceled := server.task("tasks/cancel", map[string]interface{}{"id": working.ID})
+		if canceled.Status.State != a2a.StateCanceled {
+			t.Fatalf("state = %s, want %s", canceled.Status.State, a2a.StateCanceled)
+		}
+		waitFor(t, func() bool {
+			return server.task("tasks/get", map[string]interface{}{"id": working.ID}).Status.State == a2a.StateCanceled
+		})
+	})
+
+	t.Run("refuses a finished task", func(t *testing.T) {
+		server := newTestServer(t, testutil.NewMockGenerator())
+		done := server.task("message/send", sendParams(testIdea, true))
+
+		resp := server.call("tasks/cancel", map[string]interface{}{"id": done.ID})
+		if resp.Error == nil || resp.Error.Code != a2a.CodeTaskNotCancelable {
+			t.Errorf("error = %+v, want code %d", resp.Error, a2a.CodeTaskNotCancelable)
+		}
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		server := newTestServer(t, testutil.NewMockGenerator())
+		resp := server.call("tasks/cancel", map[string]interface{}{"id": "no-such-task"})
+		if resp.Error == nil || resp.Error.Code != a2a.CodeTaskNotFound {
+			t.Errorf("error = %+v, want code %d", resp.Error, a2a.CodeTaskNotFound)
+		}
+	})
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 5s")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
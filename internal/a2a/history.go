@@ -0,0 +1,144 @@
+package a2a
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profilestore"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestlog"
+	"github.com/gin-gonic/gin"
+)
+
+// profileHistory is a page of GET /api/v1/profiles
+type profileHistory struct {
+	Profiles   []profilestore.Record `json:"profiles"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+}
+
+// historyCaller resolves whose profiles a history request may read. With
+// authentication on, callers read only their own, as with tasks/get; with
+// it off, only adminToken may read them, every caller's, optionally
+// filtered by the caller query parameter. It answers refused requests
+// itself.
+func (h *A2AHandler) historyCaller(c *gin.Context, adminToken string) (string, bool) {
+	if h.config.Demo != nil && !h.requiresAuth() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile history is not available in demo mode"})
+		return "", false
+	}
+	if rpcErr := h.authenticate(c); rpcErr != nil {
+		c.JSON(rpcErr.status, gin.H{"error": rpcErr.message})
+		return "", false
+	}
+	if !h.requiresAuth() {
+		// Without callers to tell apart, the history quotes everyone's
+		// ideas, so it answers only to the admin token like /analytics
+		if !requestlog.Authorize(c, adminToken) {
+			return "", false
+		}
+		return c.Query("caller"), true
+	}
+
+	caller := callerName(c)
+	if caller == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required: " + h.credentialHint()})
+		return "", false
+	}
+	if requested := c.Query("caller"); requested != "" && requested != caller {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Callers can only read their own profile history"})
+		return "", false
+	}
+	return caller, true
+}
+
+// ServeProfiles lists stored generations, newest first, without their
+// artifacts. Query parameters: caller and tenant filter exactly; since and
+// until bound the finish time, as RFC 3339 times or dates; limit sets the
+// page size; cursor continues from a previous page's nextCursor. Without
+// authentication, callers must present adminToken as a bearer token.
+func (h *A2AHandler) ServeProfiles(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller, ok := h.historyCaller(c, adminToken)
+		if !ok {
+			return
+		}
+
+		filter := profilestore.Filter{Caller: caller, Tenant: c.Query("tenant"), Cursor: c.Query("cursor")}
+		var err error
+		if filter.Since, err = historyTime(c.Query("since")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since: " + err.Error()})
+			return
+		}
+		if filter.Until, err = historyTime(c.Query("until")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid until: " + err.Error()})
+			return
+		}
+		if limit := c.Query("limit"); limit != "" {
+			filter.Limit, err = strconv.Atoi(limit)
+			if err != nil || filter.Limit < 1 || filter.Limit > profilestore.MaxPageSize {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit: must be between 1 and %d", profilestore.MaxPageSize)})
+				return
+			}
+		}
+
+		page, err := h.config.ProfileRepository.List(c.Request.Context(), filter)
+		if errors.Is(err, profilestore.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		if err != nil {
+			logger.ErrorContext(c.Request.Context(), "Failed to list profiles", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list profiles"})
+			return
+		}
+		if page.Records == nil {
+			page.Records = []profilestore.Record{}
+		}
+		c.JSON(http.StatusOK, profileHistory{Profiles: page.Records, NextCursor: page.Next})
+	}
+}
+
+// ServeProfile returns the stored generation of the :taskId path
+// parameter, with its artifacts. It takes the same credentials as
+// ServeProfiles.
+func (h *A2AHandler) ServeProfile(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller, ok := h.historyCaller(c, adminToken)
+		if !ok {
+			return
+		}
+
+		taskID := c.Param("taskId")
+		record, found, err := h.config.ProfileRepository.Get(c.Request.Context(), taskID)
+		if err != nil {
+			logger.ErrorContext(c.Request.Context(), "Failed to load profiles", "task_id", taskID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load profiles"})
+			return
+		}
+		// Other callers' generations are reported missing, not forbidden, so
+		// task IDs can't be probed
+		if !found || (caller != "" && record.Caller != caller) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No stored profiles for this task"})
+			return
+		}
+		c.JSON(http.StatusOK, record)
+	}
+}
+
+// historyTime parses a since or until bound, either an RFC 3339 time or a
+// date, meaning its start in UTC
+func historyTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, errors.New("expected an RFC 3339 time or a YYYY-MM-DD date")
+	}
+	return t, nil
+}
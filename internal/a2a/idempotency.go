@@ -0,0 +1,173 @@
+package a2a
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader carries a caller-chosen key identifying a message
+// across retries. Retries with the same key get the original task back
+// instead of generating again.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replaying an earlier task
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// MetadataIdempotencyKey is the params metadata key of an idempotency key,
+// for callers that can't set headers or send several keys in a batch
+const MetadataIdempotencyKey = "idempotencyKey"
+
+// DefaultIdempotencyTTL is how long idempotency keys are remembered
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength bounds keys, which are held in memory
+const maxIdempotencyKeyLength = 255
+
+// idempotentRequest is the first request sent with a key. done is closed
+// once it has answered, after which taskID names its task, or is empty if
+// it failed before starting one.
+type idempotentRequest struct {
+	fingerprint [sha256.Size]byte
+	done        chan struct{}
+	taskID      string
+}
+
+// finish records the task the request started and releases the requests
+// waiting on it
+func (r *idempotentRequest) finish(taskID string) {
+	r.taskID = taskID
+	close(r.done)
+}
+
+// idempotencyKeys remembers the request of each key, scoped to its caller
+type idempotencyKeys struct {
+	mu    sync.Mutex
+	store *memoryStore[*idempotentRequest]
+}
+
+func newIdempotencyKeys(capacity int, ttl time.Duration) *idempotencyKeys {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &idempotencyKeys{store: newMemoryStore(capacity, ttl, func(r *idempotentRequest) *idempotentRequest { return r })}
+}
+
+// claim records request as the request of key, unless another already is,
+// in which case it returns that one
+func (k *idempotencyKeys) claim(key string, request *idempotentRequest) (*idempotentRequest, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if earlier, ok := k.store.get(key); ok {
+		return earlier, false
+	}
+	k.store.set(key, request)
+	return request, true
+}
+
+// release forgets key if request is still its request, so the next retry
+// runs again
+func (k *idempotencyKeys) release(key string, request *idempotentRequest) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if current, ok := k.store.get(key); ok && current == request {
+		k.store.delete(key)
+	}
+}
+
+// idempotencyKey returns the message's idempotency key, from its metadata
+// or else the header, scoped to the caller so callers can't read each
+// other's tasks by guessing keys. It returns "" for messages without one.
+func idempotencyKey(c *gin.Context, msgParams MessageParams) (string, *rpcError) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if raw, ok := msgParams.Metadata[MetadataIdempotencyKey]; ok && raw != nil {
+		value, isString := raw.(string)
+		if !isString {
+			return "", invalidParam(MetadataIdempotencyKey, MetadataIdempotencyKey+" must be a string")
+		}
+		key = value
+	}
+	if key == "" {
+		return "", nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return "", invalidParam(MetadataIdempotencyKey, "Idempotency keys must not be longer than 255 characters")
+	}
+
+	scope := callerName(c)
+	if scope == "" {
+		scope = c.GetHeader(TenantHeader)
+	}
+	return scope + "\x00" + key, nil
+}
+
+// requestFingerprint hashes what a message asks for, so a key reused for a
+// different message is caught. Message IDs are left out, since clients may
+// regenerate them on retry.
+func requestFingerprint(msgParams MessageParams) [sha256.Size]byte {
+	metadata := make(map[string]interface{}, len(msgParams.Metadata))
+	for name, value := range msgParams.Metadata {
+		if name != MetadataIdempotencyKey {
+			metadata[name] = value
+		}
+	}
+	encoded, _ := json.Marshal(struct {
+		Parts         []MessagePart          `json:"parts"`
+		TaskID        string                 `json:"taskId"`
+		ContextID     string                 `json:"contextId"`
+		Configuration MessageConfiguration   `json:"configuration"`
+		Metadata      map[string]interface{} `json:"metadata"`
+	}{msgParams.Message.Parts, msgParams.Message.TaskID, msgParams.Message.ContextID, msgParams.Configuration, metadata})
+	return sha256.Sum256(encoded)
+}
+
+// claimIdempotencyKey answers a retry of an earlier request with the task
+// that request started, waiting for it to answer first, and reports
+// whether it answered. Otherwise it claims key and returns the claim,
+// which the caller finishes once it has answered. Tasks that failed or
+// were canceled, or have expired, are not replayed, so their retries run
+// again.
+func (h *A2AHandler) claimIdempotencyKey(c *gin.Context, rpcID, key string, msgParams MessageParams) (*idempotentRequest, bool) {
+	ctx := c.Request.Context()
+	fingerprint := requestFingerprint(msgParams)
+	for {
+		request := &idempotentRequest{fingerprint: fingerprint, done: make(chan struct{})}
+		earlier, claimed := h.idempotency.claim(key, request)
+		if claimed {
+			return request, false
+		}
+		if earlier.fingerprint != fingerprint {
+			h.sendError(c, rpcID, &rpcError{
+				status:  http.StatusUnprocessableEntity,
+				code:    CodeInvalidParams,
+				message: "The idempotency key was already used for a different message",
+			})
+			return nil, true
+		}
+
+		select {
+		case <-earlier.done:
+		case <-ctx.Done():
+			h.sendError(c, rpcID, &rpcError{code: CodeInternalError, message: "Request canceled while waiting for the original request"})
+			return nil, true
+		}
+
+		if earlier.taskID != "" {
+			task, ok, err := h.config.TaskStore.Get(earlier.taskID)
+			if err != nil {
+				logger.WarnContext(ctx, "Failed to load the task of an idempotency key", "task_id", earlier.taskID, "error", err)
+			}
+			if ok && task.Status.State != StateFailed && task.Status.State != StateCanceled {
+				logger.InfoContext(ctx, "Replaying task for a repeated idempotency key", "task_id", task.ID)
+				c.Header(IdempotentReplayedHeader, "true")
+				h.sendSuccessResponse(c, rpcID, limitHistory(task, msgParams.Configuration.HistoryLength))
+				return nil, true
+			}
+		}
+		h.idempotency.release(key, earlier)
+	}
+}
@@ -0,0 +1,86 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/agentkit"
+	"github.com/gin-gonic/gin"
+)
+
+// profilerSkill serves one skill of the agent card through agentkit. The
+// skills share one implementation, which routes on the skillId metadata
+// like the profiler endpoint.
+type profilerSkill struct {
+	h  *A2AHandler
+	id string
+}
+
+// Skills returns the profiler's skills for an agentkit.Server. Profile
+// generation comes first, so it takes messages without a skillId; those
+// still refine a conversation's earlier profiles.
+func (h *A2AHandler) Skills() []agentkit.Skill {
+	ids := []string{agent.SkillProfileGeneration, agent.SkillPersonaRefinement, agent.SkillJourneyMapping}
+	skills := make([]agentkit.Skill, len(ids))
+	for i, id := range ids {
+		skills[i] = profilerSkill{h: h, id: id}
+	}
+	return skills
+}
+
+// GuardKit puts the agentkit endpoint behind the profiler endpoint's
+// guards: the body size and nesting limits, read-only and drain mode, and
+// the demo and rate limits. Mount it after RequireAuth, so limits per API
+// key see the caller.
+func (h *A2AHandler) GuardKit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, rpcErr := h.readBody(c)
+		if rpcErr != nil {
+			h.sendError(c, "", rpcErr)
+			c.Abort()
+			return
+		}
+		// agentkit decodes the body again, and answers malformed requests
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		rpcReq, err := decodeRequest(body)
+		if err != nil {
+			c.Next()
+			return
+		}
+		if rpcErr := h.guardWrite(c, rpcReq.Method, rpcReq.Method); rpcErr != nil {
+			h.sendError(c, rpcReq.ID, rpcErr)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func (s profilerSkill) ID() string {
+	return s.id
+}
+
+func (s profilerSkill) Execute(ctx context.Context, req *agentkit.Request, updates agentkit.Updates) (TaskResult, error) {
+	msgParams := MessageParams{
+		Message:       req.Params.Message,
+		Configuration: req.Params.Configuration,
+		Metadata:      req.Params.Metadata,
+		resumed:       req.Resumed,
+	}
+
+	taskReq := taskRequest{http: req.HTTP.WithContext(ctx), clientIP: req.ClientIP}
+	prepared, early, rpcErr := s.h.prepareTask(taskReq, req.TaskID, msgParams)
+	if rpcErr != nil {
+		return TaskResult{}, rpcErr.objectFor(ctx)
+	}
+	if early != nil {
+		return *early, nil
+	}
+	if rpcErr := s.h.admitTask(ctx, "message/send", prepared); rpcErr != nil {
+		return TaskResult{}, rpcErr.objectFor(ctx)
+	}
+	return s.h.executeTask(ctx, prepared, updates), nil
+}
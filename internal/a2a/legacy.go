@@ -0,0 +1,50 @@
+package a2a
+
+import (
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/google/uuid"
+)
+
+// MetadataUpgraded marks an artifact rebuilt from a legacy task result
+// rather than produced by a generation
+const MetadataUpgraded = "upgraded"
+
+// upgradeLegacyTask adds the structured artifact to a completed task that
+// only has the single combined artifact results were stored as before, so
+// references and analytics read every task's profiles the same way. Tasks
+// already in the current shape are left alone.
+func upgradeLegacyTask(task TaskResult) (TaskResult, bool) {
+	if task.Status.State != StateCompleted {
+		return task, false
+	}
+	if _, ok := structuredProfiles(task); ok {
+		return task, false
+	}
+
+	for _, artifact := range task.Artifacts {
+		for _, part := range artifact.Parts {
+			if part.Kind != "data" {
+				continue
+			}
+			var profiles models.ProfileResponse
+			if err := decodeDataPart(part, &profiles); err != nil || len(profiles.Profiles) == 0 {
+				continue
+			}
+
+			metadata := make(map[string]interface{}, len(artifact.Metadata)+1)
+			for key, value := range artifact.Metadata {
+				metadata[key] = value
+			}
+			metadata[MetadataUpgraded] = true
+
+			task.Artifacts = append(task.Artifacts, Artifact{
+				ArtifactID: uuid.New().String(),
+				Name:       StructuredArtifactName,
+				Parts:      []MessagePart{part},
+				Metadata:   metadata,
+			})
+			return task, true
+		}
+	}
+	return task, false
+}
@@ -0,0 +1,21 @@
+package a2a
+
+// MetadataWarnings is the task metadata key listing limits that were
+// relaxed instead of failing the request, in soft-limit mode
+const MetadataWarnings = "warnings"
+
+// limitWarnings collects the limits a request exceeded under soft limits
+type limitWarnings []string
+
+// exceeded reports a request over a limit on field, where limit reads like
+// "must be between 1 and 5". Strict mode returns the invalid params error;
+// soft mode records a warning saying what was done instead and returns nil,
+// so the caller clamps the value and continues.
+func (h *A2AHandler) exceeded(warnings *limitWarnings, field, limit, instead string) *rpcError {
+	if !h.config.SoftLimits {
+		return invalidParam(field, field+" "+limit)
+	}
+	logger.Warn("Soft limit applied", "field", field, "limit", limit)
+	*warnings = append(*warnings, field+": "+limit+"; "+instead)
+	return nil
+}
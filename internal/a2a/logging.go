@@ -0,0 +1,20 @@
+package a2a
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+)
+
+var logger = logging.For("a2a")
+
+// debugBody logs a request or response body, redacted, at debug level. The
+// body isn't even converted at other levels, since bodies can be large.
+func debugBody(ctx context.Context, msg string, body []byte, args ...interface{}) {
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	logger.DebugContext(ctx, msg, append(args, "body", redact.ForLog(string(body)))...)
+}
@@ -0,0 +1,205 @@
+package a2a
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultStoreTTL is how long the in-memory stores keep an entry after its
+// last write
+const DefaultStoreTTL = 24 * time.Hour
+
+// memoryEntry is a stored value with its expiry; a zero ExpiresAt never expires
+type memoryEntry[V any] struct {
+	Key       string    `json:"key"`
+	Value     V         `json:"value"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+func (e memoryEntry[V]) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+// memoryStore is the bounded TTL map behind the in-memory stores. Once full
+// it drops expired entries first, then the oldest. clone copies values on
+// the way in and out so callers can't mutate stored state.
+type memoryStore[V any] struct {
+	capacity int
+	ttl      time.Duration
+	clone    func(V) V
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry[V]
+	order   []string
+}
+
+func newMemoryStore[V any](capacity int, ttl time.Duration, clone func(V) V) *memoryStore[V] {
+	return &memoryStore[V]{
+		capacity: capacity,
+		ttl:      ttl,
+		clone:    clone,
+		entries:  make(map[string]memoryEntry[V]),
+	}
+}
+
+func (s *memoryStore[V]) set(key string, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := memoryEntry[V]{Key: key, Value: s.clone(value)}
+	if s.ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(s.ttl)
+	}
+	s.insert(entry)
+}
+
+// insert adds or replaces an entry; the caller holds mu
+func (s *memoryStore[V]) insert(entry memoryEntry[V]) {
+	if _, exists := s.entries[entry.Key]; !exists {
+		if len(s.order) >= s.capacity {
+			s.prune(time.Now())
+		}
+		for len(s.order) >= s.capacity {
+			delete(s.entries, s.order[0])
+			s.order = s.order[1:]
+		}
+		s.order = append(s.order, entry.Key)
+	}
+	s.entries[entry.Key] = entry
+}
+
+// prune drops expired entries; the caller holds mu
+func (s *memoryStore[V]) prune(now time.Time) {
+	kept := s.order[:0]
+	for _, key := range s.order {
+		if s.entries[key].expired(now) {
+			delete(s.entries, key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	s.order = kept
+}
+
+func (s *memoryStore[V]) get(key string) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		var zero V
+		return zero, false
+	}
+	return s.clone(entry.Value), true
+}
+
+// update replaces the live values fn changes, keeping their position and
+// expiry, and returns how many it changed
+func (s *memoryStore[V]) update(fn func(V) (V, bool)) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	updated := 0
+	for _, key := range s.order {
+		entry := s.entries[key]
+		if entry.expired(now) {
+			continue
+		}
+		value, changed := fn(s.clone(entry.Value))
+		if !changed {
+			continue
+		}
+		entry.Value = s.clone(value)
+		s.entries[key] = entry
+		updated++
+	}
+	return updated
+}
+
+// snapshot writes the live entries to path as JSON, oldest first. The file
+// is replaced atomically so a crash mid-write keeps the previous snapshot.
+func (s *memoryStore[V]) snapshot(path string) error {
+	s.mu.Lock()
+	s.prune(time.Now())
+	entries := make([]memoryEntry[V], 0, len(s.order))
+	for _, key := range s.order {
+		entries = append(entries, s.entries[key])
+	}
+	data, err := json.Marshal(entries)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return nil
+}
+
+// restore loads a snapshot written by snapshot, skipping entries that
+// expired in the meantime. A missing file is not an error.
+func (s *memoryStore[V]) restore(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var entries []memoryEntry[V]
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return 0, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	restored := 0
+	for _, entry := range entries {
+		if entry.expired(now) {
+			continue
+		}
+		s.insert(entry)
+		restored++
+	}
+	return restored, nil
+}
+
+func (s *memoryStore[V]) delete(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; !ok {
+		return false
+	}
+	delete(s.entries, key)
+	for i, stored := range s.order {
+		if stored == key {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
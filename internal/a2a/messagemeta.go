@@ -0,0 +1,101 @@
+package a2a
+
+import (
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+)
+
+// Keys understood in message.metadata, as opposed to params.metadata
+const (
+	// MessageMetadataBrandVoice is the voice the personas are worded in
+	MessageMetadataBrandVoice = "brandVoice"
+	// MessageMetadataHints is an object of facts about the business, such
+	// as {"industry": "fintech"}
+	MessageMetadataHints = "hints"
+)
+
+// MetadataMessage is the task metadata key echoing the metadata of the
+// message that produced the task
+const MetadataMessage = "messageMetadata"
+
+// messageMetadata returns the metadata of a message. A reply resuming an
+// input-required task without any keeps the task's earlier metadata.
+func messageMetadata(msgParams MessageParams) map[string]interface{} {
+	if len(msgParams.Message.Metadata) > 0 || msgParams.resumed == nil {
+		return msgParams.Message.Metadata
+	}
+	metadata, _ := msgParams.resumed.Metadata[MetadataMessage].(map[string]interface{})
+	return metadata
+}
+
+// applyMessageMetadata passes the brand voice and hints of a message's
+// metadata to the profiler and returns them as applied, for the artifacts
+func applyMessageMetadata(msgParams MessageParams, opts *profiler.GenerateOptions) (map[string]interface{}, *rpcError) {
+	metadata := messageMetadata(msgParams)
+	applied := make(map[string]interface{})
+
+	voice, rpcErr := metadataString(metadata, MessageMetadataBrandVoice)
+	if rpcErr != nil {
+		return nil, messageMetadataError(rpcErr, MessageMetadataBrandVoice)
+	}
+	voice, err := profiler.SanitizePreference(voice)
+	if err != nil {
+		return nil, invalidParam("message.metadata."+MessageMetadataBrandVoice, err.Error())
+	}
+	if voice != "" {
+		opts.BrandVoice = voice
+		applied[MessageMetadataBrandVoice] = voice
+	}
+
+	raw, ok := metadata[MessageMetadataHints]
+	if !ok || raw == nil {
+		return applied, nil
+	}
+	object, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, invalidParam("message.metadata."+MessageMetadataHints, MessageMetadataHints+" must be an object of strings")
+	}
+	hints := make(map[string]string, len(object))
+	for key, value := range object {
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalidParam("message.metadata."+MessageMetadataHints, MessageMetadataHints+" must be an object of strings")
+		}
+		hints[key] = text
+	}
+	hints, err = profiler.SanitizeHints(hints)
+	if err != nil {
+		return nil, invalidParam("message.metadata."+MessageMetadataHints, err.Error())
+	}
+	if len(hints) > 0 {
+		opts.Hints = hints
+		applied[MessageMetadataHints] = hints
+	}
+	return applied, nil
+}
+
+// messageMetadataError points a metadata validation error at message.metadata
+func messageMetadataError(rpcErr *rpcError, key string) *rpcError {
+	rpcErr.data.Field = "message.metadata." + key
+	return rpcErr
+}
+
+// withMessageMetadata echoes the message's metadata in the task's metadata
+func withMessageMetadata(result TaskResult, msgParams MessageParams) TaskResult {
+	metadata := messageMetadata(msgParams)
+	if len(metadata) == 0 {
+		return result
+	}
+	return withTaskMetadata(result, MetadataMessage, metadata)
+}
+
+// withTaskMetadata sets one key of a task's metadata without touching the
+// map the task already holds
+func withTaskMetadata(result TaskResult, key string, value interface{}) TaskResult {
+	metadata := make(map[string]interface{}, len(result.Metadata)+1)
+	for k, v := range result.Metadata {
+		metadata[k] = v
+	}
+	metadata[key] = value
+	result.Metadata = metadata
+	return result
+}
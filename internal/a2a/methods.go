@@ -0,0 +1,79 @@
+package a2a
+
+import (
+	"fmt"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/agentkit"
+	"github.com/gin-gonic/gin"
+)
+
+// MetadataDeprecation is the task metadata key warning that a request was
+// sent with a deprecated method alias
+const MetadataDeprecation = "deprecation"
+
+// ctxKeyDeprecation holds the deprecation notice of a request sent with an
+// alias, for the response
+const ctxKeyDeprecation = "a2a.deprecation"
+
+// methodDeprecation tells a caller which method replaces the alias it used
+type methodDeprecation struct {
+	Method      string `json:"method"`
+	Replacement string `json:"replacement"`
+	Message     string `json:"message"`
+}
+
+// DefaultMethodAliases are the alternative method names accepted unless a
+// deployment overrides or disables them
+var DefaultMethodAliases = map[string]string{
+	"agent/task": "message/send",
+}
+
+// registerMethods builds the handler's method table from its config
+func (h *A2AHandler) registerMethods() {
+	h.methods = agentkit.NewMethods(logger)
+	h.methods.Register("message/send", h.handleTask)
+	h.methods.Register("message/stream", h.handleStream)
+	h.methods.Register("tasks/get", h.handleGetTask)
+	h.methods.Register("tasks/cancel", h.handleCancelTask)
+	h.methods.Register("tasks/resubscribe", h.handleResubscribe)
+	h.methods.Register("tasks/feedback", h.handleFeedback)
+	h.methods.Register("tasks/pushNotificationConfig/set", h.handleSetPushConfig)
+	h.methods.Register("tasks/pushNotificationConfig/get", h.handleGetPushConfig)
+	h.methods.Register("agent/getAuthenticatedExtendedCard", h.handleExtendedCard)
+
+	aliases := h.config.MethodAliases
+	if aliases == nil {
+		aliases = DefaultMethodAliases
+	}
+	for name, method := range aliases {
+		h.methods.Alias(name, method)
+	}
+	for _, name := range h.config.DisabledMethods {
+		h.methods.Disable(name)
+	}
+
+	logger.Info("Enabled JSON-RPC methods", "methods", h.methods.Names())
+}
+
+// deprecatedAlias handles a request sent with an alias of method. Aliases
+// are legacy names: the request is refused when RejectAliases is set, and
+// otherwise answered with a deprecation notice.
+func (h *A2AHandler) deprecatedAlias(c *gin.Context, alias, method string) *rpcError {
+	if h.config.RejectAliases {
+		logger.WarnContext(c.Request.Context(), "Rejected legacy method", "method", alias)
+		return &rpcError{
+			code:    CodeMethodNotFound,
+			message: fmt.Sprintf("Method %s is no longer accepted; use %s", alias, method),
+			data:    ErrorData{Method: alias},
+		}
+	}
+
+	logger.WarnContext(c.Request.Context(), "Deprecated method called", "method", alias, "alias_of", method)
+	c.Header("Deprecation", "true")
+	c.Set(ctxKeyDeprecation, methodDeprecation{
+		Method:      alias,
+		Replacement: method,
+		Message:     fmt.Sprintf("%s is deprecated; use %s", alias, method),
+	})
+	return nil
+}
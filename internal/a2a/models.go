@@ -1,103 +1,104 @@
 package a2a
 
 import (
-	"encoding/json"
-	"time"
+	a2atypes "github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
 )
 
-// JSON-RPC types
-type JSONRPCRequest struct {
-	JSONRPC string      `json:"jsonrpc"`
-	ID      string      `json:"id"`
-	Method  string      `json:"method"`
-	Params  interface{} `json:"params"`
-}
-
-type JSONRPCResponse struct {
-	JSONRPC string      `json:"jsonrpc"`
-	ID      string      `json:"id"`
-	Result  interface{} `json:"result,omitempty"`
-	Error   interface{} `json:"error,omitempty"`
-}
+// The envelope types live in pkg/a2a so other agents can import them
+type (
+	JSONRPCRequest                 = a2atypes.JSONRPCRequest
+	JSONRPCResponse                = a2atypes.JSONRPCResponse
+	JSONRPCError                   = a2atypes.JSONRPCError
+	ErrorData                      = a2atypes.ErrorData
+	A2AMessage                     = a2atypes.A2AMessage
+	MessagePart                    = a2atypes.MessagePart
+	FileContent                    = a2atypes.FileContent
+	MessageConfiguration           = a2atypes.MessageConfiguration
+	PushNotificationConfig         = a2atypes.PushNotificationConfig
+	PushNotificationAuthentication = a2atypes.PushNotificationAuthentication
+	TaskPushNotificationConfig     = a2atypes.TaskPushNotificationConfig
+	TaskQueryParams                = a2atypes.TaskQueryParams
+	TaskIDParams                   = a2atypes.TaskIDParams
+	TaskFeedbackParams             = a2atypes.TaskFeedbackParams
+	Feedback                       = a2atypes.Feedback
+	TaskResult                     = a2atypes.TaskResult
+	TaskStatusUpdateEvent          = a2atypes.TaskStatusUpdateEvent
+	TaskArtifactUpdateEvent        = a2atypes.TaskArtifactUpdateEvent
+	TaskStatus                     = a2atypes.TaskStatus
+	Artifact                       = a2atypes.Artifact
+)
 
-// Message types
+// MessageParams are the params of message/send and message/stream. It
+// mirrors pkg/a2a.MessageParams with the server's own state added.
 type MessageParams struct {
-	Message       A2AMessage           `json:"message"`
-	Configuration MessageConfiguration `json:"configuration"`
-}
+	Message       A2AMessage             `json:"message"`
+	Configuration MessageConfiguration   `json:"configuration"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 
-type A2AMessage struct {
-	Kind      string        `json:"kind"`
-	Role      string        `json:"role"`
-	Parts     []MessagePart `json:"parts"`
-	MessageID string        `json:"messageId,omitempty"`
-	TaskID    string        `json:"taskId,omitempty"`
+	// resumed is the input-required task this message answers, if any
+	resumed *TaskResult
 }
 
-type MessagePart struct {
-	Kind string      `json:"kind"`
-	Text interface{} `json:"text,omitempty"`
-	Data interface{} `json:"data,omitempty"`
-}
+// Stream event kinds
+const (
+	KindStatusUpdate   = a2atypes.KindStatusUpdate
+	KindArtifactUpdate = a2atypes.KindArtifactUpdate
+)
 
-type MessageConfiguration struct {
-	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"`
-	HistoryLength       int      `json:"historyLength,omitempty"`
-	Blocking            bool     `json:"blocking,omitempty"`
-}
+// Task states
+const (
+	StateSubmitted     = a2atypes.StateSubmitted
+	StateWorking       = a2atypes.StateWorking
+	StateInputRequired = a2atypes.StateInputRequired
+	StateCompleted     = a2atypes.StateCompleted
+	StateFailed        = a2atypes.StateFailed
+	StateCanceled      = a2atypes.StateCanceled
+	StateRejected      = a2atypes.StateRejected
+)
 
-// Task types
-type TaskResult struct {
-	ID        string       `json:"id"`
-	ContextID string       `json:"contextId"`
-	Status    TaskStatus   `json:"status"`
-	Artifacts []Artifact   `json:"artifacts,omitempty"`
-	History   []A2AMessage `json:"history,omitempty"`
-	Kind      string       `json:"kind"`
-}
+// JSON-RPC error codes, defined in pkg/a2aerrors
+const (
+	CodeParseError                   = a2aerrors.CodeParseError
+	CodeInvalidRequest               = a2aerrors.CodeInvalidRequest
+	CodeMethodNotFound               = a2aerrors.CodeMethodNotFound
+	CodeInvalidParams                = a2aerrors.CodeInvalidParams
+	CodeInternalError                = a2aerrors.CodeInternalError
+	CodeTaskNotFound                 = a2aerrors.CodeTaskNotFound
+	CodeTaskNotCancelable            = a2aerrors.CodeTaskNotCancelable
+	CodePushNotificationNotSupported = a2aerrors.CodePushNotificationNotSupported
+	CodeUnsupportedOperation         = a2aerrors.CodeUnsupportedOperation
+	CodeContentTypeNotSupported      = a2aerrors.CodeContentTypeNotSupported
+	CodeInvalidAgentResponse         = a2aerrors.CodeInvalidAgentResponse
+	CodeExtendedCardNotConfigured    = a2aerrors.CodeExtendedCardNotConfigured
+	CodeUnauthorized                 = a2aerrors.CodeUnauthorized
+	CodeMaintenance                  = a2aerrors.CodeMaintenance
+	CodeRateLimited                  = a2aerrors.CodeRateLimited
+)
 
-type TaskStatus struct {
-	State     string      `json:"state"`
-	Timestamp string      `json:"timestamp"`
-	Message   *A2AMessage `json:"message,omitempty"`
-}
+// Message roles
+const (
+	RoleUser  = a2atypes.RoleUser
+	RoleAgent = a2atypes.RoleAgent
+)
 
-type Artifact struct {
-	ArtifactID string        `json:"artifactId"`
-	Name       string        `json:"name"`
-	Parts      []MessagePart `json:"parts"`
+func TextPart(text string) MessagePart {
+	return a2atypes.TextPart(text)
 }
 
-// Helper functions
-func TextPart(text string) MessagePart {
-	return MessagePart{
-		Kind: "text",
-		Text: &text,
-	}
+func DataPart(data interface{}) MessagePart {
+	return a2atypes.DataPart(data)
 }
 
-func DataPart(data map[string]interface{}) MessagePart {
-	dataBytes, _ := json.Marshal(data)
-	return MessagePart{
-		Kind: "text",
-		Text: string(dataBytes),
-	}
+func FilePart(name, mimeType, uri string) MessagePart {
+	return a2atypes.FilePart(name, mimeType, uri)
 }
 
 func Timestamp() string {
-	return time.Now().UTC().Format(time.RFC3339)
+	return a2atypes.Timestamp()
 }
 
-// Task states
-const (
-	StateWorking       = "working"
-	StateInputRequired = "input-required"
-	StateCompleted     = "completed"
-	StateFailed        = "failed"
-)
-
-// Message roles
-const (
-	RoleUser  = "user"
-	RoleAgent = "agent"
-)
+// isTerminalState reports whether a task in state can no longer change
+func isTerminalState(state string) bool {
+	return a2atypes.IsTerminalState(state)
+}
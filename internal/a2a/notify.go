@@ -0,0 +1,71 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// isNotification reports whether a JSON-RPC request object has no "id"
+// member. Such a request is a notification and gets no response. Bodies
+// without a "jsonrpc" member are direct messages, not notifications.
+func isNotification(request []byte) bool {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(request, &envelope); err != nil {
+		return false
+	}
+	_, hasVersion := envelope["jsonrpc"]
+	_, hasID := envelope["id"]
+	return hasVersion && !hasID
+}
+
+// handleNotification answers a notification with 204 No Content and runs it
+// in the background
+func (h *A2AHandler) handleNotification(c *gin.Context, request []byte) {
+	c.Set(ctxKeyMethod, "notification")
+	h.startNotification(c, request)
+	c.Set(ctxKeyOutcome, "notification accepted")
+	c.Status(http.StatusNoContent)
+}
+
+// startNotification runs a notification in the background under a generated
+// request ID, which also names its task. The result is only delivered to a
+// push notification webhook registered by the request itself.
+func (h *A2AHandler) startNotification(c *gin.Context, request []byte) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(request, &envelope); err != nil {
+		return
+	}
+
+	var method string
+	_ = json.Unmarshal(envelope["method"], &method)
+	if resolved, _, _ := h.methods.Resolve(method); resolved == "message/stream" || resolved == "tasks/resubscribe" {
+		logger.WarnContext(c.Request.Context(), "Ignoring notification; streaming methods need a request ID", "method", method)
+		return
+	}
+
+	id := uuid.New().String()
+	envelope["id"], _ = json.Marshal(id)
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return
+	}
+
+	// The notification outlives the HTTP request that carried it
+	req := c.Request.Clone(context.WithoutCancel(c.Request.Context()))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	logger.InfoContext(c.Request.Context(), "Running notification", "method", method, "id", id)
+	// gin reuses c once the handler returns, so the goroutine only touches
+	// the cloned request
+	go func() {
+		recorder := h.serveInternal(req)
+		logger.InfoContext(req.Context(), "Notification finished", "id", id, "status", recorder.Code)
+	}()
+}
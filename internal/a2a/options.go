@@ -0,0 +1,165 @@
+package a2a
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+)
+
+// Metadata keys understood on message/send params
+const (
+	MetadataPromptOverride = "promptOverride"
+	MetadataNoCache        = "noCache"
+	MetadataReproducible   = "reproducible"
+	MetadataPersonaCount   = "personaCount"
+	MetadataGrounded       = "grounded"
+	MetadataAvatars        = "avatars"
+	MetadataSummaryFirst   = "summaryFirst"
+	MetadataFormat         = "format"
+	MetadataReadback       = "readback"
+	MetadataSkillID        = "skillId"
+)
+
+// Request headers understood by the A2A endpoint
+const (
+	// PromptOverrideHeader carries the key that unlocks prompt overrides
+	PromptOverrideHeader = "X-Prompt-Override-Key"
+	// TenantHeader identifies the calling tenant for usage accounting
+	TenantHeader = "X-Tenant-ID"
+)
+
+// generateOptions builds profiler options from request params and headers
+func (h *A2AHandler) generateOptions(req taskRequest, msgParams MessageParams, warnings *limitWarnings) (profiler.GenerateOptions, *rpcError) {
+	opts := profiler.GenerateOptions{
+		Tenant:     req.http.Header.Get(TenantHeader),
+		SoftLimits: h.config.SoftLimits,
+	}
+
+	override, rpcErr := h.resolvePromptOverride(req, msgParams)
+	if rpcErr != nil {
+		return opts, rpcErr
+	}
+	opts.PromptOverride = override
+
+	noCache, rpcErr := metadataBool(msgParams.Metadata, MetadataNoCache)
+	if rpcErr != nil {
+		return opts, rpcErr
+	}
+	opts.NoCache = noCache
+
+	reproducible, rpcErr := metadataBool(msgParams.Metadata, MetadataReproducible)
+	if rpcErr != nil {
+		return opts, rpcErr
+	}
+	opts.Reproducible = reproducible
+
+	personaCount, rpcErr := metadataInt(msgParams.Metadata, MetadataPersonaCount)
+	if rpcErr != nil {
+		return opts, rpcErr
+	}
+	if personaCount < 0 || personaCount > profiler.MaxPersonaCount {
+		clamped := min(max(personaCount, 1), profiler.MaxPersonaCount)
+		limit := fmt.Sprintf("must be between 1 and %d", profiler.MaxPersonaCount)
+		if rpcErr := h.exceeded(warnings, MetadataPersonaCount, limit, fmt.Sprintf("generating %d", clamped)); rpcErr != nil {
+			return opts, rpcErr
+		}
+		personaCount = clamped
+	}
+	opts.PersonaCount = personaCount
+
+	grounded, rpcErr := metadataBool(msgParams.Metadata, MetadataGrounded)
+	if rpcErr != nil {
+		return opts, rpcErr
+	}
+	opts.Grounded = grounded
+
+	readback, rpcErr := metadataBool(msgParams.Metadata, MetadataReadback)
+	if rpcErr != nil {
+		return opts, rpcErr
+	}
+	opts.Readback = readback
+
+	h.applyPreferences(&opts)
+	h.limitDemo(&opts, warnings)
+
+	return opts, nil
+}
+
+// metadataInt reads an optional whole number from request metadata
+func metadataInt(metadata map[string]interface{}, key string) (int, *rpcError) {
+	raw, ok := metadata[key]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+
+	value, ok := raw.(float64)
+	if !ok || value != math.Trunc(value) {
+		return 0, invalidParam(key, key+" must be an integer")
+	}
+	return int(value), nil
+}
+
+// metadataString reads an optional string from request metadata
+func metadataString(metadata map[string]interface{}, key string) (string, *rpcError) {
+	raw, ok := metadata[key]
+	if !ok || raw == nil {
+		return "", nil
+	}
+
+	value, ok := raw.(string)
+	if !ok {
+		return "", invalidParam(key, key+" must be a string")
+	}
+	return value, nil
+}
+
+// metadataBool reads an optional boolean flag from request metadata
+func metadataBool(metadata map[string]interface{}, key string) (bool, *rpcError) {
+	raw, ok := metadata[key]
+	if !ok || raw == nil {
+		return false, nil
+	}
+
+	value, ok := raw.(bool)
+	if !ok {
+		return false, invalidParam(key, key+" must be a boolean")
+	}
+	return value, nil
+}
+
+// resolvePromptOverride returns the sanitized prompt override, if the caller
+// supplied one and is allowed to use it
+func (h *A2AHandler) resolvePromptOverride(req taskRequest, msgParams MessageParams) (string, *rpcError) {
+	raw, ok := msgParams.Metadata[MetadataPromptOverride]
+	if !ok || raw == nil {
+		return "", nil
+	}
+
+	override, ok := raw.(string)
+	if !ok {
+		return "", invalidParam(MetadataPromptOverride, "promptOverride must be a string")
+	}
+
+	key := req.http.Header.Get(PromptOverrideHeader)
+	if h.config.PromptOverrideKey == "" ||
+		subtle.ConstantTimeCompare([]byte(key), []byte(h.config.PromptOverrideKey)) != 1 {
+		logger.WarnContext(req.http.Context(), "Rejected prompt override: missing or invalid "+PromptOverrideHeader, "client_ip", req.clientIP)
+		return "", &rpcError{code: CodeUnauthorized, message: "Prompt override not permitted"}
+	}
+
+	sanitized, err := profiler.SanitizePromptOverride(override)
+	if err != nil {
+		logger.WarnContext(req.http.Context(), "Rejected prompt override", "client_ip", req.clientIP, "error", err)
+		return "", invalidParam(MetadataPromptOverride, err.Error())
+	}
+
+	if sanitized != "" {
+		logger.InfoContext(req.http.Context(), "Prompt override accepted", "audit", true, "client_ip", req.clientIP,
+			"chars", len(sanitized), "override", redact.ForLog(sanitized))
+	}
+
+	return sanitized, nil
+}
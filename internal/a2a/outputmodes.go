@@ -0,0 +1,68 @@
+package a2a
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/locale"
+)
+
+// outputModes is which representations of the profiles a client accepts
+type outputModes struct {
+	text bool
+	data bool
+}
+
+// parseOutputModes reads acceptedOutputModes. An empty list accepts text
+// only, matching clients written before data parts were supported.
+func parseOutputModes(accepted []string) (outputModes, *rpcError) {
+	if len(accepted) == 0 {
+		return outputModes{text: true}, nil
+	}
+
+	var modes outputModes
+	for _, mode := range accepted {
+		switch strings.ToLower(strings.TrimSpace(mode)) {
+		case "text", "text/plain", "text/markdown":
+			modes.text = true
+		case "data", "application/json":
+			modes.data = true
+		}
+	}
+	if !modes.text && !modes.data {
+		return modes, &rpcError{
+			code:    CodeContentTypeNotSupported,
+			message: fmt.Sprintf("None of the accepted output modes are supported: %s (use text or data)", strings.Join(accepted, ", ")),
+			data:    ErrorData{Field: "configuration.acceptedOutputModes"},
+		}
+	}
+	return modes, nil
+}
+
+// parts builds the message parts for the accepted modes
+func (m outputModes) parts(text string, data interface{}) []MessagePart {
+	var parts []MessagePart
+	if m.text {
+		parts = append(parts, TextPart(text))
+	}
+	if m.data {
+		parts = append(parts, DataPart(data))
+	}
+	return parts
+}
+
+// renderOptions controls how generated profiles are presented
+type renderOptions struct {
+	loc   locale.Locale
+	modes outputModes
+	// summaryFirst puts the market summary and keywords before the personas
+	summaryFirst bool
+	// flavor is the text format of text parts
+	flavor flavor
+	// applied is the message metadata that steered generation, echoed in
+	// the artifact metadata
+	applied map[string]interface{}
+	// brand is the calling tenant's branding, if it has one
+	brand *agent.Branding
+}
@@ -0,0 +1,39 @@
+package a2a
+
+import (
+	"context"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/auth"
+)
+
+// MetadataCaller is the task metadata key naming the caller that created
+// the task, as method:name. Tasks of anonymous callers don't carry it.
+const MetadataCaller = "caller"
+
+// callerOf names the authenticated caller of ctx as method:name, or ""
+// for anonymous callers
+func callerOf(ctx context.Context) string {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return identity.Method + ":" + identity.Name
+}
+
+// withCaller records the caller of ctx as the task's owner
+func withCaller(result TaskResult, ctx context.Context) TaskResult {
+	caller := callerOf(ctx)
+	if caller == "" {
+		return result
+	}
+	return withTaskMetadata(result, MetadataCaller, caller)
+}
+
+// ownsTask tells whether the caller of ctx may read or change task. Only
+// the caller that created a task may; tasks created anonymously, which
+// includes every task while authentication is off, are open to anyone.
+// Callers are told other callers' tasks don't exist.
+func ownsTask(ctx context.Context, task TaskResult) bool {
+	owner, _ := task.Metadata[MetadataCaller].(string)
+	return owner == "" || owner == callerOf(ctx)
+}
@@ -0,0 +1,208 @@
+package a2a
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestlog"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultPreferenceStoreCapacity bounds how many tenants the in-memory
+// preference store keeps
+const DefaultPreferenceStoreCapacity = 1000
+
+// Preferences are a tenant's defaults, applied to every generation for the
+// tenant named in the X-Tenant-ID header
+type Preferences struct {
+	// Tone is the voice persona values are written in, e.g. "formal"
+	Tone string `json:"tone,omitempty"`
+	// Region is the market the personas live in, e.g. "East Africa"
+	Region string `json:"region,omitempty"`
+	// PersonaCount is used when a request doesn't set personaCount
+	PersonaCount int `json:"personaCount,omitempty"`
+	// CustomFields are extra values added to every persona, e.g. "budget"
+	CustomFields []string `json:"customFields,omitempty"`
+	UpdatedAt    string   `json:"updatedAt,omitempty"`
+}
+
+// normalize cleans and validates preferences submitted by a tenant. With
+// soft limits, values over a limit are clamped and reported as warnings
+// instead of failing.
+func (p *Preferences) normalize(soft bool) ([]string, error) {
+	var warnings []string
+	for _, pref := range []struct {
+		name  string
+		value *string
+	}{{"tone", &p.Tone}, {"region", &p.Region}} {
+		if runes := []rune(*pref.value); soft && len(runes) > profiler.MaxPreferenceLength {
+			*pref.value = string(runes[:profiler.MaxPreferenceLength])
+			warnings = append(warnings, fmt.Sprintf("%s: exceeds %d characters; truncated", pref.name, profiler.MaxPreferenceLength))
+		}
+		cleaned, err := profiler.SanitizePreference(*pref.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pref.name, err)
+		}
+		*pref.value = cleaned
+	}
+
+	if p.PersonaCount < 0 || p.PersonaCount > profiler.MaxPersonaCount {
+		if !soft {
+			return nil, fmt.Errorf("personaCount must be between 1 and %d", profiler.MaxPersonaCount)
+		}
+		p.PersonaCount = min(max(p.PersonaCount, 1), profiler.MaxPersonaCount)
+		warnings = append(warnings, fmt.Sprintf("personaCount: must be between 1 and %d; using %d", profiler.MaxPersonaCount, p.PersonaCount))
+	}
+
+	if soft && len(p.CustomFields) > profiler.MaxCustomFields {
+		warnings = append(warnings, fmt.Sprintf("customFields: at most %d are allowed; keeping the first %d", profiler.MaxCustomFields, profiler.MaxCustomFields))
+		p.CustomFields = p.CustomFields[:profiler.MaxCustomFields]
+	}
+	if err := profiler.ValidateCustomFields(p.CustomFields); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// PreferenceStore keeps each tenant's preferences
+type PreferenceStore interface {
+	// Save replaces the tenant's preferences
+	Save(tenant string, prefs Preferences) error
+	// Get returns the tenant's preferences; ok is false if none are set
+	Get(tenant string) (prefs Preferences, ok bool, err error)
+	// Delete clears the tenant's preferences; ok is false if none were set
+	Delete(tenant string) (ok bool, err error)
+}
+
+// MemoryPreferenceStore is a bounded in-memory PreferenceStore. Preferences
+// never expire, but the least recently saved tenant is forgotten once full.
+// They are lost on restart unless snapshots are written with Snapshot.
+type MemoryPreferenceStore struct {
+	store *memoryStore[Preferences]
+}
+
+// NewMemoryPreferenceStore creates a store holding up to capacity tenants
+func NewMemoryPreferenceStore(capacity int) *MemoryPreferenceStore {
+	if capacity <= 0 {
+		capacity = DefaultPreferenceStoreCapacity
+	}
+	return &MemoryPreferenceStore{store: newMemoryStore(capacity, 0, copyPreferences)}
+}
+
+func (s *MemoryPreferenceStore) Save(tenant string, prefs Preferences) error {
+	s.store.set(tenant, prefs)
+	return nil
+}
+
+func (s *MemoryPreferenceStore) Get(tenant string) (Preferences, bool, error) {
+	prefs, ok := s.store.get(tenant)
+	return prefs, ok, nil
+}
+
+func (s *MemoryPreferenceStore) Delete(tenant string) (bool, error) {
+	return s.store.delete(tenant), nil
+}
+
+// Snapshot writes the stored preferences to path
+func (s *MemoryPreferenceStore) Snapshot(path string) error {
+	return s.store.snapshot(path)
+}
+
+// Restore loads preferences from a snapshot written by Snapshot and returns
+// how many tenants were restored. A missing file restores nothing.
+func (s *MemoryPreferenceStore) Restore(path string) (int, error) {
+	return s.store.restore(path)
+}
+
+func copyPreferences(prefs Preferences) Preferences {
+	prefs.CustomFields = append([]string(nil), prefs.CustomFields...)
+	return prefs
+}
+
+// applyPreferences fills in options the request left unset from the
+// tenant's preferences. A failing store is logged and skipped.
+func (h *A2AHandler) applyPreferences(opts *profiler.GenerateOptions) {
+	if opts.Tenant == "" {
+		return
+	}
+	prefs, ok, err := h.config.PreferenceStore.Get(opts.Tenant)
+	if err != nil {
+		logger.Warn("Failed to load tenant preferences", "tenant", opts.Tenant, "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	opts.Tone = prefs.Tone
+	opts.Region = prefs.Region
+	opts.CustomFields = prefs.CustomFields
+	if opts.PersonaCount == 0 {
+		opts.PersonaCount = prefs.PersonaCount
+	}
+}
+
+// ServePreferences reads (GET), replaces (PUT) or clears (DELETE) the
+// preferences of the tenant in the :tenant path parameter. Callers must
+// present token as a bearer token; an empty token disables the endpoint.
+func (h *A2AHandler) ServePreferences(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requestlog.Authorize(c, token) {
+			return
+		}
+		tenant := c.Param("tenant")
+
+		switch c.Request.Method {
+		case http.MethodGet:
+			prefs, ok, err := h.config.PreferenceStore.Get(tenant)
+			if err != nil {
+				logger.ErrorContext(c.Request.Context(), "Failed to load tenant preferences", "tenant", tenant, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load preferences"})
+				return
+			}
+			if !ok {
+				c.JSON(http.StatusNotFound, gin.H{"error": "No preferences set for this tenant"})
+				return
+			}
+			c.JSON(http.StatusOK, prefs)
+
+		case http.MethodPut:
+			var prefs Preferences
+			if err := c.ShouldBindJSON(&prefs); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preferences: " + err.Error()})
+				return
+			}
+			warnings, err := prefs.normalize(h.config.SoftLimits)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preferences: " + err.Error()})
+				return
+			}
+			prefs.UpdatedAt = Timestamp()
+			if err := h.config.PreferenceStore.Save(tenant, prefs); err != nil {
+				logger.ErrorContext(c.Request.Context(), "Failed to save tenant preferences", "tenant", tenant, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences"})
+				return
+			}
+			logger.InfoContext(c.Request.Context(), "Tenant preferences updated", "audit", true, "tenant", tenant, "client_ip", c.ClientIP())
+			c.JSON(http.StatusOK, struct {
+				Preferences
+				Warnings []string `json:"warnings,omitempty"`
+			}{prefs, warnings})
+
+		case http.MethodDelete:
+			ok, err := h.config.PreferenceStore.Delete(tenant)
+			if err != nil {
+				logger.ErrorContext(c.Request.Context(), "Failed to delete tenant preferences", "tenant", tenant, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete preferences"})
+				return
+			}
+			if !ok {
+				c.JSON(http.StatusNotFound, gin.H{"error": "No preferences set for this tenant"})
+				return
+			}
+			logger.InfoContext(c.Request.Context(), "Tenant preferences cleared", "audit", true, "tenant", tenant, "client_ip", c.ClientIP())
+			c.Status(http.StatusNoContent)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profilestore"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileSaveTimeout bounds saving a generation to the profile repository
+const ProfileSaveTimeout = 5 * time.Second
+
+// callerName names the authenticated caller as method:name, or "" for
+// anonymous callers
+func callerName(c *gin.Context) string {
+	return callerOf(c.Request.Context())
+}
+
+// recordProfiles saves a completed task's profiles to the profile
+// repository, redacted as anything else retained. The save outlives a
+// client that went away, and a failed save is only logged: the caller
+// still gets the profiles. Demo mode keeps nothing.
+func (h *A2AHandler) recordProfiles(ctx context.Context, task *preparedTask, result TaskResult) {
+	if h.config.ProfileRepository == nil || h.config.Demo != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ProfileSaveTimeout)
+	defer cancel()
+
+	response, err := redactResponse(*task.response)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to redact profiles; not saving them", "task_id", task.taskID, "error", err)
+		return
+	}
+	record := profilestore.Record{
+		TaskID:       task.taskID,
+		ContextID:    task.contextID,
+		BusinessIdea: redact.ForStore(task.response.BusinessIdea),
+		Caller:       task.caller,
+		Tenant:       task.tenant,
+		Response:     response,
+		Artifacts:    redactArtifacts(result.Artifacts),
+		Latency:      task.latency.Milliseconds(),
+		StartedAt:    task.started,
+		FinishedAt:   time.Now(),
+	}
+	if err := h.config.ProfileRepository.Save(ctx, record); err != nil {
+		logger.WarnContext(ctx, "Failed to save profiles", "task_id", task.taskID, "error", err)
+	}
+}
+
+// redactResponse applies the store redaction policy to every text of a
+// response: the idea, and whatever of it the model echoed in the personas
+func redactResponse(response models.ProfileResponse) (models.ProfileResponse, error) {
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return models.ProfileResponse{}, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return models.ProfileResponse{}, err
+	}
+	if raw, err = json.Marshal(redactValue(value)); err != nil {
+		return models.ProfileResponse{}, err
+	}
+	var redacted models.ProfileResponse
+	if err := json.Unmarshal(raw, &redacted); err != nil {
+		return models.ProfileResponse{}, err
+	}
+	return redacted, nil
+}
+
+// redactArtifacts copies artifacts with the store redaction policy applied
+// to their text and data parts. Compressed data parts are stored
+// decompressed, since their contents can't be redacted otherwise.
+func redactArtifacts(artifacts []Artifact) []Artifact {
+	redacted := make([]Artifact, len(artifacts))
+	for i, artifact := range artifacts {
+		parts := make([]MessagePart, len(artifact.Parts))
+		for j, part := range artifact.Parts {
+			switch text := part.Text.(type) {
+			case string:
+				part.Text = redact.ForStore(text)
+			case *string:
+				if text != nil {
+					part.Text = redact.ForStore(*text)
+				}
+			}
+			if part.Data != nil {
+				var data interface{}
+				if err := decodeDataPart(part, &data); err != nil {
+					logger.Warn("Failed to decode data part; not storing it", "artifact_id", artifact.ArtifactID, "error", err)
+					data = nil
+				}
+				part.Data = redactValue(data)
+				if part.Metadata["encoding"] == DataEncodingGzipBase64 {
+					metadata := make(map[string]interface{}, len(part.Metadata))
+					for key, value := range part.Metadata {
+						metadata[key] = value
+					}
+					delete(metadata, "encoding")
+					delete(metadata, "originalSize")
+					part.Metadata = metadata
+				}
+			}
+			parts[j] = part
+		}
+		artifact.Parts = parts
+		redacted[i] = artifact
+	}
+	return redacted
+}
+
+// redactValue applies the store redaction policy to the strings of a
+// decoded JSON value
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return redact.ForStore(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = redactValue(item)
+		}
+	}
+	return value
+}
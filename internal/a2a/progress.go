@@ -0,0 +1,111 @@
+package a2a
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+)
+
+// ProgressInterval is how often a working task repeats its latest status
+// while generation makes no visible progress
+const ProgressInterval = 10 * time.Second
+
+// phaseMessages are the working-state messages of each generation phase
+var phaseMessages = map[string]string{
+	profiler.PhaseAnalyzing:       "Analyzing the business idea...",
+	profiler.PhaseGenerating:      "Generating customer profiles...",
+	profiler.PhaseCheckingFit:     "Checking that each persona fits the idea...",
+	profiler.PhaseMappingJourneys: "Mapping customer journeys...",
+	profiler.PhaseSummarizing:     "Writing the market summary...",
+}
+
+// progressReporter sends working-state updates for a task. Updates are
+// serialized, and the latest one is repeated every interval until close.
+type progressReporter struct {
+	taskID string
+	report func(TaskStatus)
+	start  time.Time
+
+	mu      sync.Mutex
+	last    string
+	updated time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newProgressReporter(taskID string, report func(TaskStatus), interval time.Duration) *progressReporter {
+	p := &progressReporter{
+		taskID: taskID,
+		report: report,
+		start:  time.Now(),
+		stop:   make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.heartbeat(interval)
+			}
+		}
+	}()
+	return p
+}
+
+// update sends text as the task's working status
+func (p *progressReporter) update(text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.last = text
+	p.updated = time.Now()
+	p.report(workingStatus(p.taskID, text))
+}
+
+// heartbeat repeats the latest status if nothing was sent for interval
+func (p *progressReporter) heartbeat(interval time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.last == "" || time.Since(p.updated) < interval {
+		return
+	}
+	elapsed := time.Since(p.start).Round(time.Second)
+	p.report(workingStatus(p.taskID, fmt.Sprintf("%s (still working, %s elapsed)", p.last, elapsed)))
+}
+
+// phase sends the message of a generation phase
+func (p *progressReporter) phase(phase string) {
+	if text, ok := phaseMessages[phase]; ok {
+		p.update(text)
+	}
+}
+
+// personas reports that generated of total personas are done
+func (p *progressReporter) personas(generated, total int) {
+	p.update(fmt.Sprintf("Generated persona %d of %d", generated, total))
+}
+
+// close stops the heartbeat; no updates are sent once it returns
+func (p *progressReporter) close() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// storeProgress records working-state updates of a non-blocking task, so
+// tasks/get shows how far generation got
+func (h *A2AHandler) storeProgress(working TaskResult) func(TaskStatus) {
+	return func(status TaskStatus) {
+		working.Status = status
+		if err := h.config.TaskStore.Save(working); err != nil {
+			logger.Warn("Failed to store task progress", "task_id", working.ID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestid"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// NotificationTokenHeader carries the token from the push notification config
+	NotificationTokenHeader = "X-A2A-Notification-Token"
+	// pushAttempts is how many times a webhook delivery is tried
+	pushAttempts = 3
+	// pushBackoff is the delay before the first retry, doubled on each retry
+	pushBackoff = 2 * time.Second
+)
+
+// pushConfigs keeps the webhook registered for each task, dropping the
+// oldest registration once full
+type pushConfigs struct {
+	capacity int
+
+	mu      sync.Mutex
+	configs map[string]PushNotificationConfig
+	order   []string
+}
+
+func newPushConfigs(capacity int) *pushConfigs {
+	return &pushConfigs{
+		capacity: capacity,
+		configs:  make(map[string]PushNotificationConfig),
+	}
+}
+
+func (p *pushConfigs) set(taskID string, config PushNotificationConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.configs[taskID]; !exists {
+		for len(p.order) >= p.capacity {
+			delete(p.configs, p.order[0])
+			p.order = p.order[1:]
+		}
+		p.order = append(p.order, taskID)
+	}
+	p.configs[taskID] = config
+}
+
+func (p *pushConfigs) get(taskID string) (PushNotificationConfig, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	config, ok := p.configs[taskID]
+	return config, ok
+}
+
+// validatePushConfig checks the webhook URL and authentication settings.
+// Webhooks are only delivered to public addresses.
+func validatePushConfig(config PushNotificationConfig) *rpcError {
+	parsed, err := url.Parse(config.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return invalidParam("pushNotificationConfig.url", "pushNotificationConfig.url must be an absolute http(s) URL")
+	}
+	// Hostnames are checked when the webhook is dialed
+	if ip := net.ParseIP(parsed.Hostname()); ip != nil && !publicIP(ip) {
+		return invalidParam("pushNotificationConfig.url", "pushNotificationConfig.url must not point at a private, loopback or link-local address")
+	}
+	if auth := config.Authentication; auth != nil {
+		for _, scheme := range auth.Schemes {
+			if !strings.EqualFold(scheme, "bearer") {
+				return invalidParam("pushNotificationConfig.authentication.schemes", fmt.Sprintf("Unsupported push authentication scheme: %s", scheme))
+			}
+		}
+	}
+	return nil
+}
+
+// registerPush validates and stores the webhook for a task
+func (h *A2AHandler) registerPush(taskID string, config PushNotificationConfig) *rpcError {
+	if rpcErr := validatePushConfig(config); rpcErr != nil {
+		return rpcErr
+	}
+	h.push.set(taskID, config)
+	logger.Info("Registered push notifications", "task_id", taskID)
+	return nil
+}
+
+// notifyPush POSTs a finished task to its registered webhook in the
+// background, with the ID of the request that finished it
+func (h *A2AHandler) notifyPush(ctx context.Context, task TaskResult) {
+	config, ok := h.push.get(task.ID)
+	if !ok || !isTerminalState(task.Status.State) {
+		return
+	}
+	if h.config.DryRun {
+		logger.InfoContext(ctx, "Dry run: skipping push notification", "task_id", task.ID)
+		return
+	}
+
+	// Delivery outlives the task, but keeps its request ID and trace
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		backoff := pushBackoff
+		for attempt := 1; attempt <= pushAttempts; attempt++ {
+			err := h.deliverPush(ctx, config, task)
+			if err == nil {
+				logger.InfoContext(ctx, "Delivered push notification", "task_id", task.ID)
+				return
+			}
+			logger.WarnContext(ctx, "Push notification failed", "task_id", task.ID, "attempt", attempt, "attempts", pushAttempts, "error", err)
+			if attempt < pushAttempts {
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+		}
+	}()
+}
+
+func (h *A2AHandler) deliverPush(ctx context.Context, config PushNotificationConfig, task TaskResult) error {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if requestID := requestid.FromContext(ctx); requestID != "" {
+		req.Header.Set(requestid.Header, requestID)
+	}
+	tracing.Inject(ctx, req.Header)
+	if config.Token != "" {
+		req.Header.Set(NotificationTokenHeader, config.Token)
+	}
+	if config.Authentication != nil && config.Authentication.Credentials != "" {
+		req.Header.Set("Authorization", "Bearer "+config.Authentication.Credentials)
+	}
+
+	resp, err := h.pushClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// handleSetPushConfig registers a webhook for an existing task. A task that
+// already finished is delivered right away.
+func (h *A2AHandler) handleSetPushConfig(c *gin.Context, rpcReq JSONRPCRequest) {
+	paramsJSON, err := json.Marshal(rpcReq.Params)
+	if err != nil {
+		h.sendErrorResponse(c, rpcReq.ID, "Failed to parse parameters", CodeInvalidParams)
+		return
+	}
+
+	var params TaskPushNotificationConfig
+	if err := json.Unmarshal(paramsJSON, &params); err != nil || params.TaskID == "" {
+		h.sendError(c, rpcReq.ID, invalidParam("taskId", "Invalid parameters: taskId is required"))
+		return
+	}
+	params.TaskID = h.resolveTaskID(params.TaskID)
+
+	task, ok, err := h.config.TaskStore.Get(params.TaskID)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Failed to load task", "task_id", params.TaskID, "error", err)
+		h.sendError(c, rpcReq.ID, taskStoreError(params.TaskID))
+		return
+	}
+	if !ok || !ownsTask(c.Request.Context(), task) {
+		h.sendError(c, rpcReq.ID, taskNotFound(params.TaskID))
+		return
+	}
+
+	if rpcErr := h.registerPush(params.TaskID, params.PushNotificationConfig); rpcErr != nil {
+		h.sendError(c, rpcReq.ID, rpcErr)
+		return
+	}
+	h.notifyPush(c.Request.Context(), task)
+
+	h.sendSuccessResponse(c, rpcReq.ID, params)
+}
+
+// handleGetPushConfig returns the webhook registered for a task, without
+// its secrets
+func (h *A2AHandler) handleGetPushConfig(c *gin.Context, rpcReq JSONRPCRequest) {
+	paramsJSON, err := json.Marshal(rpcReq.Params)
+	if err != nil {
+		h.sendErrorResponse(c, rpcReq.ID, "Failed to parse parameters", CodeInvalidParams)
+		return
+	}
+
+	var params TaskIDParams
+	if err := json.Unmarshal(paramsJSON, &params); err != nil || params.ID == "" {
+		h.sendError(c, rpcReq.ID, invalidParam("id", "Invalid parameters: id is required"))
+		return
+	}
+	params.ID = h.resolveTaskID(params.ID)
+
+	task, ok, err := h.config.TaskStore.Get(params.ID)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Failed to load task", "task_id", params.ID, "error", err)
+		h.sendError(c, rpcReq.ID, taskStoreError(params.ID))
+		return
+	}
+	if !ok || !ownsTask(c.Request.Context(), task) {
+		h.sendError(c, rpcReq.ID, taskNotFound(params.ID))
+		return
+	}
+
+	config, ok := h.push.get(params.ID)
+	if !ok {
+		h.sendError(c, rpcReq.ID, &rpcError{
+			code:    CodeTaskNotFound,
+			message: fmt.Sprintf("No push notification config for task: %s", params.ID),
+			data:    ErrorData{TaskID: params.ID},
+		})
+		return
+	}
+
+	h.sendSuccessResponse(c, rpcReq.ID, TaskPushNotificationConfig{
+		TaskID:                 params.ID,
+		PushNotificationConfig: withoutSecrets(config),
+	})
+}
+
+// redactedSecret stands in for the webhook secrets the get method won't
+// return, so callers can still tell one is set
+const redactedSecret = "[REDACTED]"
+
+// withoutSecrets copies config with its token and credentials replaced
+func withoutSecrets(config PushNotificationConfig) PushNotificationConfig {
+	if config.Token != "" {
+		config.Token = redactedSecret
+	}
+	if auth := config.Authentication; auth != nil && auth.Credentials != "" {
+		redacted := *auth
+		redacted.Credentials = redactedSecret
+		config.Authentication = &redacted
+	}
+	return config
+}
@@ -0,0 +1,96 @@
+package a2a
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/auth"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the caller's API key
+const APIKeyHeader = "X-API-Key"
+
+// RateLimitConfig limits generation requests so one noisy caller can't
+// spend the model quota of everyone else
+type RateLimitConfig struct {
+	// PerIP, if set, admits requests by client IP
+	PerIP *ratelimit.Limiter
+	// PerKey, if set, admits requests by authenticated caller, or by their
+	// X-API-Key header when keys aren't checked, on top of the per-IP limit
+	PerKey *ratelimit.Limiter
+}
+
+// admitWrite applies the demo and rate limits to a generation request
+func (h *A2AHandler) admitWrite(c *gin.Context, method string) *rpcError {
+	if rpcErr := h.admitDemo(c, method); rpcErr != nil {
+		return rpcErr
+	}
+	return h.admitRateLimit(c, method)
+}
+
+// admitRateLimit refuses generation requests over the per-IP or per-key
+// limit with a rate limit error
+func (h *A2AHandler) admitRateLimit(c *gin.Context, method string) *rpcError {
+	limits := h.config.RateLimit
+	if limits == nil {
+		return nil
+	}
+
+	if limits.PerIP != nil {
+		ip := h.clientIP(c)
+		if allowed, retryAfter := limits.PerIP.Allow(ip); !allowed {
+			logger.WarnContext(c.Request.Context(), "Rate limited", "limit", "ip", "method", method, "client_ip", ip)
+			return rateLimited(method, "Request limit reached", retryAfter)
+		}
+	}
+	if key := quotaKey(c); key != "" && limits.PerKey != nil {
+		if allowed, retryAfter := limits.PerKey.Allow(key); !allowed {
+			logger.WarnContext(c.Request.Context(), "Rate limited", "limit", "api_key", "method", method)
+			return rateLimited(method, "Request limit for this API key reached", retryAfter)
+		}
+	}
+	return nil
+}
+
+// clientIP is the address per-IP limits count a request against: the
+// connection's remote address, or with trusted proxies configured, the
+// client they forwarded the request for. Without any, forwarding headers
+// are ignored, since any client could set them to dodge the limit.
+func (h *A2AHandler) clientIP(c *gin.Context) string {
+	if len(h.config.TrustedProxies) == 0 {
+		return c.RemoteIP()
+	}
+	return c.ClientIP()
+}
+
+// quotaKey is what the per-key limit counts a request against: the
+// authenticated caller, or else the hash of an unchecked X-API-Key
+func quotaKey(c *gin.Context) string {
+	if identity, ok := auth.FromContext(c.Request.Context()); ok {
+		return identity.Method + ":" + identity.Name
+	}
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return "key:" + auth.HashKey(key)
+	}
+	return ""
+}
+
+// rateLimited builds the error of a refused request, sent with a
+// Retry-After header
+func rateLimited(method, reason string, retryAfter time.Duration) *rpcError {
+	// The 429 lets HTTP clients and proxies back off without reading the
+	// body
+	return &rpcError{
+		status:     http.StatusTooManyRequests,
+		retryAfter: retryAfter,
+		code:       CodeRateLimited,
+		message:    fmt.Sprintf("%s; try again in %s", reason, retryAfter.Round(time.Second)),
+		data: ErrorData{
+			Method: method,
+			ETA:    time.Now().Add(retryAfter).UTC().Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,44 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// writeMethods generate profiles or write tasks, so read-only mode and a
+// draining server refuse them
+var writeMethods = map[string]bool{
+	"message/send":                     true,
+	"message/stream":                   true,
+	"tasks/feedback":                   true,
+	"tasks/pushNotificationConfig/set": true,
+}
+
+// refusesWrites reports whether write methods are refused, in read-only
+// mode or while the server drains for shutdown
+func (h *A2AHandler) refusesWrites() bool {
+	return h.config.ReadOnly || h.running.isDraining()
+}
+
+// maintenanceError refuses a write method in read-only mode, with the
+// expected end of the maintenance window if one is configured
+func (h *A2AHandler) maintenanceError(ctx context.Context, method string) *rpcError {
+	if !h.config.ReadOnly {
+		logger.WarnContext(ctx, "Refused request while shutting down", "method", method)
+		return &rpcError{
+			code:    CodeMaintenance,
+			message: "The agent is shutting down; retry the request",
+			data:    ErrorData{Method: method},
+		}
+	}
+	logger.WarnContext(ctx, "Refused request in read-only mode", "method", method)
+
+	message := "The agent is read-only for maintenance; retrieval still works"
+	data := ErrorData{Method: method}
+	if eta := h.config.ReadOnlyETA; !eta.IsZero() {
+		data.ETA = eta.UTC().Format(time.RFC3339)
+		message = fmt.Sprintf("%s. Try again after %s", message, data.ETA)
+	}
+	return &rpcError{code: CodeMaintenance, message: message, data: data}
+}
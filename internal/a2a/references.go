@@ -0,0 +1,84 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+)
+
+// referenceField names referenceTaskIds in errors and warnings
+const referenceField = "message.referenceTaskIds"
+
+// referencedProfiles loads the profiles of the tasks a message references,
+// read from their "Customer Profile JSON" artifacts and merged in reference
+// order. It returns nil when the message references no tasks. Unknown tasks
+// and tasks without profiles are errors.
+func (h *A2AHandler) referencedProfiles(ctx context.Context, msg A2AMessage, warnings *limitWarnings) (*models.ProfileResponse, *rpcError) {
+	if len(msg.ReferenceTaskIDs) == 0 {
+		return nil, nil
+	}
+
+	var merged *models.ProfileResponse
+	for _, taskID := range msg.ReferenceTaskIDs {
+		task, ok, err := h.config.TaskStore.Get(taskID)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to load referenced task", "task_id", taskID, "error", err)
+			return nil, taskStoreError(taskID)
+		}
+		if !ok {
+			return nil, taskNotFound(taskID)
+		}
+
+		profiles, ok := structuredProfiles(task)
+		if !ok {
+			return nil, &rpcError{
+				code:    CodeInvalidParams,
+				message: fmt.Sprintf("Referenced task %s has no profiles", taskID),
+				data:    ErrorData{TaskID: taskID, Field: referenceField},
+			}
+		}
+
+		if merged == nil {
+			merged = profiles
+			continue
+		}
+		merged.Profiles = append(merged.Profiles, profiles.Profiles...)
+		merged.Sources = append(merged.Sources, profiles.Sources...)
+	}
+
+	if len(merged.Profiles) > profiler.MaxPersonaCount {
+		limit := fmt.Sprintf("may hold at most %d personas in total", profiler.MaxPersonaCount)
+		if rpcErr := h.exceeded(warnings, referenceField, limit, fmt.Sprintf("using the first %d", profiler.MaxPersonaCount)); rpcErr != nil {
+			return nil, rpcErr
+		}
+		merged.Profiles = merged.Profiles[:profiler.MaxPersonaCount]
+	}
+
+	logger.InfoContext(ctx, "Loaded profiles from referenced tasks", "profiles", len(merged.Profiles), "tasks", len(msg.ReferenceTaskIDs))
+	return merged, nil
+}
+
+// structuredProfiles decodes the ProfileResponse of a task's structured artifact
+func structuredProfiles(task TaskResult) (*models.ProfileResponse, bool) {
+	for _, artifact := range task.Artifacts {
+		if artifact.Name != StructuredArtifactName {
+			continue
+		}
+		for _, part := range artifact.Parts {
+			if part.Kind != "data" {
+				continue
+			}
+			var profiles models.ProfileResponse
+			if err := decodeDataPart(part, &profiles); err != nil {
+				logger.Warn("Failed to decode profiles of referenced task", "task_id", task.ID, "error", err)
+				continue
+			}
+			if len(profiles.Profiles) > 0 {
+				return &profiles, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,82 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MessageMetadataRegenerates names, in the message metadata of an
+// automatic refinement, the poorly rated task it improves on
+const MessageMetadataRegenerates = "regenerates"
+
+// shouldRegenerate reports whether feedback asks for an automatic
+// refinement: a rating below RegenerateBelow that says what was wrong
+func (h *A2AHandler) shouldRegenerate(feedback Feedback) bool {
+	return feedback.Rating < h.config.RegenerateBelow && feedback.Comment != ""
+}
+
+// regenerate starts refining a poorly rated task's profiles with the
+// feedback comment as guidance and returns the new task's ID, or "" if it
+// couldn't start. The refinement runs as a non-blocking message/send in
+// the task's conversation; the task's push notification config, if it has
+// one, delivers the improved profiles.
+func (h *A2AHandler) regenerate(c *gin.Context, task TaskResult, comment string) string {
+	msgParams := MessageParams{
+		Message: A2AMessage{
+			Kind:             "message",
+			Role:             RoleUser,
+			Parts:            []MessagePart{TextPart(comment)},
+			MessageID:        uuid.New().String(),
+			ContextID:        task.ContextID,
+			ReferenceTaskIDs: []string{task.ID},
+			Metadata:         map[string]interface{}{MessageMetadataRegenerates: task.ID},
+		},
+		Metadata: map[string]interface{}{MetadataSkillID: agent.SkillPersonaRefinement},
+	}
+	blocking := false
+	msgParams.Configuration.Blocking = &blocking
+	if config, ok := h.push.get(task.ID); ok {
+		msgParams.Configuration.PushNotificationConfig = &config
+	}
+	if correlation, ok := task.Metadata[MetadataCorrelation]; ok {
+		msgParams.Metadata[MetadataCorrelation] = correlation
+	}
+
+	body, err := json.Marshal(JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      uuid.New().String(),
+		Method:  "message/send",
+		Params:  msgParams,
+	})
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Failed to encode refinement", "task_id", task.ID, "error", err)
+		return ""
+	}
+
+	// The refinement outlives the feedback request that started it
+	req := c.Request.Clone(context.WithoutCancel(c.Request.Context()))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	var resp JSONRPCResponse
+	var started TaskResult
+	resp.Result = &started
+	recorder := h.serveInternal(req)
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		logger.WarnContext(c.Request.Context(), "Failed to start refinement", "task_id", task.ID, "error", err)
+		return ""
+	}
+	if resp.Error != nil {
+		logger.WarnContext(c.Request.Context(), "Failed to start refinement", "task_id", task.ID, "error", resp.Error)
+		return ""
+	}
+
+	logger.InfoContext(c.Request.Context(), "Refining poorly rated task", "task_id", task.ID, "refinement_task_id", started.ID)
+	return started.ID
+}
@@ -0,0 +1,109 @@
+package a2a
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestlog"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/testutil"
+	"github.com/gin-gonic/gin"
+)
+
+// ReplayMode selects what a replayed request is generated with
+type ReplayMode string
+
+const (
+	// ReplayDryRun calls the real model but keeps the replay's tasks,
+	// contexts and notifications away from live state
+	ReplayDryRun ReplayMode = "dry-run"
+	// ReplayMock answers with testutil.MockGenerator and never calls the model
+	ReplayMock ReplayMode = "mock"
+)
+
+// ParseReplayMode validates a replay mode name; empty means ReplayMock
+func ParseReplayMode(raw string) (ReplayMode, error) {
+	switch mode := ReplayMode(raw); mode {
+	case "":
+		return ReplayMock, nil
+	case ReplayDryRun, ReplayMock:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown replay mode %q (use %s or %s)", raw, ReplayDryRun, ReplayMock)
+	}
+}
+
+// Replay runs a raw A2A request body through a throwaway handler built from
+// config and returns the recorded HTTP response. The replay gets fresh task
+// and context stores, is not recorded in the request log, persona library
+// or feedback stats, renders no avatars and delivers no push notifications.
+// Request headers are not replayed, so prompt overrides are refused. Since
+// it touches no live state, a replay also runs in read-only mode, and it
+// is not rate limited in demo mode.
+func Replay(generator profiler.ProfileGenerator, config HandlerConfig, body []byte, mode ReplayMode) *http.Response {
+	if mode == ReplayMock {
+		generator = testutil.NewMockGenerator()
+	}
+
+	config.DryRun = true
+	config.ReadOnly = false
+	if config.Demo != nil {
+		demo := *config.Demo
+		demo.Limiter = nil
+		config.Demo = &demo
+	}
+	config.TaskStore = nil
+	config.ContextStore = nil
+	config.RequestLog = nil
+	config.PersonaLibrary = nil
+	config.ProfileRepository = nil
+	config.FeedbackStats = nil
+	config.AvatarGenerator = nil
+	config.AvatarStore = nil
+	handler := NewA2AHandler(generator, config)
+
+	req := httptest.NewRequest(http.MethodPost, "/a2a/profiler", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	logger.Info("Replaying request", "mode", mode)
+	return handler.serveInternal(req).Result()
+}
+
+// ServeReplay re-runs a request from the request log through the current
+// code. The mode query parameter picks dry-run or mock (the default).
+// Callers must present token as a bearer token, as for /debug/requests.
+func (h *A2AHandler) ServeReplay(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requestlog.Authorize(c, token) {
+			return
+		}
+		if h.config.RequestLog == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Request log is disabled"})
+			return
+		}
+
+		mode, err := ParseReplayMode(c.Query("mode"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		record, ok := h.config.RequestLog.Find(c.Param("traceID"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No such request in the log"})
+			return
+		}
+		if record.Request == "" {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The request body was not kept and cannot be replayed"})
+			return
+		}
+
+		resp := Replay(h.generator, h.config, []byte(record.Request), mode)
+		defer resp.Body.Close()
+
+		c.Header("X-Replay-Mode", string(mode))
+		c.DataFromReader(resp.StatusCode, resp.ContentLength, resp.Header.Get("Content-Type"), resp.Body, nil)
+	}
+}
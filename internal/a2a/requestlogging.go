@@ -0,0 +1,75 @@
+package a2a
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLoggingConfig configures RequestLoggingMiddleware
+type RequestLoggingConfig struct {
+	// Enabled, if set, switches logging on and off while running; nil
+	// logs always
+	Enabled *atomic.Bool
+	// MaxBodyBytes truncates logged bodies; zero logs no body
+	MaxBodyBytes int
+	// SampleRate is the fraction of requests logged; zero or less logs none
+	SampleRate float64
+	// RedactHeaders and RedactFields are masked on top of the credential
+	// headers and fields redact always masks
+	RedactHeaders []string
+	RedactFields  []string
+}
+
+// RequestLoggingMiddleware logs a sample of incoming requests at info
+// level, with credentials masked and the body truncated; the access log
+// records how they were served. Only the logged prefix of the body is read
+// ahead; the handler still reads the whole body.
+func RequestLoggingMiddleware(config RequestLoggingConfig) gin.HandlerFunc {
+	fields := redact.NewFieldMasker(config.RedactFields...)
+
+	return func(c *gin.Context) {
+		if config.Enabled != nil && !config.Enabled.Load() {
+			c.Next()
+			return
+		}
+		if config.SampleRate < 1 && rand.Float64() >= config.SampleRate {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		args := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"headers", redact.Headers(c.Request.Header, config.RedactHeaders...),
+		}
+		if config.MaxBodyBytes > 0 && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			prefix, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(config.MaxBodyBytes)+1))
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), c.Request.Body), c.Request.Body}
+			if err != nil {
+				logger.WarnContext(ctx, "Failed to read request body for logging", "error", err)
+			}
+
+			truncated := len(prefix) > config.MaxBodyBytes
+			if truncated {
+				prefix = prefix[:config.MaxBodyBytes]
+			}
+			args = append(args, "body", redact.ForLog(fields.Mask(string(prefix))), "body_truncated", truncated)
+		}
+		logger.InfoContext(ctx, "Incoming request", args...)
+		c.Next()
+	}
+}
+
+// readCloser reads the logged prefix back before the rest of the body,
+// closing the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
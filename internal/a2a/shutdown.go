@@ -0,0 +1,52 @@
+package a2a
+
+import (
+	"context"
+	"time"
+)
+
+// drainPollInterval is how often Drain checks for running generations
+const drainPollInterval = 100 * time.Millisecond
+
+// drainCancelWait bounds how long Drain waits for canceled generations to
+// record their state
+const drainCancelWait = 5 * time.Second
+
+// Drain prepares the handler for shutdown. New generations are refused
+// with a retryable maintenance error, and Drain waits for the running
+// ones, including background tasks, to finish and be stored. Generations
+// still running when ctx is done are canceled, and ctx's error returned.
+func (h *A2AHandler) Drain(ctx context.Context) error {
+	h.running.mu.Lock()
+	h.running.draining = true
+	h.running.mu.Unlock()
+
+	if n := h.running.count(); n > 0 {
+		logger.Info("Draining running generations", "generations", n)
+	}
+	if waitForTasks(ctx, h.running) {
+		return nil
+	}
+
+	logger.Warn("Canceling generations still running at shutdown", "generations", h.running.count())
+	h.running.cancelAll()
+	cancelCtx, cancel := context.WithTimeout(context.Background(), drainCancelWait)
+	defer cancel()
+	waitForTasks(cancelCtx, h.running)
+	return ctx.Err()
+}
+
+// waitForTasks reports whether every generation finished before ctx was done
+func waitForTasks(ctx context.Context, running *runningTasks) bool {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for running.count() > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+)
+
+// skillRoute sets up the options of a message routed to a skill. previous
+// holds the profiles of the referenced tasks or else the latest profiles of
+// the conversation, or nil.
+type skillRoute func(opts *profiler.GenerateOptions, previous *models.ProfileResponse) *rpcError
+
+// skillRoutes handles every skill of the agent card. Messages without a
+// skillId take routeAuto.
+var skillRoutes = map[string]skillRoute{
+	agent.SkillProfileGeneration: routeProfileGeneration,
+	agent.SkillPersonaRefinement: routePersonaRefinement,
+	agent.SkillJourneyMapping:    routeJourneyMapping,
+}
+
+// legacySkillIDs maps skill IDs of earlier agent cards to their skill
+var legacySkillIDs = map[string]string{
+	"customer-profiling": agent.SkillProfileGeneration,
+	"profile-refinement": agent.SkillPersonaRefinement,
+}
+
+// resolveSkill reads the skillId metadata and returns the skill and its
+// route; the skill is "" for messages that don't name one
+func resolveSkill(metadata map[string]interface{}) (string, skillRoute, *rpcError) {
+	skill, rpcErr := metadataString(metadata, MetadataSkillID)
+	if rpcErr != nil {
+		return "", nil, rpcErr
+	}
+	if skill == "" {
+		return "", routeAuto, nil
+	}
+
+	if current, ok := legacySkillIDs[skill]; ok {
+		skill = current
+	}
+	route, ok := skillRoutes[skill]
+	if !ok {
+		return "", nil, invalidParam(MetadataSkillID, fmt.Sprintf("unknown skill %q (supported: %s)", skill, strings.Join(supportedSkills(), ", ")))
+	}
+	logger.Debug("Routing message to skill", "skill", skill)
+	return skill, route, nil
+}
+
+func supportedSkills() []string {
+	skills := make([]string, 0, len(skillRoutes))
+	for skill := range skillRoutes {
+		skills = append(skills, skill)
+	}
+	sort.Strings(skills)
+	return skills
+}
+
+// routeAuto refines the conversation's profiles if it has any and
+// generates new ones otherwise
+func routeAuto(opts *profiler.GenerateOptions, previous *models.ProfileResponse) *rpcError {
+	if previous != nil {
+		logger.Info("Refining profiles from the conversation", "profiles", len(previous.Profiles))
+		opts.Previous = previous
+	}
+	return nil
+}
+
+// routeProfileGeneration always starts over from the business idea
+func routeProfileGeneration(opts *profiler.GenerateOptions, previous *models.ProfileResponse) *rpcError {
+	return nil
+}
+
+// routePersonaRefinement rewrites the conversation's profiles and fails
+// when there are none to refine
+func routePersonaRefinement(opts *profiler.GenerateOptions, previous *models.ProfileResponse) *rpcError {
+	if previous == nil {
+		return invalidParam(MetadataSkillID, agent.SkillPersonaRefinement+" needs a contextId or referenceTaskIds with earlier profiles")
+	}
+	return routeAuto(opts, previous)
+}
+
+// routeJourneyMapping maps the conversation's profiles, or new profiles
+// for the business idea when there are none
+func routeJourneyMapping(opts *profiler.GenerateOptions, previous *models.ProfileResponse) *rpcError {
+	opts.Journeys = true
+	opts.Previous = previous
+	return nil
+}
+
+// previousProfiles returns the latest profiles of a conversation, or nil if
+// it has none or they can't be loaded
+func (h *A2AHandler) previousProfiles(ctx context.Context, contextID string) *models.ProfileResponse {
+	previous, ok, err := h.config.ContextStore.Get(contextID)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to load context, generating from scratch", "context_id", contextID, "error", err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	return previous
+}
@@ -0,0 +1,112 @@
+package a2a
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatsWindow is the span of the recent request counts in Stats
+const StatsWindow = 5 * time.Minute
+
+// Stats is a snapshot of the handler's load for operators
+type Stats struct {
+	// Workers is nil when generations aren't bounded by a worker pool
+	Workers *WorkerStats `json:"workers"`
+	// Recent counts requests over the last StatsWindow, Total since start
+	Recent RequestCounts `json:"recent"`
+	Total  RequestCounts `json:"total"`
+}
+
+// WorkerStats reports the worker pool's use: busy workers and queued
+// tasks, out of its capacity
+type WorkerStats struct {
+	Busy      int `json:"busy"`
+	Queued    int `json:"queued"`
+	Workers   int `json:"workers"`
+	QueueSize int `json:"queueSize"`
+}
+
+// RequestCounts counts A2A requests answered with a JSON-RPC error, and
+// those whose task failed. A batch counts as one request.
+type RequestCounts struct {
+	Requests    int64 `json:"requests"`
+	Errors      int64 `json:"errors"`
+	FailedTasks int64 `json:"failedTasks"`
+	// ErrorRate is the fraction of requests that errored or failed
+	ErrorRate float64 `json:"errorRate"`
+}
+
+func (r *RequestCounts) add(other RequestCounts) {
+	r.Requests += other.Requests
+	r.Errors += other.Errors
+	r.FailedTasks += other.FailedTasks
+}
+
+func (r RequestCounts) withRate() RequestCounts {
+	if r.Requests > 0 {
+		r.ErrorRate = float64(r.Errors+r.FailedTasks) / float64(r.Requests)
+	}
+	return r
+}
+
+// requestStats counts requests in total and per minute over StatsWindow
+type requestStats struct {
+	mu      sync.Mutex
+	total   RequestCounts
+	minutes [int(StatsWindow / time.Minute)]struct {
+		minute int64
+		counts RequestCounts
+	}
+}
+
+// record counts a finished request by the outcome in its request log record
+func (s *requestStats) record(outcome string) {
+	var counts RequestCounts
+	counts.Requests = 1
+	switch {
+	case strings.HasPrefix(outcome, "error "):
+		counts.Errors = 1
+	case outcome == StateFailed:
+		counts.FailedTasks = 1
+	}
+
+	minute := time.Now().Unix() / 60
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total.add(counts)
+	bucket := &s.minutes[minute%int64(len(s.minutes))]
+	if bucket.minute != minute {
+		bucket.minute = minute
+		bucket.counts = RequestCounts{}
+	}
+	bucket.counts.add(counts)
+}
+
+// snapshot returns the recent and total counts
+func (s *requestStats) snapshot() (recent, total RequestCounts) {
+	oldest := time.Now().Unix()/60 - int64(len(s.minutes)) + 1
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bucket := range s.minutes {
+		if bucket.minute >= oldest {
+			recent.add(bucket.counts)
+		}
+	}
+	return recent.withRate(), s.total.withRate()
+}
+
+// stats reports the pool's use, or nil for an unlimited pool
+func (p *workerPool) stats() *WorkerStats {
+	if p == nil {
+		return nil
+	}
+	return &WorkerStats{Busy: len(p.workers), Queued: len(p.queue), Workers: cap(p.workers), QueueSize: cap(p.queue)}
+}
+
+// Stats reports the worker pool's use and the recent and total request
+// and error counts
+func (h *A2AHandler) Stats() Stats {
+	recent, total := h.requests.snapshot()
+	return Stats{Workers: h.workers.stats(), Recent: recent, Total: total}
+}
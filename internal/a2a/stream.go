@@ -0,0 +1,276 @@
+package a2a
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/accesslog"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/agentkit"
+	"github.com/gin-gonic/gin"
+)
+
+// streamRetention is how long a finished stream's events stay available to tasks/resubscribe
+const streamRetention = 10 * time.Minute
+
+// StoredTaskPollInterval is how often tasks/resubscribe polls the task
+// store for a task running elsewhere
+const StoredTaskPollInterval = time.Second
+
+// taskEventLog buffers a streaming task's events so clients that lost the
+// connection can resubscribe and catch up
+type taskEventLog struct {
+	mu      sync.Mutex
+	events  []interface{}
+	writers map[*agentkit.EventStream]bool
+	done    chan struct{}
+}
+
+func newTaskEventLog() *taskEventLog {
+	return &taskEventLog{
+		writers: make(map[*agentkit.EventStream]bool),
+		done:    make(chan struct{}),
+	}
+}
+
+// publish appends an event and forwards it to every attached stream; final
+// closes the log
+func (l *taskEventLog) publish(event interface{}, final bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seq := len(l.events)
+	l.events = append(l.events, event)
+	for stream := range l.writers {
+		stream.Send(seq, JSONRPCResponse{Result: event})
+	}
+	if final {
+		close(l.done)
+	}
+}
+
+// attach replays events from seq onward to stream and then forwards new
+// ones until detach is called
+func (l *taskEventLog) attach(stream *agentkit.EventStream, from int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for seq := max(from, 0); seq < len(l.events); seq++ {
+		stream.Send(seq, JSONRPCResponse{Result: l.events[seq]})
+	}
+	l.writers[stream] = true
+}
+
+func (l *taskEventLog) detach(stream *agentkit.EventStream) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.writers, stream)
+}
+
+// streamingTasks indexes the event logs of streamed tasks by task ID
+type streamingTasks struct {
+	mu   sync.Mutex
+	logs map[string]*taskEventLog
+}
+
+func newStreamingTasks() *streamingTasks {
+	return &streamingTasks{logs: make(map[string]*taskEventLog)}
+}
+
+func (s *streamingTasks) start(taskID string) *taskEventLog {
+	events := newTaskEventLog()
+
+	s.mu.Lock()
+	s.logs[taskID] = events
+	s.mu.Unlock()
+
+	go func() {
+		<-events.done
+		time.Sleep(streamRetention)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.logs[taskID] == events {
+			delete(s.logs, taskID)
+		}
+	}()
+
+	return events
+}
+
+func (s *streamingTasks) get(taskID string) (*taskEventLog, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events, ok := s.logs[taskID]
+	return events, ok
+}
+
+// handleStream runs a message/send request but streams status and artifact
+// updates as Server-Sent Events instead of returning a single response
+func (h *A2AHandler) handleStream(c *gin.Context, rpcReq JSONRPCRequest) {
+	logger.DebugContext(c.Request.Context(), "Handling JSON-RPC stream", "id", rpcReq.ID)
+
+	paramsJSON, err := json.Marshal(rpcReq.Params)
+	if err != nil {
+		h.sendErrorResponse(c, rpcReq.ID, "Failed to parse parameters", CodeInvalidParams)
+		return
+	}
+
+	var msgParams MessageParams
+	if err := json.Unmarshal(paramsJSON, &msgParams); err != nil {
+		logger.WarnContext(c.Request.Context(), "Failed to unmarshal params", "error", err)
+		h.sendErrorResponse(c, rpcReq.ID, "Invalid parameters", CodeInvalidParams)
+		return
+	}
+
+	taskID := h.resumeTask(c.Request.Context(), rpcReq.ID, &msgParams)
+	accesslog.AddTask(c.Request.Context(), taskID)
+	ensureContextID(&msgParams.Message)
+	contextID := msgParams.Message.ContextID
+
+	stream := agentkit.NewEventStream(c, rpcReq.ID, logger, redact.ForLog)
+	events := h.streaming.start(taskID)
+	events.attach(stream, 0)
+	defer events.detach(stream)
+
+	// The task outlives this stream, so clients that lose the connection
+	// can resubscribe
+	result, rpcErr := h.runTask(c, taskID, msgParams, true, func(status TaskStatus) {
+		events.publish(agentkit.StatusEvent(taskID, contextID, status, false), false)
+	})
+	if rpcErr != nil {
+		c.Set(ctxKeyOutcome, fmt.Sprintf("error %d", rpcErr.code))
+		events.detach(stream)
+		events.publish(agentkit.StatusEvent(taskID, contextID, TaskStatus{State: StateFailed, Timestamp: Timestamp()}, true), true)
+		stream.Send(-1, JSONRPCResponse{Error: rpcErr.objectFor(c.Request.Context())})
+		return
+	}
+
+	result = h.saveTask(c.Request.Context(), result, msgParams)
+	c.Set(ctxKeyOutcome, result.Status.State)
+
+	for _, artifact := range result.Artifacts {
+		for _, event := range agentkit.ArtifactEvents(taskID, result.ContextID, artifact) {
+			events.publish(event, false)
+		}
+	}
+	final := agentkit.StatusEvent(taskID, result.ContextID, result.Status, true)
+	final.Metadata = result.Metadata
+	events.publish(final, true)
+}
+
+// handleResubscribe reattaches a client to a streamed task, replaying the
+// events it missed. The SSE Last-Event-ID header skips events already received.
+func (h *A2AHandler) handleResubscribe(c *gin.Context, rpcReq JSONRPCRequest) {
+	paramsJSON, err := json.Marshal(rpcReq.Params)
+	if err != nil {
+		h.sendErrorResponse(c, rpcReq.ID, "Failed to parse parameters", CodeInvalidParams)
+		return
+	}
+
+	var params TaskIDParams
+	if err := json.Unmarshal(paramsJSON, &params); err != nil || params.ID == "" {
+		h.sendError(c, rpcReq.ID, invalidParam("id", "Invalid parameters: id is required"))
+		return
+	}
+	params.ID = h.resolveTaskID(params.ID)
+	accesslog.AddTask(c.Request.Context(), params.ID)
+
+	from := 0
+	if lastID := c.GetHeader("Last-Event-ID"); lastID != "" {
+		seq, err := strconv.Atoi(lastID)
+		if err != nil {
+			h.sendError(c, rpcReq.ID, invalidParam("Last-Event-ID", "Invalid Last-Event-ID header"))
+			return
+		}
+		from = seq + 1
+	}
+
+	events, ok := h.streaming.get(params.ID)
+	if !ok {
+		h.replayStoredTask(c, rpcReq.ID, params.ID)
+		return
+	}
+
+	logger.InfoContext(c.Request.Context(), "Client resubscribed", "task_id", params.ID, "from_event", from)
+
+	stream := agentkit.NewEventStream(c, rpcReq.ID, logger, redact.ForLog)
+	events.attach(stream, from)
+	defer events.detach(stream)
+
+	select {
+	case <-events.done:
+	case <-c.Request.Context().Done():
+	}
+}
+
+// replayStoredTask streams a task that isn't buffered here from the task
+// store: a finished task as its artifacts followed by its final status. A
+// task still running, on another replica sharing the store or started
+// without a stream, is followed by polling the store, with a status event
+// for each change, until it finishes or waits for input. Following gives up
+// after the generation timeout, by which a live task would have ended.
+func (h *A2AHandler) replayStoredTask(c *gin.Context, rpcID, taskID string) {
+	ctx := c.Request.Context()
+	task, ok, err := h.config.TaskStore.Get(taskID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to load task", "task_id", taskID, "error", err)
+		h.sendError(c, rpcID, taskStoreError(taskID))
+		return
+	}
+	if !ok {
+		h.sendError(c, rpcID, taskNotFound(taskID))
+		return
+	}
+
+	stream := agentkit.NewEventStream(c, rpcID, logger, redact.ForLog)
+	if !isTerminalState(task.Status.State) && task.Status.State != StateInputRequired {
+		logger.InfoContext(ctx, "Following stored task", "task_id", taskID, "state", task.Status.State)
+		var giveUp <-chan time.Time
+		if h.config.GenerationTimeout > 0 {
+			giveUp = time.After(h.config.GenerationTimeout)
+		}
+		ticker := time.NewTicker(StoredTaskPollInterval)
+		defer ticker.Stop()
+
+		stream.Send(-1, JSONRPCResponse{Result: agentkit.StatusEvent(task.ID, task.ContextID, task.Status, false)})
+		for !isTerminalState(task.Status.State) && task.Status.State != StateInputRequired {
+			select {
+			case <-ctx.Done():
+				return
+			case <-giveUp:
+				stream.Send(-1, JSONRPCResponse{Error: (&rpcError{
+					code:    CodeInternalError,
+					message: fmt.Sprintf("Task %s is still %s after %s", taskID, task.Status.State, h.config.GenerationTimeout),
+					data:    ErrorData{TaskID: taskID},
+				}).objectFor(ctx)})
+				return
+			case <-ticker.C:
+			}
+
+			latest, ok, err := h.config.TaskStore.Get(taskID)
+			if err != nil || !ok {
+				logger.WarnContext(ctx, "Failed to poll followed task", "task_id", taskID, "found", ok, "error", err)
+				continue
+			}
+			if latest.Status.State != task.Status.State || latest.Status.Timestamp != task.Status.Timestamp {
+				task = latest
+				if !isTerminalState(task.Status.State) && task.Status.State != StateInputRequired {
+					stream.Send(-1, JSONRPCResponse{Result: agentkit.StatusEvent(task.ID, task.ContextID, task.Status, false)})
+				}
+			}
+		}
+	}
+
+	for _, artifact := range task.Artifacts {
+		for _, event := range agentkit.ArtifactEvents(task.ID, task.ContextID, artifact) {
+			stream.Send(-1, JSONRPCResponse{Result: event})
+		}
+	}
+	stream.Send(-1, JSONRPCResponse{Result: agentkit.StatusEvent(task.ID, task.ContextID, task.Status, true)})
+}
@@ -0,0 +1,39 @@
+package a2a
+
+import (
+	"github.com/google/uuid"
+)
+
+// requestTasks maps the JSON-RPC request ids that started tasks to the
+// generated task IDs, so a client that looks a task up by its request id
+// still finds it
+type requestTasks struct {
+	store *memoryStore[string]
+}
+
+func newRequestTasks(capacity int) *requestTasks {
+	return &requestTasks{store: newMemoryStore(capacity, DefaultStoreTTL, func(id string) string { return id })}
+}
+
+// newTaskID names a new task independently of the request that started it
+func (h *A2AHandler) newTaskID(rpcID string) string {
+	taskID := uuid.New().String()
+	if rpcID != "" && rpcID != "direct-message" {
+		h.requestTasks.store.set(rpcID, taskID)
+	}
+	return taskID
+}
+
+// resolveTaskID returns the task a client-supplied id names: a stored task
+// with that ID, or else the task started by a request with that id
+func (h *A2AHandler) resolveTaskID(id string) string {
+	taskID, ok := h.requestTasks.store.get(id)
+	if !ok || taskID == id {
+		return id
+	}
+	if _, exists, err := h.config.TaskStore.Get(id); err == nil && exists {
+		return id
+	}
+	logger.Debug("Resolved request id to task", "id", id, "task_id", taskID)
+	return taskID
+}
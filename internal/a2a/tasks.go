@@ -0,0 +1,289 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/gin-gonic/gin"
+)
+
+// runningTasks tracks the cancel funcs of in-flight generations by task ID
+type runningTasks struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+	// draining refuses new generations while the server shuts down
+	draining bool
+}
+
+func newRunningTasks() *runningTasks {
+	return &runningTasks{cancels: make(map[string]context.CancelFunc)}
+}
+
+// cancel stops the task's generation; it returns false if none is running
+func (r *runningTasks) cancel(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cancel, ok := r.cancels[id]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// cancelAll stops every running generation
+func (r *runningTasks) cancelAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, cancel := range r.cancels {
+		cancel()
+	}
+}
+
+// count returns how many generations are running
+func (r *runningTasks) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.cancels)
+}
+
+func (r *runningTasks) isDraining() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.draining
+}
+
+// startTask records the task as working and returns a context that
+// tasks/cancel can cancel, the working task, and a func to call once
+// generation ends. The context keeps the values of parent, such as its
+// request ID, and is canceled with it unless detach is set, for tasks that
+// outlive the request. It expires after the generation timeout.
+func (h *A2AHandler) startTask(parent context.Context, detach bool, taskID string, msgParams MessageParams) (context.Context, TaskResult, func()) {
+	if detach {
+		parent = context.WithoutCancel(parent)
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout := h.config.GenerationTimeout; timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+
+	h.running.mu.Lock()
+	h.running.cancels[taskID] = cancel
+	h.running.mu.Unlock()
+
+	working := TaskResult{
+		ID:        taskID,
+		ContextID: msgParams.Message.ContextID,
+		Kind:      "task",
+		Status: TaskStatus{
+			State:     StateWorking,
+			Timestamp: Timestamp(),
+		},
+	}
+	working.History = taskHistory(msgParams, working)
+	working = withCorrelation(working, msgParams)
+	working = withMessageMetadata(working, msgParams)
+	working = withCaller(working, parent)
+	if err := h.config.TaskStore.Save(working); err != nil {
+		logger.WarnContext(ctx, "Failed to store task", "task_id", taskID, "error", err)
+	}
+
+	return ctx, working, func() {
+		h.running.mu.Lock()
+		delete(h.running.cancels, taskID)
+		h.running.mu.Unlock()
+		cancel()
+	}
+}
+
+// processMessageAsync answers with the working task right away, or the
+// submitted task if it waits for a worker, and generates in the
+// background; clients poll tasks/get or use push notifications for the
+// result
+func (h *A2AHandler) processMessageAsync(c *gin.Context, rpcID string, prepared *preparedTask, msgParams MessageParams) {
+	taskID := prepared.taskID
+	ctx, working, done := h.startTask(c.Request.Context(), true, taskID, msgParams)
+	if prepared.worker.waiting() {
+		working.Status.State = StateSubmitted
+		if err := h.config.TaskStore.Save(working); err != nil {
+			logger.WarnContext(ctx, "Failed to store task", "task_id", taskID, "error", err)
+		}
+	}
+
+	go func() {
+		defer done()
+		result := h.executeTask(ctx, prepared, h.storeProgress(working))
+		h.saveTask(ctx, result, msgParams)
+		logger.InfoContext(ctx, "Background task finished", "task_id", taskID, "state", result.Status.State)
+	}()
+
+	h.sendSuccessResponse(c, rpcID, limitHistory(working, msgParams.Configuration.HistoryLength))
+}
+
+// finishTask records the task and sends it as the JSON-RPC result
+func (h *A2AHandler) finishTask(c *gin.Context, rpcID string, msgParams MessageParams, result TaskResult) {
+	result = h.saveTask(c.Request.Context(), result, msgParams)
+	h.sendSuccessResponse(c, rpcID, limitHistory(result, msgParams.Configuration.HistoryLength))
+}
+
+// saveTask records the task with the exchanged messages as its history and
+// returns the recorded task
+func (h *A2AHandler) saveTask(ctx context.Context, result TaskResult, msgParams MessageParams) TaskResult {
+	result.History = taskHistory(msgParams, result)
+	result = withCorrelation(result, msgParams)
+	result = withMessageMetadata(result, msgParams)
+	result = withCaller(result, ctx)
+	if err := h.config.TaskStore.Save(result); err != nil {
+		logger.WarnContext(ctx, "Failed to store task", "task_id", result.ID, "error", err)
+	}
+	h.notifyPush(ctx, result)
+	return result
+}
+
+// taskHistory is the user's message followed by the agent's reply, if any,
+// after the history of the input-required task the message resumed
+func taskHistory(msgParams MessageParams, result TaskResult) []A2AMessage {
+	var history []A2AMessage
+	if msgParams.resumed != nil {
+		history = append(history, msgParams.resumed.History...)
+	}
+
+	userMessage := msgParams.Message
+	userMessage.TaskID = result.ID
+	userMessage.ContextID = result.ContextID
+	history = append(history, redactMessage(userMessage))
+
+	if reply := result.Status.Message; reply != nil {
+		agentMessage := *reply
+		agentMessage.Kind = "message"
+		agentMessage.TaskID = result.ID
+		agentMessage.ContextID = result.ContextID
+		history = append(history, agentMessage)
+	}
+	return history
+}
+
+// limitHistory keeps the last historyLength messages of the task's history;
+// zero keeps all of them
+func limitHistory(task TaskResult, historyLength int) TaskResult {
+	if historyLength > 0 && len(task.History) > historyLength {
+		task.History = task.History[len(task.History)-historyLength:]
+	}
+	return task
+}
+
+// handleGetTask returns a stored task so clients can poll its status and artifacts
+func (h *A2AHandler) handleGetTask(c *gin.Context, rpcReq JSONRPCRequest) {
+	paramsJSON, err := json.Marshal(rpcReq.Params)
+	if err != nil {
+		h.sendErrorResponse(c, rpcReq.ID, "Failed to parse parameters", CodeInvalidParams)
+		return
+	}
+
+	var params TaskQueryParams
+	if err := json.Unmarshal(paramsJSON, &params); err != nil || params.ID == "" {
+		h.sendError(c, rpcReq.ID, invalidParam("id", "Invalid parameters: id is required"))
+		return
+	}
+	params.ID = h.resolveTaskID(params.ID)
+	if params.HistoryLength < 0 {
+		h.sendError(c, rpcReq.ID, invalidParam("historyLength", "historyLength must not be negative"))
+		return
+	}
+
+	task, ok, err := h.config.TaskStore.Get(params.ID)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Failed to load task", "task_id", params.ID, "error", err)
+		h.sendError(c, rpcReq.ID, taskStoreError(params.ID))
+		return
+	}
+	if !ok || !ownsTask(c.Request.Context(), task) {
+		h.sendError(c, rpcReq.ID, taskNotFound(params.ID))
+		return
+	}
+
+	h.sendSuccessResponse(c, rpcReq.ID, limitHistory(task, params.HistoryLength))
+}
+
+// handleCancelTask cancels an in-flight generation or a task waiting for
+// input and marks its task canceled
+func (h *A2AHandler) handleCancelTask(c *gin.Context, rpcReq JSONRPCRequest) {
+	paramsJSON, err := json.Marshal(rpcReq.Params)
+	if err != nil {
+		h.sendErrorResponse(c, rpcReq.ID, "Failed to parse parameters", CodeInvalidParams)
+		return
+	}
+
+	var params TaskIDParams
+	if err := json.Unmarshal(paramsJSON, &params); err != nil || params.ID == "" {
+		h.sendError(c, rpcReq.ID, invalidParam("id", "Invalid parameters: id is required"))
+		return
+	}
+	params.ID = h.resolveTaskID(params.ID)
+
+	task, ok, err := h.config.TaskStore.Get(params.ID)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Failed to load task", "task_id", params.ID, "error", err)
+		h.sendError(c, rpcReq.ID, taskStoreError(params.ID))
+		return
+	}
+	if !ok || !ownsTask(c.Request.Context(), task) {
+		h.sendError(c, rpcReq.ID, taskNotFound(params.ID))
+		return
+	}
+	waiting := task.Status.State == StateInputRequired
+	if isTerminalState(task.Status.State) || (!waiting && !h.running.cancel(params.ID)) {
+		h.sendError(c, rpcReq.ID, &rpcError{
+			code:    CodeTaskNotCancelable,
+			message: fmt.Sprintf("Task cannot be canceled: %s is %s", params.ID, task.Status.State),
+			data:    ErrorData{TaskID: params.ID},
+		})
+		return
+	}
+
+	logger.InfoContext(c.Request.Context(), "Canceled task", "task_id", params.ID)
+
+	task.Status = TaskStatus{
+		State:     StateCanceled,
+		Timestamp: Timestamp(),
+	}
+	if err := h.config.TaskStore.Save(task); err != nil {
+		logger.WarnContext(c.Request.Context(), "Failed to store task", "task_id", params.ID, "error", err)
+	}
+
+	h.sendSuccessResponse(c, rpcReq.ID, task)
+}
+
+// redactMessage scrubs the text parts of a message before it is stored.
+// Inline file bytes are dropped; the part keeps the file's name and type.
+func redactMessage(msg A2AMessage) A2AMessage {
+	parts := make([]MessagePart, len(msg.Parts))
+	for i, part := range msg.Parts {
+		if part.File != nil && part.File.Bytes != "" {
+			file := *part.File
+			file.Bytes = ""
+			part.File = &file
+		}
+		switch text := part.Text.(type) {
+		case string:
+			part.Text = redact.ForStore(text)
+		case *string:
+			if text != nil {
+				scrubbed := redact.ForStore(*text)
+				part.Text = &scrubbed
+			}
+		}
+		parts[i] = part
+	}
+	msg.Parts = parts
+	return msg
+}
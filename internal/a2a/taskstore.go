@@ -0,0 +1,64 @@
+package a2a
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/agentkit"
+)
+
+// DefaultTaskStoreCapacity bounds how many tasks the in-memory store keeps
+const DefaultTaskStoreCapacity = 1000
+
+// TaskStore persists task snapshots so clients can poll them with tasks/get
+type TaskStore = agentkit.TaskStore
+
+// MemoryTaskStore is a bounded in-memory TaskStore. Tasks expire ttl after
+// their last save, and the oldest task is evicted once full. Tasks are lost
+// on restart unless snapshots are written with Snapshot.
+type MemoryTaskStore struct {
+	store *memoryStore[TaskResult]
+}
+
+// NewMemoryTaskStore creates a store holding up to capacity tasks; a zero
+// ttl keeps tasks until they are evicted
+func NewMemoryTaskStore(capacity int, ttl time.Duration) *MemoryTaskStore {
+	if capacity <= 0 {
+		capacity = DefaultTaskStoreCapacity
+	}
+	return &MemoryTaskStore{store: newMemoryStore(capacity, ttl, copyTask)}
+}
+
+func (s *MemoryTaskStore) Save(task TaskResult) error {
+	s.store.set(task.ID, task)
+	return nil
+}
+
+func (s *MemoryTaskStore) Get(id string) (TaskResult, bool, error) {
+	task, ok := s.store.get(id)
+	return task, ok, nil
+}
+
+// Snapshot writes the stored tasks to path
+func (s *MemoryTaskStore) Snapshot(path string) error {
+	return s.store.snapshot(path)
+}
+
+// Restore loads tasks from a snapshot written by Snapshot and returns how
+// many were restored. A missing file restores nothing.
+func (s *MemoryTaskStore) Restore(path string) (int, error) {
+	return s.store.restore(path)
+}
+
+// UpgradeLegacyResults rewrites stored tasks from before the structured
+// artifact into the current shape and returns how many it upgraded. Run it
+// after Restore.
+func (s *MemoryTaskStore) UpgradeLegacyResults() int {
+	return s.store.update(upgradeLegacyTask)
+}
+
+// copyTask copies the slices a caller might append to or trim
+func copyTask(task TaskResult) TaskResult {
+	task.Artifacts = append([]Artifact(nil), task.Artifacts...)
+	task.History = append([]A2AMessage(nil), task.History...)
+	return task
+}
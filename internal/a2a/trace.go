@@ -0,0 +1,60 @@
+package a2a
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestid"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestlog"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans for the stages of a request
+var tracer = otel.Tracer("github.com/BerylCAtieno/customer-profiler-agent/internal/a2a")
+
+// TraceIDHeader carries the per-request trace ID, honored on input and echoed on output
+const TraceIDHeader = "X-Trace-ID"
+
+// Gin context keys used to assemble the request log record
+const (
+	ctxKeyTraceID = "a2a.traceID"
+	ctxKeyMethod  = "a2a.method"
+	ctxKeyIdea    = "a2a.idea"
+	ctxKeyOutcome = "a2a.outcome"
+	ctxKeyRequest = "a2a.request"
+)
+
+// beginTrace assigns the request a request ID, unless a transport already
+// did, and a trace ID, and returns a func that records the finished request
+// in the request log and the handler's stats. The trace ID is the request ID unless the caller sent
+// its own.
+func (h *A2AHandler) beginTrace(c *gin.Context) func() {
+	var requestID string
+	c.Request, requestID = requestid.Ensure(c.Request)
+	c.Header(requestid.Header, requestID)
+
+	traceID := c.GetHeader(TraceIDHeader)
+	if traceID == "" || len(traceID) > 128 {
+		traceID = requestID
+	}
+	c.Set(ctxKeyTraceID, traceID)
+	c.Header(TraceIDHeader, traceID)
+
+	start := time.Now()
+
+	return func() {
+		h.requests.record(c.GetString(ctxKeyOutcome))
+		if h.config.RequestLog == nil {
+			return
+		}
+		h.config.RequestLog.Add(requestlog.Record{
+			TraceID:     traceID,
+			ReceivedAt:  start.UTC(),
+			Method:      c.GetString(ctxKeyMethod),
+			IdeaSnippet: c.GetString(ctxKeyIdea),
+			DurationMS:  time.Since(start).Milliseconds(),
+			Outcome:     c.GetString(ctxKeyOutcome),
+			Request:     c.GetString(ctxKeyRequest),
+		})
+	}
+}
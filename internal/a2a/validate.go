@@ -0,0 +1,78 @@
+package a2a
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// validateMessage checks a message's kind, role and parts against the A2A
+// schema before anything reads it, naming the offending field. An omitted
+// kind is accepted for clients that predate it.
+func validateMessage(msg A2AMessage) *rpcError {
+	if msg.Kind != "" && msg.Kind != "message" {
+		return invalidParam("message.kind", fmt.Sprintf("message.kind must be \"message\", got %q", msg.Kind))
+	}
+	switch msg.Role {
+	case RoleUser, RoleAgent:
+	case "":
+		return invalidParam("message.role", "message.role is required")
+	default:
+		return invalidParam("message.role", fmt.Sprintf("message.role must be \"user\" or \"agent\", got %q", msg.Role))
+	}
+	if msg.Parts == nil {
+		return invalidParam("message.parts", "message.parts is required")
+	}
+
+	for i, part := range msg.Parts {
+		if rpcErr := validatePart(part, fmt.Sprintf("message.parts[%d]", i)); rpcErr != nil {
+			return rpcErr
+		}
+	}
+	return nil
+}
+
+// validatePart checks that a part carries the content its kind requires
+func validatePart(part MessagePart, field string) *rpcError {
+	switch part.Kind {
+	case "text":
+		switch text := part.Text.(type) {
+		case string:
+		case *string:
+			if text == nil {
+				return invalidParam(field+".text", "A text part needs a string text")
+			}
+		default:
+			return invalidParam(field+".text", "A text part needs a string text")
+		}
+	case "data":
+		switch data := part.Data.(type) {
+		case map[string]interface{}, []interface{}:
+		case string:
+			// Compressed parts and some platforms send the JSON as a string
+			if part.Metadata["encoding"] != DataEncodingGzipBase64 && !jsonContainer(data) {
+				return invalidParam(field+".data", "A data part needs an object or array data")
+			}
+		default:
+			return invalidParam(field+".data", "A data part needs an object or array data")
+		}
+	case "file":
+		if part.File == nil {
+			return invalidParam(field+".file", "A file part needs a file")
+		}
+		if (part.File.URI == "") == (part.File.Bytes == "") {
+			return invalidParam(field+".file", "A file part needs either bytes or a uri")
+		}
+	case "":
+		return invalidParam(field+".kind", field+".kind is required")
+	default:
+		return invalidParam(field+".kind", fmt.Sprintf("%s.kind must be \"text\", \"data\" or \"file\", got %q", field, part.Kind))
+	}
+	return nil
+}
+
+// jsonContainer reports whether s is a JSON object or array
+func jsonContainer(s string) bool {
+	s = strings.TrimSpace(s)
+	return (strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")) && json.Valid([]byte(s))
+}
@@ -0,0 +1,137 @@
+package a2a
+
+import (
+	"context"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/metrics"
+)
+
+// What blocking message/send requests do when every worker is busy
+const (
+	// BusyWait queues them, holding their connection until a worker is free
+	BusyWait = "wait"
+	// BusyAsync answers them right away with their submitted task, which
+	// runs once a worker is free, as if they were non-blocking
+	BusyAsync = "async"
+)
+
+// Default worker pool of the server config; a HandlerConfig without
+// workers runs every generation at once
+const (
+	DefaultWorkers     = 8
+	DefaultWorkerQueue = 32
+)
+
+// BusyRetryAfter is the Retry-After of requests refused because every
+// worker is busy and the queue is full
+const BusyRetryAfter = 5 * time.Second
+
+// workerPool bounds how many generations run at once. Generations beyond
+// that wait in a bounded queue; a nil pool runs every generation at once.
+type workerPool struct {
+	workers chan struct{}
+	queue   chan struct{}
+}
+
+// newWorkerPool returns a pool of workers generations with room for queue
+// more to wait, or nil, for no limit, if workers isn't positive
+func newWorkerPool(workers, queue int) *workerPool {
+	if workers <= 0 {
+		return nil
+	}
+	if queue < 0 {
+		queue = 0
+	}
+	return &workerPool{workers: make(chan struct{}, workers), queue: make(chan struct{}, queue)}
+}
+
+// workerTicket is a task's claim on the pool: a worker, or while queued a
+// place in the queue. The nil ticket of an unlimited pool is always held.
+type workerTicket struct {
+	pool   *workerPool
+	queued bool
+}
+
+// admit takes a free worker, or else a place in the queue, and reports
+// false if both are taken
+func (p *workerPool) admit() (*workerTicket, bool) {
+	if p == nil {
+		return nil, true
+	}
+	defer p.observe()
+	select {
+	case p.workers <- struct{}{}:
+		return &workerTicket{pool: p}, true
+	default:
+	}
+	select {
+	case p.queue <- struct{}{}:
+		return &workerTicket{pool: p, queued: true}, true
+	default:
+		return nil, false
+	}
+}
+
+// observe publishes the pool's usage
+func (p *workerPool) observe() {
+	metrics.SetGenerationWorkers(len(p.workers), len(p.queue))
+}
+
+// waiting reports whether the ticket is still queued for a worker
+func (t *workerTicket) waiting() bool {
+	return t != nil && t.pool != nil && t.queued
+}
+
+// wait blocks until a queued ticket gets a worker, giving up its place
+// in the queue if ctx ends first
+func (t *workerTicket) wait(ctx context.Context) error {
+	if !t.waiting() {
+		return nil
+	}
+	p := t.pool
+	defer p.observe()
+	select {
+	case p.workers <- struct{}{}:
+		<-p.queue
+		t.queued = false
+		return nil
+	case <-ctx.Done():
+		<-p.queue
+		t.pool = nil
+		return ctx.Err()
+	}
+}
+
+// release frees the ticket's worker or place in the queue; releasing it
+// again does nothing
+func (t *workerTicket) release() {
+	if t == nil || t.pool == nil {
+		return
+	}
+	p := t.pool
+	t.pool = nil
+	if t.queued {
+		<-p.queue
+	} else {
+		<-p.workers
+	}
+	p.observe()
+}
+
+// admitTask claims a worker for a prepared task, or a place in the queue,
+// which executeTask waits in. With both taken it returns a 429 rate limit
+// error; the client should retry after BusyRetryAfter.
+func (h *A2AHandler) admitTask(ctx context.Context, method string, task *preparedTask) *rpcError {
+	ticket, ok := h.workers.admit()
+	if !ok {
+		metrics.CountGenerationRejected()
+		logger.WarnContext(ctx, "Refused generation: every worker is busy and the queue is full", "method", method, "task_id", task.taskID)
+		return rateLimited(method, "The server is busy", BusyRetryAfter)
+	}
+	if ticket.waiting() {
+		logger.InfoContext(ctx, "Every worker is busy; queued task", "task_id", task.taskID)
+	}
+	task.worker = ticket
+	return nil
+}
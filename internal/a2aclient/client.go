@@ -0,0 +1,201 @@
+// Package a2aclient calls other A2A agents over JSON-RPC: it sends
+// messages, polls tasks and follows streamed task events.
+package a2aclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestid"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/tracing"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+	"github.com/google/uuid"
+)
+
+// DefaultTimeout bounds each request of a client without its own HTTP
+// client. Streams are not bounded by it.
+const DefaultTimeout = 60 * time.Second
+
+// DefaultPollInterval is how often PollTask asks for a task's state
+const DefaultPollInterval = 2 * time.Second
+
+// ErrHTTPStatus is wrapped by errors for responses other than 200 OK
+var ErrHTTPStatus = errors.New("unexpected HTTP status")
+
+// Config describes the agent a Client calls
+type Config struct {
+	// URL is the agent's JSON-RPC endpoint, e.g. https://host/a2a/profiler
+	URL string
+	// HTTPClient sends the requests; nil uses one with DefaultTimeout for
+	// calls and no timeout for streams
+	HTTPClient *http.Client
+	// Header is added to every request, e.g. Authorization or X-Tenant-ID
+	Header http.Header
+}
+
+// Client calls one A2A agent. It is safe for concurrent use.
+type Client struct {
+	url    string
+	call   *http.Client
+	stream *http.Client
+	header http.Header
+}
+
+func New(config Config) *Client {
+	client := &Client{url: config.URL, header: config.Header.Clone()}
+	if config.HTTPClient != nil {
+		client.call = config.HTTPClient
+		client.stream = config.HTTPClient
+	} else {
+		client.call = &http.Client{Timeout: DefaultTimeout}
+		client.stream = &http.Client{}
+	}
+	return client
+}
+
+// Call sends one JSON-RPC request and decodes its result into result. A
+// JSON-RPC error is returned as an *a2aerrors.Error.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	resp, err := c.post(ctx, c.call, method, params, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Result json.RawMessage  `json:"result"`
+		Error  *a2aerrors.Error `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("%s: failed to decode response: %w", method, err)
+	}
+	if envelope.Error != nil {
+		return envelope.Error
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return fmt.Errorf("%s: failed to decode result: %w", method, err)
+	}
+	return nil
+}
+
+// SendMessage sends message/send and returns the task the agent answered
+// with. The task is finished unless the configuration asked not to block.
+func (c *Client) SendMessage(ctx context.Context, params a2a.MessageParams) (*a2a.TaskResult, error) {
+	var task a2a.TaskResult
+	if err := c.Call(ctx, "message/send", params, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetTask fetches a task with tasks/get; a historyLength of zero returns
+// all of its history
+func (c *Client) GetTask(ctx context.Context, taskID string, historyLength int) (*a2a.TaskResult, error) {
+	var task a2a.TaskResult
+	params := a2a.TaskQueryParams{ID: taskID, HistoryLength: historyLength}
+	if err := c.Call(ctx, "tasks/get", params, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CancelTask cancels a task with tasks/cancel and returns it
+func (c *Client) CancelTask(ctx context.Context, taskID string) (*a2a.TaskResult, error) {
+	var task a2a.TaskResult
+	if err := c.Call(ctx, "tasks/cancel", a2a.TaskIDParams{ID: taskID}, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// PollTask fetches a task every interval until it is finished or waiting
+// for input, and returns it. A zero interval uses DefaultPollInterval.
+func (c *Client) PollTask(ctx context.Context, taskID string, interval time.Duration) (*a2a.TaskResult, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		task, err := c.GetTask(ctx, taskID, 0)
+		if err != nil {
+			return nil, err
+		}
+		if a2a.IsTerminalState(task.Status.State) || task.Status.State == a2a.StateInputRequired {
+			return task, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// post sends a JSON-RPC request with a new id and checks the HTTP status
+func (c *Client) post(ctx context.Context, client *http.Client, method string, params interface{}, accept string) (*http.Response, error) {
+	body, err := json.Marshal(a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      uuid.New().String(),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to encode request: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	// Calls made for a request share its ID and trace, so the called
+	// agent's logs and spans can be matched with ours
+	if id := requestid.FromContext(ctx); id != "" && req.Header.Get(requestid.Header) == "" {
+		req.Header.Set(requestid.Header, id)
+	}
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, statusError(method, resp)
+	}
+	return resp, nil
+}
+
+// statusError explains a response other than 200 OK, preferring the
+// JSON-RPC error it carries, as unauthorized requests do
+func statusError(method string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	var envelope struct {
+		Error *a2aerrors.Error `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil {
+		return envelope.Error
+	}
+	if len(body) > 512 {
+		body = body[:512]
+	}
+	return fmt.Errorf("%s: %w: HTTP %d: %s", method, ErrHTTPStatus, resp.StatusCode, bytes.TrimSpace(body))
+}
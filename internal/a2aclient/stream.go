@@ -0,0 +1,153 @@
+package a2aclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+)
+
+// ErrStopStream can be returned by a stream handler to stop reading
+// without the stream call failing
+var ErrStopStream = errors.New("stop stream")
+
+// Event is one Server-Sent Event of a streamed task; exactly one of
+// Status and Artifact is set
+type Event struct {
+	// ID is the SSE id, which resubscribing with lastEventID resumes after;
+	// empty for events replayed without one
+	ID       string
+	Status   *a2a.TaskStatusUpdateEvent
+	Artifact *a2a.TaskArtifactUpdateEvent
+}
+
+// Final reports whether the event is the last of its stream
+func (e Event) Final() bool {
+	return e.Status != nil && e.Status.Final
+}
+
+// Stream sends message/stream and calls handle for every event until the
+// final status update. A JSON-RPC error in the stream is returned as an
+// *a2aerrors.Error.
+func (c *Client) Stream(ctx context.Context, params a2a.MessageParams, handle func(Event) error) error {
+	return c.follow(ctx, "message/stream", params, "", handle)
+}
+
+// Resubscribe reattaches to a streamed task with tasks/resubscribe. A
+// non-empty lastEventID skips the events up to and including it.
+func (c *Client) Resubscribe(ctx context.Context, taskID, lastEventID string, handle func(Event) error) error {
+	return c.follow(ctx, "tasks/resubscribe", a2a.TaskIDParams{ID: taskID}, lastEventID, handle)
+}
+
+func (c *Client) follow(ctx context.Context, method string, params interface{}, lastEventID string, handle func(Event) error) error {
+	client := *c
+	if lastEventID != "" {
+		client.header = c.header.Clone()
+		if client.header == nil {
+			client.header = make(map[string][]string)
+		}
+		client.header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := client.post(ctx, c.stream, method, params, "text/event-stream")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Requests rejected before the stream starts get a plain JSON-RPC error
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		var envelope struct {
+			Error *a2aerrors.Error `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || envelope.Error == nil {
+			return fmt.Errorf("%s: response is not an event stream", method)
+		}
+		return envelope.Error
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var id string
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			event, err := decodeEvent(id, data.String())
+			id = ""
+			data.Reset()
+			var rpcErr *a2aerrors.Error
+			if errors.As(err, &rpcErr) {
+				return rpcErr
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", method, err)
+			}
+			if err := handle(event); err != nil {
+				if errors.Is(err, ErrStopStream) {
+					return nil
+				}
+				return err
+			}
+			if event.Final() {
+				return nil
+			}
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return fmt.Errorf("%s: stream ended before the final event", method)
+}
+
+// decodeEvent decodes the JSON-RPC response carried by one SSE event
+func decodeEvent(id, data string) (Event, error) {
+	var envelope struct {
+		Result json.RawMessage  `json:"result"`
+		Error  *a2aerrors.Error `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(data), &envelope); err != nil {
+		return Event{}, fmt.Errorf("failed to decode event: %w", err)
+	}
+	if envelope.Error != nil {
+		return Event{}, envelope.Error
+	}
+
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(envelope.Result, &kind); err != nil {
+		return Event{}, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	event := Event{ID: id}
+	switch kind.Kind {
+	case a2a.KindStatusUpdate:
+		event.Status = &a2a.TaskStatusUpdateEvent{}
+		err := json.Unmarshal(envelope.Result, event.Status)
+		return event, err
+	case a2a.KindArtifactUpdate:
+		event.Artifact = &a2a.TaskArtifactUpdateEvent{}
+		err := json.Unmarshal(envelope.Result, event.Artifact)
+		return event, err
+	default:
+		return Event{}, fmt.Errorf("unknown event kind %q", kind.Kind)
+	}
+}
@@ -0,0 +1,307 @@
+// Package a2agrpc offers the profiler's A2A interface over gRPC, as the
+// A2AService of profiler.proto. Every call is translated to the JSON-RPC
+// request it mirrors and served by the same handler as the HTTP endpoint,
+// so both transports share the task and profiler core.
+package a2agrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName is the full name of the gRPC service
+const ServiceName = "profiler.a2a.v1.A2AService"
+
+// unaryMethods map the service's unary RPCs to JSON-RPC methods
+var unaryMethods = map[string]string{
+	"SendMessage":                   "message/send",
+	"GetTask":                       "tasks/get",
+	"CancelTask":                    "tasks/cancel",
+	"SubmitFeedback":                "tasks/feedback",
+	"SetTaskPushNotificationConfig": "tasks/pushNotificationConfig/set",
+	"GetTaskPushNotificationConfig": "tasks/pushNotificationConfig/get",
+	"GetAuthenticatedExtendedCard":  "agent/getAuthenticatedExtendedCard",
+}
+
+// streamMethods map the service's server-streaming RPCs to JSON-RPC methods
+var streamMethods = map[string]string{
+	"SendStreamingMessage": "message/stream",
+	"TaskSubscription":     "tasks/resubscribe",
+}
+
+// Server implements A2AService on top of a JSON-RPC handler
+type Server struct {
+	rpc http.Handler
+}
+
+// New serves the gRPC calls with rpc, the JSON-RPC handler of the HTTP
+// endpoint (a2a.A2AHandler.RPCHandler)
+func New(rpc http.Handler) *Server {
+	return &Server{rpc: rpc}
+}
+
+// Register adds the service to a gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	desc := grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*interface{})(nil),
+		Metadata:    "profiler.proto",
+	}
+	for name, method := range unaryMethods {
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: name,
+			Handler:    s.unaryHandler(name, method),
+		})
+	}
+	for name, method := range streamMethods {
+		desc.Streams = append(desc.Streams, grpc.StreamDesc{
+			StreamName:    name,
+			Handler:       s.streamHandler(method),
+			ServerStreams: true,
+		})
+	}
+	registrar.RegisterService(&desc, s)
+}
+
+func (s *Server) unaryHandler(name, method string) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		params := new(structpb.Struct)
+		if err := dec(params); err != nil {
+			return nil, err
+		}
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.call(ctx, method, req.(*structpb.Struct))
+		}
+		if interceptor == nil {
+			return call(ctx, params)
+		}
+		info := &grpc.UnaryServerInfo{Server: s, FullMethod: "/" + ServiceName + "/" + name}
+		return interceptor(ctx, params, info, call)
+	}
+}
+
+func (s *Server) streamHandler(method string) grpc.StreamHandler {
+	return func(_ interface{}, stream grpc.ServerStream) error {
+		params := new(structpb.Struct)
+		if err := stream.RecvMsg(params); err != nil {
+			return err
+		}
+		req, err := newRequest(stream.Context(), method, params, "text/event-stream")
+		if err != nil {
+			return err
+		}
+
+		w := &eventWriter{stream: stream, header: make(http.Header)}
+		s.rpc.ServeHTTP(w, req)
+		return w.finish()
+	}
+}
+
+// call serves a unary RPC and returns its result
+func (s *Server) call(ctx context.Context, method string, params *structpb.Struct) (*structpb.Struct, error) {
+	req, err := newRequest(ctx, method, params, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	w := &responseWriter{header: make(http.Header)}
+	s.rpc.ServeHTTP(w, req)
+	return decodeResponse(w.body.Bytes())
+}
+
+// newRequest builds the JSON-RPC request of a call, with the call's
+// metadata as headers
+func newRequest(ctx context.Context, method string, params *structpb.Struct, accept string) (*http.Request, error) {
+	body, err := json.Marshal(a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      uuid.New().String(),
+		Method:  method,
+		Params:  params.AsMap(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to encode params: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/a2a/profiler", bytes.NewReader(body))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		if strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-") || key == "content-type" {
+			continue
+		}
+		req.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			req.RemoteAddr = p.Addr.String()
+		}
+		// The handler authenticates client certificates from the TLS state
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			req.TLS = &info.State
+		}
+	}
+	return req, nil
+}
+
+// decodeResponse returns the result of a JSON-RPC response, or its error
+// as a gRPC status
+func decodeResponse(body []byte) (*structpb.Struct, error) {
+	var envelope struct {
+		Result json.RawMessage  `json:"result"`
+		Error  *a2aerrors.Error `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode response: %v", err)
+	}
+	if envelope.Error != nil {
+		return nil, statusError(envelope.Error)
+	}
+
+	result := new(structpb.Struct)
+	if err := protojson.Unmarshal(envelope.Result, result); err != nil {
+		return nil, status.Errorf(codes.Internal, "result is not an object: %v", err)
+	}
+	return result, nil
+}
+
+// statusCodes map JSON-RPC error codes to gRPC codes; others are Internal
+var statusCodes = map[a2aerrors.Code]codes.Code{
+	a2aerrors.CodeParseError:                   codes.InvalidArgument,
+	a2aerrors.CodeInvalidRequest:               codes.InvalidArgument,
+	a2aerrors.CodeInvalidParams:                codes.InvalidArgument,
+	a2aerrors.CodeContentTypeNotSupported:      codes.InvalidArgument,
+	a2aerrors.CodeMethodNotFound:               codes.Unimplemented,
+	a2aerrors.CodeUnsupportedOperation:         codes.Unimplemented,
+	a2aerrors.CodePushNotificationNotSupported: codes.Unimplemented,
+	a2aerrors.CodeTaskNotFound:                 codes.NotFound,
+	a2aerrors.CodeTaskNotCancelable:            codes.FailedPrecondition,
+	a2aerrors.CodeExtendedCardNotConfigured:    codes.FailedPrecondition,
+	a2aerrors.CodeUnauthorized:                 codes.Unauthenticated,
+	a2aerrors.CodeMaintenance:                  codes.Unavailable,
+	a2aerrors.CodeRateLimited:                  codes.ResourceExhausted,
+}
+
+// statusError converts a JSON-RPC error to a gRPC status. The JSON-RPC
+// error object is attached as a Struct detail, so clients keep its code
+// and data.
+func statusError(rpcErr *a2aerrors.Error) error {
+	code, ok := statusCodes[rpcErr.Code]
+	if !ok {
+		code = codes.Internal
+	}
+	st := status.New(code, rpcErr.Message)
+
+	raw, err := json.Marshal(rpcErr)
+	if err != nil {
+		return st.Err()
+	}
+	detail := new(structpb.Struct)
+	if err := protojson.Unmarshal(raw, detail); err != nil {
+		return st.Err()
+	}
+	if withDetail, err := st.WithDetails(protoadapt.MessageV1Of(detail)); err == nil {
+		st = withDetail
+	}
+	return st.Err()
+}
+
+// responseWriter records the response of a unary call
+type responseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header         { return w.header }
+func (w *responseWriter) WriteHeader(int)             {}
+func (w *responseWriter) Write(p []byte) (int, error) { return w.body.Write(p) }
+func (w *responseWriter) Flush()                      {}
+
+// eventWriter is the response of a streamed call. Each Server-Sent Event
+// is sent on the gRPC stream as soon as the handler flushes it.
+type eventWriter struct {
+	stream grpc.ServerStream
+	header http.Header
+	buf    bytes.Buffer
+	// err is the first failure, after which events are dropped
+	err error
+}
+
+func (w *eventWriter) Header() http.Header         { return w.header }
+func (w *eventWriter) WriteHeader(int)             {}
+func (w *eventWriter) Write(p []byte) (int, error) { return w.buf.Write(p) }
+
+func (w *eventWriter) Flush() {
+	if !strings.HasPrefix(w.header.Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	for w.err == nil {
+		end := bytes.Index(w.buf.Bytes(), []byte("\n\n"))
+		if end < 0 {
+			return
+		}
+		event := string(w.buf.Next(end + 2))
+		w.err = w.send(event)
+	}
+}
+
+// send forwards the data of one SSE event; events without data, such as
+// keep-alive comments, are skipped
+func (w *eventWriter) send(event string) error {
+	var data []string
+	for _, line := range strings.Split(event, "\n") {
+		if strings.HasPrefix(line, "data:") {
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	result, err := decodeResponse([]byte(strings.Join(data, "\n")))
+	if err != nil {
+		return err
+	}
+	return w.stream.SendMsg(result)
+}
+
+// finish forwards what is left once the handler returns. Requests rejected
+// before the stream started get a plain JSON-RPC error.
+func (w *eventWriter) finish() error {
+	if !strings.HasPrefix(w.header.Get("Content-Type"), "text/event-stream") {
+		if _, err := decodeResponse(w.buf.Bytes()); err != nil {
+			return err
+		}
+		return status.Error(codes.Internal, "response is not an event stream")
+	}
+	w.Flush()
+	if w.err == nil && len(bytes.TrimSpace(w.buf.Bytes())) > 0 {
+		w.err = w.send(w.buf.String())
+	}
+	if w.err != nil {
+		if _, ok := status.FromError(w.err); !ok {
+			return status.Error(codes.Unavailable, fmt.Sprintf("failed to send event: %v", w.err))
+		}
+	}
+	return w.err
+}
@@ -0,0 +1,181 @@
+// Package a2aws offers the profiler's JSON-RPC interface over WebSocket,
+// for browser clients that can't consume Server-Sent Events or poll. Each
+// text message is one JSON-RPC request or batch. It is served by the same
+// handler as the HTTP endpoint, and its responses come back as text
+// messages; a streaming method answers with one message per event.
+// Requests on a connection run concurrently, so a client can cancel a task
+// while it streams.
+package a2aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestid"
+	"golang.org/x/net/websocket"
+)
+
+var logger = logging.For("a2aws")
+
+// MaxMessageSize is the largest request message accepted
+const MaxMessageSize = 10 << 20
+
+// Server serves JSON-RPC over WebSocket connections
+type Server struct {
+	rpc http.Handler
+	ws  websocket.Server
+}
+
+// New serves the WebSocket requests with rpc, the JSON-RPC handler of the
+// HTTP endpoint (a2a.A2AHandler.RPCHandler). Connections from browsers are
+// accepted if allowOrigin allows their Origin header, or from any origin
+// when allowOrigin is nil; clients that send no Origin are always accepted.
+func New(rpc http.Handler, allowOrigin func(origin string) bool) *Server {
+	s := &Server{rpc: rpc}
+	s.ws = websocket.Server{
+		Handshake: func(_ *websocket.Config, req *http.Request) error {
+			origin := req.Header.Get("Origin")
+			if origin == "" || allowOrigin == nil || allowOrigin(origin) {
+				return nil
+			}
+			logger.WarnContext(req.Context(), "Refused WebSocket connection from a disallowed origin", "origin", origin)
+			return errors.New("origin not allowed")
+		},
+		Handler: s.serveConn,
+	}
+	return s
+}
+
+// ServeHTTP upgrades the request to a WebSocket connection
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.ws.ServeHTTP(w, req)
+}
+
+// conn is one client connection. Responses of concurrent requests are
+// written one message at a time.
+type conn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+}
+
+func (c *conn) send(message []byte) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	// A failed write means the client went away; reading will notice
+	_ = websocket.Message.Send(c.ws, string(message))
+}
+
+// serveConn reads requests until the client disconnects, then cancels the
+// requests still running
+func (s *Server) serveConn(ws *websocket.Conn) {
+	ws.MaxPayloadBytes = MaxMessageSize
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+
+	c := &conn{ws: ws}
+	logger.InfoContext(ctx, "WebSocket client connected", "remote_addr", ws.Request().RemoteAddr)
+
+	var wg sync.WaitGroup
+	for {
+		var message string
+		if err := websocket.Message.Receive(ws, &message); err != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveMessage(ctx, c, []byte(message))
+		}()
+	}
+
+	cancel()
+	wg.Wait()
+	logger.InfoContext(ctx, "WebSocket client disconnected", "remote_addr", ws.Request().RemoteAddr)
+}
+
+// serveMessage runs one request with the headers of the connection's
+// upgrade request, such as X-Tenant-ID
+func (s *Server) serveMessage(ctx context.Context, c *conn, message []byte) {
+	upgrade := c.ws.Request()
+	// Each message is a request of its own, with its own request ID
+	ctx = requestid.NewContext(ctx, requestid.New())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/a2a/profiler", bytes.NewReader(message))
+	if err != nil {
+		return
+	}
+	req.Header = upgrade.Header.Clone()
+	for _, header := range []string{"Connection", "Upgrade", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions", requestid.Header} {
+		req.Header.Del(header)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = upgrade.RemoteAddr
+	req.TLS = upgrade.TLS
+
+	w := &responseWriter{conn: c, header: make(http.Header)}
+	s.rpc.ServeHTTP(w, req)
+	w.finish()
+}
+
+// responseWriter sends a response to the client: a plain JSON-RPC response
+// as one message, or an event stream as one message per event, each as soon
+// as the handler flushes it
+type responseWriter struct {
+	conn   *conn
+	header http.Header
+	buf    bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header         { return w.header }
+func (w *responseWriter) WriteHeader(int)             {}
+func (w *responseWriter) Write(p []byte) (int, error) { return w.buf.Write(p) }
+
+func (w *responseWriter) streaming() bool {
+	return strings.HasPrefix(w.header.Get("Content-Type"), "text/event-stream")
+}
+
+func (w *responseWriter) Flush() {
+	if !w.streaming() {
+		return
+	}
+	for {
+		end := bytes.Index(w.buf.Bytes(), []byte("\n\n"))
+		if end < 0 {
+			return
+		}
+		w.sendEvent(w.buf.Next(end + 2))
+	}
+}
+
+// sendEvent sends the data of one SSE event; events without data, such as
+// keep-alive comments, are skipped
+func (w *responseWriter) sendEvent(event []byte) {
+	var data [][]byte
+	for _, line := range bytes.Split(event, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("data:")) {
+			data = append(data, bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" ")))
+		}
+	}
+	if len(data) > 0 {
+		w.conn.send(bytes.Join(data, []byte("\n")))
+	}
+}
+
+// finish sends what is left once the handler returns. Notifications have
+// no response, so nothing is sent for them.
+func (w *responseWriter) finish() {
+	if w.streaming() {
+		w.Flush()
+		if len(bytes.TrimSpace(w.buf.Bytes())) > 0 {
+			w.sendEvent(w.buf.Bytes())
+		}
+		return
+	}
+	if body := bytes.TrimSpace(w.buf.Bytes()); len(body) > 0 {
+		w.conn.send(body)
+	}
+}
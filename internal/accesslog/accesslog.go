@@ -0,0 +1,78 @@
+// Package accesslog writes one structured record per HTTP request, with the
+// fields log pipelines such as Loki or Datadog index on, in place of Gin's
+// plain-text access log.
+package accesslog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+var logger = logging.For("access")
+
+type contextKey struct{}
+
+// tasks collects the IDs of the tasks a request touched; a batch touches
+// several, on its own goroutines
+type tasks struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// AddTask records that the request of ctx created or returned the task id.
+// It does nothing outside a request served through Middleware.
+func AddTask(ctx context.Context, id string) {
+	t, ok := ctx.Value(contextKey{}).(*tasks)
+	if !ok || id == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, seen := range t.ids {
+		if seen == id {
+			return
+		}
+	}
+	t.ids = append(t.ids, id)
+}
+
+// Middleware logs each request once served, with its method, path, status,
+// latency, request and response sizes, client IP and the tasks it touched.
+// Records carry the request ID and authenticated caller through the request
+// context, as every log record does.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		t := &tasks{}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, t))
+
+		c.Next()
+
+		args := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"latency_ms", time.Since(start).Milliseconds(),
+			"bytes_in", c.Request.ContentLength,
+			"bytes_out", max(c.Writer.Size(), 0),
+			"client_ip", c.ClientIP(),
+		}
+		t.mu.Lock()
+		switch len(t.ids) {
+		case 0:
+		case 1:
+			args = append(args, "task_id", t.ids[0])
+		default:
+			args = append(args, "task_ids", t.ids)
+		}
+		t.mu.Unlock()
+		if len(c.Errors) > 0 {
+			args = append(args, "error", c.Errors.String())
+		}
+		logger.InfoContext(c.Request.Context(), "Request", args...)
+	}
+}
@@ -0,0 +1,129 @@
+// Package admin serves the operator API under /admin: live stats, config
+// reload, response cache flush and the request logging switch, so
+// operators can intervene without restarting the server.
+package admin
+
+import (
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestlog"
+	"github.com/gin-gonic/gin"
+)
+
+var logger = logging.For("admin")
+
+// Cache is the response cache of the profile generator
+type Cache interface {
+	CacheStats() (profiler.CacheStats, bool)
+	FlushCache() (int, bool)
+}
+
+// Config wires the API to the parts of the server it reports on and acts on
+type Config struct {
+	// Token is the bearer token operators present; empty disables the API
+	Token   string
+	Handler *a2a.A2AHandler
+	Cache   Cache
+	// RequestLogging switches request logging on and off
+	RequestLogging *atomic.Bool
+	// Reload re-reads the config and applies the settings that can change
+	// while running, returning their names
+	Reload func() ([]string, error)
+}
+
+type Handler struct {
+	config  Config
+	started time.Time
+}
+
+func NewHandler(config Config) *Handler {
+	return &Handler{config: config, started: time.Now()}
+}
+
+// Register adds the API's routes under /admin. Every route answers 404
+// while the token is unset.
+func (h *Handler) Register(router *gin.Engine) {
+	group := router.Group("/admin", func(c *gin.Context) {
+		if !requestlog.Authorize(c, h.config.Token) {
+			c.Abort()
+		}
+	})
+	group.GET("/stats", h.serveStats)
+	group.POST("/reload", h.serveReload)
+	group.POST("/cache/flush", h.serveCacheFlush)
+	group.GET("/request-logging", h.serveRequestLogging)
+	group.PUT("/request-logging", h.serveRequestLogging)
+}
+
+// stats is the body of GET /admin/stats
+type stats struct {
+	UptimeSeconds  int64                `json:"uptimeSeconds"`
+	Goroutines     int                  `json:"goroutines"`
+	Generation     a2a.Stats            `json:"generation"`
+	Cache          *profiler.CacheStats `json:"cache"`
+	RequestLogging bool                 `json:"requestLogging"`
+	LogLevel       string               `json:"logLevel"`
+}
+
+// serveStats reports the worker pool's queue, the response cache and the
+// recent and total error rates
+func (h *Handler) serveStats(c *gin.Context) {
+	report := stats{
+		UptimeSeconds:  int64(time.Since(h.started).Seconds()),
+		Goroutines:     runtime.NumGoroutine(),
+		Generation:     h.config.Handler.Stats(),
+		RequestLogging: h.config.RequestLogging.Load(),
+		LogLevel:       logging.Level().String(),
+	}
+	if cache, ok := h.config.Cache.CacheStats(); ok {
+		report.Cache = &cache
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// serveReload re-reads the config. An invalid config is refused and
+// nothing changes; settings that only apply at startup are left as they
+// are.
+func (h *Handler) serveReload(c *gin.Context) {
+	applied, err := h.config.Reload()
+	if err != nil {
+		logger.WarnContext(c.Request.Context(), "Refused config reload", "audit", true, "client_ip", c.ClientIP(), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logger.InfoContext(c.Request.Context(), "Config reloaded", "audit", true, "client_ip", c.ClientIP(), "applied", applied)
+	c.JSON(http.StatusOK, gin.H{"applied": applied})
+}
+
+// serveCacheFlush empties the response cache
+func (h *Handler) serveCacheFlush(c *gin.Context) {
+	flushed, ok := h.config.Cache.FlushCache()
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "The response cache is disabled"})
+		return
+	}
+	logger.InfoContext(c.Request.Context(), "Response cache flushed", "audit", true, "client_ip", c.ClientIP(), "entries", flushed)
+	c.JSON(http.StatusOK, gin.H{"flushed": flushed})
+}
+
+// serveRequestLogging reports (GET) or switches (PUT) request logging
+func (h *Handler) serveRequestLogging(c *gin.Context) {
+	if c.Request.Method == http.MethodPut {
+		var body struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Enabled == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": `Expected {"enabled": true} or {"enabled": false}`})
+			return
+		}
+		h.config.RequestLogging.Store(*body.Enabled)
+		logger.InfoContext(c.Request.Context(), "Request logging switched", "audit", true, "client_ip", c.ClientIP(), "enabled", *body.Enabled)
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": h.config.RequestLogging.Load()})
+}
@@ -1,19 +1,9 @@
 package agent
 
-import (
-	_ "embed"
-	"fmt"
-)
+// version is the agent version published in the agent card
+const version = "1.1.0"
 
-//go:embed agent.json
-var AgentCardData []byte
-
-func LoadAgentCard() error {
-	if len(AgentCardData) == 0 {
-		return fmt.Errorf("agent card is empty")
-	}
-	return nil
+// Version returns the agent version declared in the agent card
+func Version() string {
+	return version
 }
-
-// TODO: update times in json
-// TODO: update domains
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+)
+
+// BrandingExtension is the URI of the extension that carries a tenant's
+// accent color and logo in its branded agent card
+const BrandingExtension = "https://github.com/BerylCAtieno/customer-profiler-agent/extensions/branding/v1"
+
+// accentColorPattern matches #rgb and #rrggbb colors
+var accentColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Branding white-labels the agent for a tenant that runs it on behalf of
+// its own clients. Empty fields keep the agent's own values.
+type Branding struct {
+	// Name replaces the agent's name on the card and in text output
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// LogoURL is an absolute http(s) URL of the tenant's logo
+	LogoURL string `json:"logoUrl,omitempty"`
+	// AccentColor is a hex color such as "#0a7cff" for renderers to use
+	AccentColor string `json:"accentColor,omitempty"`
+	// Organization and OrganizationURL replace the card's provider
+	Organization    string `json:"organization,omitempty"`
+	OrganizationURL string `json:"organizationUrl,omitempty"`
+}
+
+// Validate checks that the branding names the agent and that its logo and
+// color are usable
+func (b Branding) Validate() error {
+	if b.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	for field, raw := range map[string]string{"logoUrl": b.LogoURL, "organizationUrl": b.OrganizationURL} {
+		if raw == "" {
+			continue
+		}
+		if parsed, err := url.Parse(raw); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s must be an absolute http(s) URL", field)
+		}
+	}
+	if b.AccentColor != "" && !accentColorPattern.MatchString(b.AccentColor) {
+		return fmt.Errorf("accentColor must be a hex color like #0a7cff")
+	}
+	if (b.Organization == "") != (b.OrganizationURL == "") {
+		return fmt.Errorf("organization and organizationUrl must be set together")
+	}
+	return nil
+}
+
+// Apply returns a copy of card carrying the branding
+func (b Branding) Apply(card AgentCard) AgentCard {
+	card.Name = b.Name
+	if b.Description != "" {
+		card.Description = b.Description
+	}
+	if b.LogoURL != "" {
+		card.IconURL = b.LogoURL
+	}
+	if b.Organization != "" {
+		card.Provider = &AgentProvider{Organization: b.Organization, URL: b.OrganizationURL}
+	}
+
+	params := map[string]interface{}{}
+	if b.AccentColor != "" {
+		params["accentColor"] = b.AccentColor
+	}
+	if b.LogoURL != "" {
+		params["logoUrl"] = b.LogoURL
+	}
+	if len(params) > 0 {
+		card.Capabilities.Extensions = append(append([]AgentExtension(nil), card.Capabilities.Extensions...), AgentExtension{
+			URI:         BrandingExtension,
+			Description: "Accent color and logo for rendering this agent's output",
+			Params:      params,
+		})
+	}
+	return card
+}
+
+// LoadBranding reads tenant brandings, keyed by tenant ID, from a JSON file
+func LoadBranding(path string) (map[string]Branding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branding: %w", err)
+	}
+
+	var brandings map[string]Branding
+	if err := json.Unmarshal(data, &brandings); err != nil {
+		return nil, fmt.Errorf("failed to parse branding: %w", err)
+	}
+	for tenant, branding := range brandings {
+		if err := branding.Validate(); err != nil {
+			return nil, fmt.Errorf("branding of tenant %q: %w", tenant, err)
+		}
+	}
+	return brandings, nil
+}
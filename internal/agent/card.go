@@ -0,0 +1,251 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ProtocolVersion is the A2A protocol version the agent implements
+const ProtocolVersion = "0.3.0"
+
+// Skill IDs of the public card; messages pick one with the skillId metadata
+const (
+	SkillProfileGeneration = "profile-generation"
+	SkillPersonaRefinement = "persona-refinement"
+	SkillJourneyMapping    = "journey-mapping"
+)
+
+// AgentCard describes the agent to A2A clients, following the A2A
+// AgentCard schema
+type AgentCard struct {
+	ProtocolVersion    string                    `json:"protocolVersion"`
+	Name               string                    `json:"name"`
+	Description        string                    `json:"description"`
+	URL                string                    `json:"url"`
+	PreferredTransport string                    `json:"preferredTransport,omitempty"`
+	Version            string                    `json:"version"`
+	Provider           *AgentProvider            `json:"provider,omitempty"`
+	IconURL            string                    `json:"iconUrl,omitempty"`
+	DocumentationURL   string                    `json:"documentationUrl,omitempty"`
+	Capabilities       AgentCapabilities         `json:"capabilities"`
+	SecuritySchemes    map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+	Security           []map[string][]string     `json:"security,omitempty"`
+	DefaultInputModes  []string                  `json:"defaultInputModes"`
+	DefaultOutputModes []string                  `json:"defaultOutputModes"`
+	Skills             []AgentSkill              `json:"skills"`
+	// SupportsAuthenticatedExtendedCard advertises agent/getAuthenticatedExtendedCard
+	SupportsAuthenticatedExtendedCard bool `json:"supportsAuthenticatedExtendedCard,omitempty"`
+}
+
+// AgentProvider is the organization behind the agent
+type AgentProvider struct {
+	Organization string `json:"organization"`
+	URL          string `json:"url"`
+}
+
+// AgentCapabilities lists the optional protocol features the agent supports
+type AgentCapabilities struct {
+	Streaming              bool             `json:"streaming"`
+	PushNotifications      bool             `json:"pushNotifications"`
+	StateTransitionHistory bool             `json:"stateTransitionHistory"`
+	Extensions             []AgentExtension `json:"extensions,omitempty"`
+}
+
+// AgentExtension declares a protocol extension the agent supports
+type AgentExtension struct {
+	URI         string                 `json:"uri"`
+	Description string                 `json:"description,omitempty"`
+	Required    bool                   `json:"required,omitempty"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+}
+
+// SecurityScheme declares how clients authenticate, e.g. an API key header
+// or an HTTP bearer token
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// AgentSkill is one capability the agent offers
+type AgentSkill struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Examples    []string `json:"examples,omitempty"`
+	InputModes  []string `json:"inputModes,omitempty"`
+	OutputModes []string `json:"outputModes,omitempty"`
+}
+
+// CardConfig holds the deployment-specific parts of the agent card
+type CardConfig struct {
+	// URL is the A2A endpoint; empty leaves it to be filled in from the
+	// request when the card is served
+	URL string
+	// InputModes and OutputModes are the media types the endpoint accepts
+	// and returns; empty means text and data
+	InputModes  []string
+	OutputModes []string
+	// SecuritySchemes and Security declare the authentication the endpoint
+	// requires; empty means none
+	SecuritySchemes map[string]SecurityScheme
+	Security        []map[string][]string
+	// ExtendedCard advertises an authenticated extended card
+	ExtendedCard bool
+}
+
+// BuildCard assembles and validates the agent card for a deployment
+func BuildCard(config CardConfig) (*AgentCard, error) {
+	inputModes := config.InputModes
+	if len(inputModes) == 0 {
+		inputModes = []string{"text", "data"}
+	}
+	outputModes := config.OutputModes
+	if len(outputModes) == 0 {
+		outputModes = []string{"text", "data"}
+	}
+
+	card := &AgentCard{
+		ProtocolVersion:    ProtocolVersion,
+		Name:               "Customer Profiler",
+		Description:        "An intelligent agent that analyzes a business idea and predicts an ideal customer profile, including demographics, psychographics, and market fit insights.",
+		URL:                config.URL,
+		PreferredTransport: "JSONRPC",
+		Version:            Version(),
+		Provider: &AgentProvider{
+			Organization: "Beryl Atieno",
+			URL:          "https://github.com/BerylCAtieno/customer-profiler-agent",
+		},
+		Capabilities: AgentCapabilities{
+			Streaming:              true,
+			PushNotifications:      true,
+			StateTransitionHistory: false,
+		},
+		SecuritySchemes:                   config.SecuritySchemes,
+		Security:                          config.Security,
+		SupportsAuthenticatedExtendedCard: config.ExtendedCard,
+		DefaultInputModes:                 inputModes,
+		DefaultOutputModes:                outputModes,
+		Skills: []AgentSkill{
+			{
+				ID:          SkillProfileGeneration,
+				Name:        "Profile generation",
+				Description: "Generates customer personas for a business idea, pitch deck or business plan: demographics, pain points, motivations, interests and preferred channels.",
+				Tags:        []string{"customer profiling", "market analysis", "business ideas", "entrepreneurship", "AI insights"},
+				Examples: []string{
+					"A subscription box service for high-quality, sustainable coffee beans sourced from small farms.",
+					"A mobile app that helps nurses on night shifts plan healthy meals.",
+				},
+			},
+			{
+				ID:          SkillPersonaRefinement,
+				Name:        "Persona refinement",
+				Description: "Rewrites the personas of an earlier task in the same contextId to follow an instruction such as \"make persona 2 younger\".",
+				Tags:        []string{"customer profiling", "refinement"},
+				Examples:    []string{"make persona 2 younger and B2B-focused"},
+			},
+			{
+				ID:          SkillJourneyMapping,
+				Name:        "Journey mapping",
+				Description: "Maps each persona's journey from awareness to advocacy, with touchpoints, goals, pain points and opportunities per stage. Uses the personas of the same contextId, or generates them for the business idea.",
+				Tags:        []string{"customer journey", "marketing", "customer profiling"},
+				Examples:    []string{"map the customer journeys for these personas"},
+			},
+		},
+	}
+
+	if err := card.Validate(); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// securitySchemeTypes are the scheme types the A2A schema defines
+var securitySchemeTypes = map[string]bool{
+	"apiKey": true, "http": true, "oauth2": true, "openIdConnect": true, "mutualTLS": true,
+}
+
+// Validate checks the card against the required fields and constraints of
+// the A2A AgentCard schema. An empty URL is allowed for cards whose URL is
+// filled in when served.
+func (card *AgentCard) Validate() error {
+	var problems []error
+	require := func(value, field string) {
+		if value == "" {
+			problems = append(problems, fmt.Errorf("%s is required", field))
+		}
+	}
+
+	require(card.ProtocolVersion, "protocolVersion")
+	require(card.Name, "name")
+	require(card.Description, "description")
+	require(card.Version, "version")
+	if card.URL != "" {
+		if parsed, err := url.Parse(card.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, fmt.Errorf("url %q must be an absolute http(s) URL", card.URL))
+		}
+	}
+	if card.Provider != nil {
+		require(card.Provider.Organization, "provider.organization")
+		require(card.Provider.URL, "provider.url")
+	}
+	if len(card.DefaultInputModes) == 0 {
+		problems = append(problems, errors.New("defaultInputModes must not be empty"))
+	}
+	if len(card.DefaultOutputModes) == 0 {
+		problems = append(problems, errors.New("defaultOutputModes must not be empty"))
+	}
+
+	if len(card.Skills) == 0 {
+		problems = append(problems, errors.New("skills must not be empty"))
+	}
+	skillIDs := make(map[string]bool, len(card.Skills))
+	for i, skill := range card.Skills {
+		require(skill.ID, fmt.Sprintf("skills[%d].id", i))
+		require(skill.Name, fmt.Sprintf("skills[%d].name", i))
+		require(skill.Description, fmt.Sprintf("skills[%d].description", i))
+		if len(skill.Tags) == 0 {
+			problems = append(problems, fmt.Errorf("skills[%d].tags must not be empty", i))
+		}
+		if skillIDs[skill.ID] {
+			problems = append(problems, fmt.Errorf("skill id %q is used twice", skill.ID))
+		}
+		skillIDs[skill.ID] = true
+	}
+
+	for i, extension := range card.Capabilities.Extensions {
+		require(extension.URI, fmt.Sprintf("capabilities.extensions[%d].uri", i))
+	}
+
+	for name, scheme := range card.SecuritySchemes {
+		if !securitySchemeTypes[scheme.Type] {
+			problems = append(problems, fmt.Errorf("securitySchemes.%s has unknown type %q", name, scheme.Type))
+		}
+		switch scheme.Type {
+		case "apiKey":
+			require(scheme.Name, "securitySchemes."+name+".name")
+			if scheme.In != "header" && scheme.In != "query" && scheme.In != "cookie" {
+				problems = append(problems, fmt.Errorf("securitySchemes.%s.in must be header, query or cookie", name))
+			}
+		case "http":
+			require(scheme.Scheme, "securitySchemes."+name+".scheme")
+		}
+	}
+	for _, requirement := range card.Security {
+		for name := range requirement {
+			if _, ok := card.SecuritySchemes[name]; !ok {
+				problems = append(problems, fmt.Errorf("security references undeclared scheme %q", name))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid agent card: %w", errors.Join(problems...))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadCard overlays the JSON object in path on a copy of base: fields the
+// file sets replace base's, the rest are kept. The endpoint URL, protocol
+// version, version, capabilities and security always come from base, since
+// they describe what the server does rather than how it presents itself.
+// The result must still be a valid card.
+func LoadCard(base AgentCard, path string) (*AgentCard, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent card: %w", err)
+	}
+
+	// A JSON round trip copies base, so the overlay can't touch its slices
+	encoded, err := json.Marshal(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy agent card: %w", err)
+	}
+	var card AgentCard
+	if err := json.Unmarshal(encoded, &card); err != nil {
+		return nil, fmt.Errorf("failed to copy agent card: %w", err)
+	}
+	if err := json.Unmarshal(data, &card); err != nil {
+		return nil, fmt.Errorf("failed to parse agent card: %w", err)
+	}
+
+	card.URL = base.URL
+	card.ProtocolVersion = base.ProtocolVersion
+	card.Version = base.Version
+	card.Capabilities = base.Capabilities
+	card.SecuritySchemes = base.SecuritySchemes
+	card.Security = base.Security
+	card.SupportsAuthenticatedExtendedCard = base.SupportsAuthenticatedExtendedCard
+	if err := card.Validate(); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
@@ -0,0 +1,35 @@
+package agent
+
+// OperatorEndpointsExtension is the URI of the extension that lists a
+// deployment's operator endpoints in the extended card
+const OperatorEndpointsExtension = "https://github.com/BerylCAtieno/customer-profiler-agent/extensions/operator-endpoints/v1"
+
+// extendedSkills are only described to authenticated callers
+var extendedSkills = []AgentSkill{
+	{
+		ID:          "batch-profiling",
+		Name:        "Batch profiling",
+		Description: "Profiles up to 20 business ideas in one JSON-RPC batch request.",
+		Tags:        []string{"customer profiling", "batch"},
+	},
+}
+
+// ExtendedCard returns the card for authenticated callers: the public card
+// plus the extended skills and, if any, the operator endpoints by name
+func ExtendedCard(public AgentCard, endpoints map[string]string) AgentCard {
+	card := public
+	card.Skills = append(append([]AgentSkill(nil), public.Skills...), extendedSkills...)
+
+	if len(endpoints) > 0 {
+		params := make(map[string]interface{}, len(endpoints))
+		for name, endpoint := range endpoints {
+			params[name] = endpoint
+		}
+		card.Capabilities.Extensions = append(append([]AgentExtension(nil), public.Capabilities.Extensions...), AgentExtension{
+			URI:         OperatorEndpointsExtension,
+			Description: "Operator endpoints of this deployment, by name",
+			Params:      params,
+		})
+	}
+	return card
+}
@@ -0,0 +1,256 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+)
+
+const (
+	maxClusters      = 8
+	kmeansIterations = 25
+	topInterestCount = 3
+	sampleIdeaCount  = 3
+)
+
+// ClusterSummary describes one group of similar personas
+type ClusterSummary struct {
+	ID               int      `json:"id"`
+	Size             int      `json:"size"`
+	Summary          string   `json:"summary"`
+	DominantAge      string   `json:"dominant_age,omitempty"`
+	DominantLocation string   `json:"dominant_location,omitempty"`
+	DominantChannel  string   `json:"dominant_channel,omitempty"`
+	TopInterests     []string `json:"top_interests,omitempty"`
+	SampleIdeas      []string `json:"sample_ideas,omitempty"`
+}
+
+// personaText renders a persona as the text that gets embedded
+func personaText(profile models.CustomerProfile) string {
+	return strings.Join([]string{
+		"age: " + profile.Age,
+		"gender: " + profile.Gender,
+		"location: " + profile.Location,
+		"occupation: " + profile.Occupation,
+		"income: " + profile.Income,
+		"interests: " + strings.Join(profile.Interests, ","),
+		"channels: " + strings.Join(profile.PreferredChannels, ","),
+		"pain points: " + strings.Join(profile.PainPoints, ","),
+		"motivations: " + strings.Join(profile.Motivations, ","),
+	}, "; ")
+}
+
+// clusterCount picks k using the sqrt(n/2) rule of thumb
+func clusterCount(n int) int {
+	k := int(math.Round(math.Sqrt(float64(n) / 2)))
+	return max(1, min(k, maxClusters, n))
+}
+
+// kmeans assigns each vector to one of k clusters using cosine similarity.
+// Centroids are seeded deterministically by farthest-point selection so the
+// same library always yields the same clusters.
+func kmeans(vectors [][]float32, k int) []int {
+	normalized := make([][]float64, len(vectors))
+	for i, v := range vectors {
+		normalized[i] = normalize(v)
+	}
+
+	centroids := [][]float64{normalized[0]}
+	for len(centroids) < k {
+		farthest, farthestSim := 0, math.Inf(1)
+		for i, v := range normalized {
+			best := math.Inf(-1)
+			for _, c := range centroids {
+				best = math.Max(best, dot(v, c))
+			}
+			if best < farthestSim {
+				farthest, farthestSim = i, best
+			}
+		}
+		centroids = append(centroids, normalized[farthest])
+	}
+
+	assignments := make([]int, len(normalized))
+	for iter := 0; iter < kmeansIterations; iter++ {
+		changed := iter == 0
+		for i, v := range normalized {
+			if best := nearestCentroid(v, centroids); assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+
+		dims := len(normalized[0])
+		sums := make([][]float64, k)
+		for c := range sums {
+			sums[c] = make([]float64, dims)
+		}
+		for i, v := range normalized {
+			for d := range v {
+				sums[assignments[i]][d] += v[d]
+			}
+		}
+		for c := range centroids {
+			if norm := magnitude(sums[c]); norm > 0 {
+				for d := range sums[c] {
+					sums[c][d] /= norm
+				}
+				centroids[c] = sums[c]
+			}
+		}
+	}
+
+	return assignments
+}
+
+func nearestCentroid(v []float64, centroids [][]float64) int {
+	best, bestSim := 0, math.Inf(-1)
+	for c, centroid := range centroids {
+		if sim := dot(v, centroid); sim > bestSim {
+			best, bestSim = c, sim
+		}
+	}
+	return best
+}
+
+// summarizeClusters groups personas by assignment and describes each group,
+// largest first
+func summarizeClusters(personas []StoredPersona, assignments []int, k int) []ClusterSummary {
+	groups := make([][]StoredPersona, k)
+	for i, cluster := range assignments {
+		groups[cluster] = append(groups[cluster], personas[i])
+	}
+
+	var summaries []ClusterSummary
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+
+		var ages, locations, channels, interests, ideas []string
+		for _, persona := range group {
+			ages = append(ages, persona.Profile.Age)
+			locations = append(locations, persona.Profile.Location)
+			channels = append(channels, persona.Profile.PreferredChannels...)
+			interests = append(interests, persona.Profile.Interests...)
+			ideas = append(ideas, persona.BusinessIdea)
+		}
+
+		summary := ClusterSummary{
+			Size:             len(group),
+			DominantAge:      firstOrEmpty(mostCommon(ages, 1)),
+			DominantLocation: firstOrEmpty(mostCommon(locations, 1)),
+			DominantChannel:  firstOrEmpty(mostCommon(channels, 1)),
+			TopInterests:     mostCommon(interests, topInterestCount),
+			SampleIdeas:      mostCommon(ideas, sampleIdeaCount),
+		}
+		summary.Summary = describeCluster(summary)
+		summaries = append(summaries, summary)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Size > summaries[j].Size
+	})
+	for i := range summaries {
+		summaries[i].ID = i + 1
+	}
+
+	return summaries
+}
+
+// describeCluster renders a one-line, human readable cluster summary
+func describeCluster(summary ClusterSummary) string {
+	var audience []string
+	if summary.DominantLocation != "" {
+		audience = append(audience, strings.ToLower(summary.DominantLocation))
+	}
+	if summary.DominantAge != "" {
+		audience = append(audience, summary.DominantAge+" year olds")
+	} else {
+		audience = append(audience, "customers")
+	}
+
+	noun := "personas"
+	if summary.Size == 1 {
+		noun = "persona"
+	}
+
+	text := fmt.Sprintf("%d %s target %s", summary.Size, noun, strings.Join(audience, " "))
+	if summary.DominantChannel != "" {
+		text += " on " + summary.DominantChannel
+	}
+	if len(summary.TopInterests) > 0 {
+		text += fmt.Sprintf(" (interests: %s)", strings.Join(summary.TopInterests, ", "))
+	}
+	return text
+}
+
+// mostCommon returns up to n values ordered by frequency, compared
+// case-insensitively; ties keep first-seen order
+func mostCommon(values []string, n int) []string {
+	counts := make(map[string]int)
+	display := make(map[string]string)
+	var order []string
+
+	for _, value := range values {
+		trimmed := strings.TrimSpace(value)
+		key := strings.ToLower(trimmed)
+		if key == "" {
+			continue
+		}
+		if _, seen := counts[key]; !seen {
+			order = append(order, key)
+			display[key] = trimmed
+		}
+		counts[key]++
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return counts[order[i]] > counts[order[j]]
+	})
+
+	var result []string
+	for _, key := range order[:min(n, len(order))] {
+		result = append(result, display[key])
+	}
+	return result
+}
+
+func firstOrEmpty(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func normalize(v []float32) []float64 {
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = float64(x)
+	}
+	if norm := magnitude(out); norm > 0 {
+		for i := range out {
+			out[i] /= norm
+		}
+	}
+	return out
+}
+
+func magnitude(v []float64) float64 {
+	return math.Sqrt(dot(v, v))
+}
+
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,77 @@
+package analytics
+
+import (
+	"sort"
+	"sync"
+)
+
+// Ratings run from MinRating to MaxRating
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// FeedbackSummary aggregates the ratings given to tasks generated with one
+// prompt variant
+type FeedbackSummary struct {
+	PromptVariant string  `json:"prompt_variant"`
+	Count         int     `json:"count"`
+	AverageRating float64 `json:"average_rating"`
+	// Ratings counts the ratings given, from 1 to 5
+	Ratings [MaxRating]int `json:"ratings"`
+	// Comments counts the ratings that came with a comment
+	Comments int `json:"comments"`
+}
+
+// FeedbackStats keeps running totals of end-user ratings per prompt
+// variant, so overrides can be compared against the default prompt
+type FeedbackStats struct {
+	mu       sync.Mutex
+	variants map[string]*FeedbackSummary
+}
+
+func NewFeedbackStats() *FeedbackStats {
+	return &FeedbackStats{variants: make(map[string]*FeedbackSummary)}
+}
+
+// Record adds one rating for a task generated with the prompt variant.
+// Ratings outside 1-5 are ignored.
+func (s *FeedbackStats) Record(variant string, rating int, commented bool) {
+	if rating < MinRating || rating > MaxRating {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary, ok := s.variants[variant]
+	if !ok {
+		summary = &FeedbackSummary{PromptVariant: variant}
+		s.variants[variant] = summary
+	}
+	total := summary.AverageRating*float64(summary.Count) + float64(rating)
+	summary.Count++
+	summary.AverageRating = total / float64(summary.Count)
+	summary.Ratings[rating-1]++
+	if commented {
+		summary.Comments++
+	}
+}
+
+// Summaries returns the totals of every prompt variant, most rated first
+func (s *FeedbackStats) Summaries() []FeedbackSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries := make([]FeedbackSummary, 0, len(s.variants))
+	for _, summary := range s.variants {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		return summaries[i].PromptVariant < summaries[j].PromptVariant
+	})
+	return summaries
+}
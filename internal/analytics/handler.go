@@ -0,0 +1,39 @@
+package analytics
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	job      *ClusterJob
+	feedback *FeedbackStats
+}
+
+func NewHandler(job *ClusterJob, feedback *FeedbackStats) *Handler {
+	return &Handler{job: job, feedback: feedback}
+}
+
+// ServeClusters returns the latest persona cluster report. Passing
+// ?refresh=true, or asking before the first scheduled run, recomputes it.
+func (h *Handler) ServeClusters(c *gin.Context) {
+	report := h.job.Latest()
+
+	if report == nil || c.Query("refresh") == "true" {
+		var err error
+		report, err = h.job.Run(c.Request.Context())
+		if err != nil {
+			logger.ErrorContext(c.Request.Context(), "Failed to cluster personas", "error", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Persona clustering failed"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ServeFeedback returns the rating totals of each prompt variant
+func (h *Handler) ServeFeedback(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"variants": h.feedback.Summaries()})
+}
@@ -0,0 +1,118 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+)
+
+var logger = logging.For("analytics")
+
+// DefaultClusterInterval is how often the clustering job runs; each run
+// doubles as the daily persona report in the logs
+const DefaultClusterInterval = 24 * time.Hour
+
+// Embedder turns texts into embedding vectors
+type Embedder interface {
+	EmbedTexts(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// ClusterReport is the result of one clustering run over the library
+type ClusterReport struct {
+	GeneratedAt  time.Time        `json:"generated_at"`
+	PersonaCount int              `json:"persona_count"`
+	Clusters     []ClusterSummary `json:"clusters"`
+}
+
+// ClusterJob periodically clusters every persona in the library and keeps
+// the latest report for the analytics endpoint
+type ClusterJob struct {
+	library  *PersonaLibrary
+	embedder Embedder
+
+	runMu  sync.Mutex
+	mu     sync.RWMutex
+	latest *ClusterReport
+}
+
+func NewClusterJob(library *PersonaLibrary, embedder Embedder) *ClusterJob {
+	return &ClusterJob{
+		library:  library,
+		embedder: embedder,
+	}
+}
+
+// Start runs the job every interval until ctx is done
+func (j *ClusterJob) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultClusterInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := j.Run(ctx); err != nil {
+					logger.Error("Persona clustering failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Run clusters the current library, stores the report and logs it
+func (j *ClusterJob) Run(ctx context.Context) (*ClusterReport, error) {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	personas := j.library.All()
+	report := &ClusterReport{
+		GeneratedAt:  time.Now().UTC(),
+		PersonaCount: len(personas),
+		Clusters:     []ClusterSummary{},
+	}
+
+	if len(personas) > 0 {
+		texts := make([]string, len(personas))
+		for i, persona := range personas {
+			texts[i] = personaText(persona.Profile)
+		}
+
+		vectors, err := j.embedder.EmbedTexts(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed personas: %w", err)
+		}
+
+		k := clusterCount(len(personas))
+		report.Clusters = summarizeClusters(personas, kmeans(vectors, k), k)
+	}
+
+	j.mu.Lock()
+	j.latest = report
+	j.mu.Unlock()
+
+	logReport(report)
+	return report, nil
+}
+
+// Latest returns the most recent report, or nil if the job hasn't run yet
+func (j *ClusterJob) Latest() *ClusterReport {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.latest
+}
+
+func logReport(report *ClusterReport) {
+	logger.Info("Persona cluster report", "personas", report.PersonaCount, "clusters", len(report.Clusters))
+	for _, cluster := range report.Clusters {
+		logger.Info("Persona cluster", "cluster", cluster.ID, "summary", cluster.Summary)
+	}
+}
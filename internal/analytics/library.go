@@ -0,0 +1,67 @@
+// Package analytics aggregates generated personas across requests.
+package analytics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+)
+
+// DefaultLibrarySize bounds how many personas the in-memory library keeps
+const DefaultLibrarySize = 1000
+
+// StoredPersona is a generated persona together with the idea it was made for
+type StoredPersona struct {
+	BusinessIdea string                 `json:"business_idea"`
+	Profile      models.CustomerProfile `json:"profile"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+// PersonaLibrary keeps the most recently generated personas in memory,
+// dropping the oldest once full.
+type PersonaLibrary struct {
+	mu       sync.RWMutex
+	personas []StoredPersona
+	maxSize  int
+}
+
+func NewPersonaLibrary(maxSize int) *PersonaLibrary {
+	if maxSize <= 0 {
+		maxSize = DefaultLibrarySize
+	}
+	return &PersonaLibrary{maxSize: maxSize}
+}
+
+// Add records every persona in a generated response. Degraded template
+// personas are skipped since they say nothing about real demand.
+func (l *PersonaLibrary) Add(resp *models.ProfileResponse) {
+	if resp == nil || resp.Degraded {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, profile := range resp.Profiles {
+		l.personas = append(l.personas, StoredPersona{
+			BusinessIdea: redact.ForStore(resp.BusinessIdea),
+			Profile:      profile,
+			CreatedAt:    now,
+		})
+	}
+
+	if overflow := len(l.personas) - l.maxSize; overflow > 0 {
+		l.personas = append([]StoredPersona(nil), l.personas[overflow:]...)
+	}
+}
+
+// All returns a snapshot of the stored personas, oldest first
+func (l *PersonaLibrary) All() []StoredPersona {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]StoredPersona(nil), l.personas...)
+}
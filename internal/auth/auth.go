@@ -0,0 +1,115 @@
+// Package auth identifies the callers of the A2A endpoint and carries their
+// identity through the request context, for logging and quotas.
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Identity is an authenticated caller
+type Identity struct {
+	// Name identifies the caller in logs and rate limits
+	Name string
+	// Method is how the caller authenticated, e.g. "api_key"
+	Method string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying identity
+func NewContext(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext returns the caller identity in ctx, if it has one
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(Identity)
+	return identity, ok
+}
+
+// KeyStore looks up the caller an API key belongs to
+type KeyStore interface {
+	Lookup(key string) (name string, ok bool)
+}
+
+// Keys is a KeyStore held in memory. It keeps only SHA-256 hashes of the
+// keys, and compares every one in constant time, so lookups don't leak how
+// close a guess came.
+type Keys struct {
+	entries []keyEntry
+}
+
+type keyEntry struct {
+	name string
+	hash [sha256.Size]byte
+}
+
+// NewKeys returns a store of the keys in keys, by caller name
+func NewKeys(keys map[string]string) *Keys {
+	store := &Keys{}
+	for name, key := range keys {
+		store.entries = append(store.entries, keyEntry{name: name, hash: sha256.Sum256([]byte(key))})
+	}
+	return store
+}
+
+// LoadKeyFile reads a key file into keys. Each line names a caller and the
+// hex SHA-256 hash of its key, separated by whitespace, so the file holds
+// no usable keys; blank lines and lines starting with # are skipped.
+func (k *Keys) LoadKeyFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return fmt.Errorf("%s:%d: want a caller name and a key hash", path, line)
+		}
+		decoded, err := hex.DecodeString(fields[1])
+		if err != nil || len(decoded) != sha256.Size {
+			return fmt.Errorf("%s:%d: key hash must be a hex SHA-256 digest", path, line)
+		}
+		entry := keyEntry{name: fields[0]}
+		copy(entry.hash[:], decoded)
+		k.entries = append(k.entries, entry)
+	}
+	return scanner.Err()
+}
+
+// Len returns the number of keys
+func (k *Keys) Len() int {
+	return len(k.entries)
+}
+
+// Lookup returns the caller that key belongs to
+func (k *Keys) Lookup(key string) (string, bool) {
+	hash := sha256.Sum256([]byte(key))
+	name, found := "", false
+	for _, entry := range k.entries {
+		if subtle.ConstantTimeCompare(hash[:], entry.hash[:]) == 1 {
+			name, found = entry.name, true
+		}
+	}
+	return name, found
+}
+
+// HashKey returns the hex SHA-256 hash of key, as written in key files
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
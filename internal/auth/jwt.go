@@ -0,0 +1,411 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// JWKSRefreshInterval is how long a fetched key set is trusted before
+	// it is fetched again
+	JWKSRefreshInterval = time.Hour
+	// jwksRetryInterval bounds how often a token signed with an unknown key
+	// can make the verifier fetch the key set
+	jwksRetryInterval = time.Minute
+	// clockSkew is the leeway given to exp and nbf
+	clockSkew = time.Minute
+	// maxJWKSSize bounds the key set response
+	maxJWKSSize = 1 << 20
+)
+
+// ErrInvalidToken wraps every reason a bearer token is refused
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenVerifier checks bearer tokens and returns the caller they identify
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (Identity, error)
+}
+
+// JWTConfig configures the verification of JWT bearer tokens
+type JWTConfig struct {
+	// JWKSURL publishes the issuer's signing keys as a JSON Web Key Set
+	JWKSURL string
+	// Issuer must match the iss claim
+	Issuer string
+	// Audience must be one of the aud claim's values
+	Audience string
+	// HTTPClient fetches the key set; nil uses one with a 10 second timeout
+	HTTPClient *http.Client
+}
+
+// JWTVerifier checks JWT bearer tokens signed with RS256, RS384, RS512,
+// PS256, PS384, PS512, ES256, ES384 or ES512 by a key of the configured
+// key set. It is safe for concurrent use.
+type JWTVerifier struct {
+	config JWTConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	// refreshing is closed when the fetch in flight finishes
+	refreshing chan struct{}
+}
+
+func NewJWTVerifier(config JWTConfig) *JWTVerifier {
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &JWTVerifier{config: config, client: client}
+}
+
+// algorithm verifies the signatures of one JWS alg
+type algorithm struct {
+	hash   crypto.Hash
+	verify func(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) error
+}
+
+var algorithms = map[string]algorithm{
+	"RS256": {crypto.SHA256, verifyPKCS1}, "RS384": {crypto.SHA384, verifyPKCS1}, "RS512": {crypto.SHA512, verifyPKCS1},
+	"PS256": {crypto.SHA256, verifyPSS}, "PS384": {crypto.SHA384, verifyPSS}, "PS512": {crypto.SHA512, verifyPSS},
+	"ES256": {crypto.SHA256, verifyECDSA}, "ES384": {crypto.SHA384, verifyECDSA}, "ES512": {crypto.SHA512, verifyECDSA},
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Issuer    string      `json:"iss"`
+	Subject   string      `json:"sub"`
+	Audience  audience    `json:"aud"`
+	ExpiresAt json.Number `json:"exp"`
+	NotBefore json.Number `json:"nbf"`
+}
+
+// audience is the aud claim, a single string or an array of them
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings")
+	}
+	*a = list
+	return nil
+}
+
+// Verify checks the signature, issuer, audience and lifetime of token and
+// returns the caller named by its sub claim. Errors wrap ErrInvalidToken,
+// except failures to fetch the key set.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, fmt.Errorf("%w: not a JWS compact token", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Identity{}, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	alg, ok := algorithms[header.Alg]
+	if !ok {
+		return Identity{}, fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: signature is not base64url", ErrInvalidToken)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return Identity{}, err
+	}
+	digest := alg.hash.New()
+	digest.Write([]byte(parts[0] + "." + parts[1]))
+	if err := alg.verify(key, alg.hash, digest.Sum(nil), signature); err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.checkClaims(claims, time.Now()); err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return Identity{Name: claims.Subject, Method: "jwt"}, nil
+}
+
+// checkClaims applies the issuer, audience and lifetime checks
+func (v *JWTVerifier) checkClaims(claims jwtClaims, now time.Time) error {
+	if claims.Issuer != v.config.Issuer {
+		return fmt.Errorf("issuer %q is not trusted", claims.Issuer)
+	}
+	found := false
+	for _, aud := range claims.Audience {
+		found = found || aud == v.config.Audience
+	}
+	if !found {
+		return fmt.Errorf("token is not meant for audience %q", v.config.Audience)
+	}
+	if claims.Subject == "" {
+		return errors.New("token has no sub claim")
+	}
+
+	if claims.ExpiresAt == "" {
+		return errors.New("token has no exp claim")
+	}
+	expires, err := numericDate(claims.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("exp: %v", err)
+	}
+	if now.After(expires.Add(clockSkew)) {
+		return errors.New("token has expired")
+	}
+	if claims.NotBefore != "" {
+		notBefore, err := numericDate(claims.NotBefore)
+		if err != nil {
+			return fmt.Errorf("nbf: %v", err)
+		}
+		if now.Add(clockSkew).Before(notBefore) {
+			return errors.New("token is not valid yet")
+		}
+	}
+	return nil
+}
+
+// key returns the signing key with ID kid, fetching the key set when it is
+// stale or, at most every jwksRetryInterval, when it lacks the key. A token
+// without a kid may use a key set of one key.
+func (v *JWTVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	for {
+		v.mu.Lock()
+		key := v.lookup(kid)
+		since := time.Since(v.fetchedAt)
+		stale := v.keys == nil || since >= JWKSRefreshInterval || (key == nil && since >= jwksRetryInterval)
+		if !stale || (v.refreshing != nil && key != nil) {
+			v.mu.Unlock()
+			if key == nil {
+				return nil, fmt.Errorf("%w: no signing key %q in the key set", ErrInvalidToken, kid)
+			}
+			return key, nil
+		}
+
+		// Another verification is already fetching the set; wait for it
+		// rather than fetching it twice
+		if refreshing := v.refreshing; refreshing != nil {
+			v.mu.Unlock()
+			select {
+			case <-refreshing:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		// The fetch runs without mu so verifications with known keys
+		// aren't held up by the network
+		done := make(chan struct{})
+		v.refreshing = done
+		v.mu.Unlock()
+
+		keys, err := v.fetch(ctx)
+
+		v.mu.Lock()
+		if err == nil {
+			v.keys = keys
+		}
+		// A failed refresh keeps the old keys, retried after the interval
+		v.fetchedAt = time.Now()
+		v.refreshing = nil
+		close(done)
+		empty := v.keys == nil
+		v.mu.Unlock()
+		if err != nil && empty {
+			return nil, err
+		}
+	}
+}
+
+// lookup finds a key of the current set; the caller holds mu
+func (v *JWTVerifier) lookup(kid string) crypto.PublicKey {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key
+		}
+	}
+	return v.keys[kid]
+}
+
+// jwk is one key of a JSON Web Key Set
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetch downloads the key set, keeping its RSA and EC signing keys
+func (v *JWTVerifier) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.config.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		// Keys of other types, or malformed ones, can't verify our
+		// tokens; skipping them keeps the rest of the set usable
+		if public, err := key.publicKey(); err == nil {
+			keys[key.Kid] = public
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS holds no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() > 1<<31 {
+			return nil, errors.New("bad RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func verifyPKCS1(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) error {
+	public, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("alg needs an RSA key")
+	}
+	return rsa.VerifyPKCS1v15(public, hash, digest, signature)
+}
+
+func verifyPSS(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) error {
+	public, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("alg needs an RSA key")
+	}
+	return rsa.VerifyPSS(public, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+}
+
+// verifyECDSA checks a JWS ECDSA signature, the fixed-size r and s
+// concatenated
+func verifyECDSA(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) error {
+	public, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("alg needs an EC key")
+	}
+	size := (public.Curve.Params().BitSize + 7) / 8
+	if len(signature) != 2*size {
+		return errors.New("signature has the wrong length")
+	}
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+	if !ecdsa.Verify(public, digest, r, s) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("not base64url")
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("bad base64url integer")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// numericDate reads a NumericDate claim, seconds since the epoch
+func numericDate(value json.Number) (time.Time, error) {
+	seconds, err := value.Float64()
+	if err != nil {
+		return time.Time{}, errors.New("not a number")
+	}
+	return time.Unix(int64(seconds), 0), nil
+}
@@ -0,0 +1,416 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "profiler"
+)
+
+// testKey is a signing key of the test key set
+type testKey struct {
+	kid string
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newRSAKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, rsa: key}
+}
+
+func newECKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, ec: key}
+}
+
+func (k testKey) jwk() map[string]string {
+	encode := base64.RawURLEncoding.EncodeToString
+	if k.rsa != nil {
+		e := big.NewInt(int64(k.rsa.E)).Bytes()
+		return map[string]string{"kty": "RSA", "kid": k.kid, "use": "sig", "n": encode(k.rsa.N.Bytes()), "e": encode(e)}
+	}
+	size := (k.ec.Curve.Params().BitSize + 7) / 8
+	return map[string]string{
+		"kty": "EC", "kid": k.kid, "crv": "P-256",
+		"x": encode(k.ec.X.FillBytes(make([]byte, size))), "y": encode(k.ec.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+// jwksServer serves a key set that tests can rotate, counting fetches
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []testKey
+	fetches atomic.Int32
+	// block, when set, holds every fetch until it is closed
+	block chan struct{}
+}
+
+func newJWKSServer(t *testing.T, keys ...testKey) *jwksServer {
+	t.Helper()
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		block := s.block
+		set := make([]map[string]string, 0, len(s.keys))
+		for _, key := range s.keys {
+			set = append(set, key.jwk())
+		}
+		s.mu.Unlock()
+		if block != nil {
+			<-block
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": set})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...testKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func newTestVerifier(s *jwksServer) *JWTVerifier {
+	return NewJWTVerifier(JWTConfig{JWKSURL: s.URL, Issuer: testIssuer, Audience: testAudience})
+}
+
+// validClaims returns claims that pass every check, for tests to spoil
+func validClaims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss": testIssuer,
+		"aud": testAudience,
+		"sub": "alice",
+		"exp": now.Add(time.Hour).Unix(),
+		"nbf": now.Add(-time.Minute).Unix(),
+	}
+}
+
+// sign builds a compact JWS of claims with alg, signed by key
+func sign(t *testing.T, key testKey, alg string, claims map[string]interface{}) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": key.kid, "typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := base64.RawURLEncoding.EncodeToString
+	input := encode(header) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch {
+	case alg == "none":
+	case alg == "HS256":
+		// The classic confusion attack: the RSA public key as HMAC secret
+		mac := hmac.New(sha256.New, key.rsa.PublicKey.N.Bytes())
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case key.rsa != nil:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key.rsa, crypto.SHA256, digest[:])
+	default:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key.ec, digest[:])
+		if err == nil {
+			signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + encode(signature)
+}
+
+func TestJWTVerify(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+	server := newJWKSServer(t, rsaKey, ecKey)
+
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr string
+	}{
+		{name: "RS256", token: func() string { return sign(t, rsaKey, "RS256", validClaims()) }},
+		{name: "ES256", token: func() string { return sign(t, ecKey, "ES256", validClaims()) }},
+		{
+			name:  "audience in a list",
+			token: func() string { return sign(t, rsaKey, "RS256", with("aud", []string{"other", testAudience})) },
+		},
+		{
+			name:    "alg none",
+			token:   func() string { return sign(t, rsaKey, "none", validClaims()) },
+			wantErr: `unsupported alg "none"`,
+		},
+		{
+			name:    "HS256 keyed with the RSA public key",
+			token:   func() string { return sign(t, rsaKey, "HS256", validClaims()) },
+			wantErr: `unsupported alg "HS256"`,
+		},
+		{
+			name: "EC alg over an RSA key",
+			token: func() string {
+				token := sign(t, ecKey, "ES256", validClaims())
+				return strings.Replace(token, encodeHeader(t, "ES256", ecKey.kid), encodeHeader(t, "ES256", rsaKey.kid), 1)
+			},
+			wantErr: "alg needs an EC key",
+		},
+		{
+			name: "tampered claims",
+			token: func() string {
+				parts := strings.Split(sign(t, rsaKey, "RS256", validClaims()), ".")
+				forged, _ := json.Marshal(with("sub", "admin"))
+				return parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2]
+			},
+			wantErr: "verification error",
+		},
+		{
+			name:    "wrong issuer",
+			token:   func() string { return sign(t, rsaKey, "RS256", with("iss", "https://evil.example.com")) },
+			wantErr: "is not trusted",
+		},
+		{
+			name:    "missing issuer",
+			token:   func() string { return sign(t, rsaKey, "RS256", with("iss", nil)) },
+			wantErr: "is not trusted",
+		},
+		{
+			name:    "wrong audience",
+			token:   func() string { return sign(t, rsaKey, "RS256", with("aud", "billing")) },
+			wantErr: "not meant for audience",
+		},
+		{
+			name:    "missing sub",
+			token:   func() string { return sign(t, rsaKey, "RS256", with("sub", nil)) },
+			wantErr: "no sub claim",
+		},
+		{
+			name:    "missing exp",
+			token:   func() string { return sign(t, rsaKey, "RS256", with("exp", nil)) },
+			wantErr: "no exp claim",
+		},
+		{
+			name:    "unknown kid",
+			token:   func() string { return sign(t, newRSAKey(t, "rsa-unknown"), "RS256", validClaims()) },
+			wantErr: `no signing key "rsa-unknown"`,
+		},
+		{
+			name:    "not a JWS",
+			token:   func() string { return "not.a-token" },
+			wantErr: "not a JWS compact token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := newTestVerifier(server).Verify(context.Background(), tt.token())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				if identity.Name != "alice" || identity.Method != "jwt" {
+					t.Errorf("identity = %+v, want alice by jwt", identity)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("error = %v, want ErrInvalidToken", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func encodeHeader(t *testing.T, alg, kid string) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(header)
+}
+
+func TestJWTLifetimeSkew(t *testing.T) {
+	verifier := NewJWTVerifier(JWTConfig{Issuer: testIssuer, Audience: testAudience})
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name    string
+		exp     time.Duration
+		nbf     time.Duration
+		noNbf   bool
+		wantErr string
+	}{
+		{name: "valid", exp: time.Hour, nbf: -time.Hour},
+		{name: "expired within the skew", exp: -clockSkew + time.Second, nbf: -time.Hour},
+		{name: "expired exactly at the skew", exp: -clockSkew, nbf: -time.Hour},
+		{name: "expired beyond the skew", exp: -clockSkew - time.Second, nbf: -time.Hour, wantErr: "token has expired"},
+		{name: "not yet valid within the skew", exp: time.Hour, nbf: clockSkew - time.Second},
+		{name: "not yet valid exactly at the skew", exp: time.Hour, nbf: clockSkew},
+		{name: "not yet valid beyond the skew", exp: time.Hour, nbf: clockSkew + time.Second, wantErr: "token is not valid yet"},
+		{name: "no nbf", exp: time.Hour, noNbf: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwtClaims{
+				Issuer:    testIssuer,
+				Subject:   "alice",
+				Audience:  audience{testAudience},
+				ExpiresAt: numericClaim(now.Add(tt.exp)),
+			}
+			if !tt.noNbf {
+				claims.NotBefore = numericClaim(now.Add(tt.nbf))
+			}
+			err := verifier.checkClaims(claims, now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkClaims: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func numericClaim(at time.Time) json.Number {
+	data, _ := json.Marshal(at.Unix())
+	return json.Number(data)
+}
+
+func TestJWTUnknownKidRefetchesKeySet(t *testing.T) {
+	oldKey := newRSAKey(t, "old")
+	newKey := newRSAKey(t, "new")
+	server := newJWKSServer(t, oldKey)
+	verifier := newTestVerifier(server)
+	ctx := context.Background()
+
+	if _, err := verifier.Verify(ctx, sign(t, oldKey, "RS256", validClaims())); err != nil {
+		t.Fatalf("Verify with the first key: %v", err)
+	}
+	if got := server.fetches.Load(); got != 1 {
+		t.Fatalf("fetched the key set %d times, want 1", got)
+	}
+
+	// The issuer rotates its key; a token under the new kid arrives before
+	// jwksRetryInterval has passed, so the set isn't fetched again
+	server.setKeys(oldKey, newKey)
+	rotated := sign(t, newKey, "RS256", validClaims())
+	if _, err := verifier.Verify(ctx, rotated); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("error = %v, want ErrInvalidToken before the retry interval", err)
+	}
+	if got := server.fetches.Load(); got != 1 {
+		t.Fatalf("fetched the key set %d times within the retry interval, want 1", got)
+	}
+
+	verifier.mu.Lock()
+	verifier.fetchedAt = time.Now().Add(-jwksRetryInterval)
+	verifier.mu.Unlock()
+
+	if _, err := verifier.Verify(ctx, rotated); err != nil {
+		t.Fatalf("Verify with the rotated key: %v", err)
+	}
+	if got := server.fetches.Load(); got != 2 {
+		t.Errorf("fetched the key set %d times, want 2", got)
+	}
+	if _, err := verifier.Verify(ctx, sign(t, oldKey, "RS256", validClaims())); err != nil {
+		t.Errorf("Verify with the first key after the refetch: %v", err)
+	}
+}
+
+func TestJWTFetchDoesNotBlockKnownKeys(t *testing.T) {
+	known := newRSAKey(t, "known")
+	server := newJWKSServer(t, known)
+	verifier := newTestVerifier(server)
+	ctx := context.Background()
+	token := sign(t, known, "RS256", validClaims())
+
+	if _, err := verifier.Verify(ctx, token); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	// Hold the next fetch open and trigger it with an unknown kid
+	block := make(chan struct{})
+	server.mu.Lock()
+	server.block = block
+	server.mu.Unlock()
+	verifier.mu.Lock()
+	verifier.fetchedAt = time.Now().Add(-jwksRetryInterval)
+	verifier.mu.Unlock()
+
+	unknown := make(chan error, 1)
+	go func() {
+		_, err := verifier.Verify(ctx, sign(t, newRSAKey(t, "unknown"), "RS256", validClaims()))
+		unknown <- err
+	}()
+	for server.fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	verified := make(chan error, 1)
+	go func() {
+		_, err := verifier.Verify(ctx, token)
+		verified <- err
+	}()
+	select {
+	case err := <-verified:
+		if err != nil {
+			t.Errorf("Verify with a known key during a fetch: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Verify with a known key waited for the key set fetch")
+	}
+
+	close(block)
+	if err := <-unknown; !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("error = %v, want ErrInvalidToken for the unknown kid", err)
+	}
+}
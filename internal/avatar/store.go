@@ -0,0 +1,95 @@
+// Package avatar keeps generated persona images in memory and serves them
+// over HTTP so artifacts can reference them by URI.
+package avatar
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultMaxImages bounds how many avatars are held at once
+	DefaultMaxImages = 200
+	// DefaultTTL is how long an avatar stays downloadable
+	DefaultTTL = 24 * time.Hour
+)
+
+type image struct {
+	data      []byte
+	mimeType  string
+	expiresAt time.Time
+}
+
+// Store is a bounded, expiring in-memory image store
+type Store struct {
+	ttl       time.Duration
+	maxImages int
+
+	mu     sync.Mutex
+	images map[string]image
+	order  []string
+}
+
+func NewStore(ttl time.Duration, maxImages int) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if maxImages <= 0 {
+		maxImages = DefaultMaxImages
+	}
+	return &Store{
+		ttl:       ttl,
+		maxImages: maxImages,
+		images:    make(map[string]image),
+	}
+}
+
+// Put stores an image and returns its ID, evicting the oldest when full
+func (s *Store) Put(data []byte, mimeType string) string {
+	id := uuid.New().String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.order) >= s.maxImages {
+		delete(s.images, s.order[0])
+		s.order = s.order[1:]
+	}
+
+	s.images[id] = image{
+		data:      data,
+		mimeType:  mimeType,
+		expiresAt: time.Now().Add(s.ttl),
+	}
+	s.order = append(s.order, id)
+
+	return id
+}
+
+func (s *Store) get(id string) (image, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	img, ok := s.images[id]
+	if ok && time.Now().After(img.expiresAt) {
+		delete(s.images, id)
+		return image{}, false
+	}
+	return img, ok
+}
+
+// ServeAvatar writes the image stored under the :id path parameter
+func (s *Store) ServeAvatar(c *gin.Context) {
+	img, ok := s.get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Avatar not found"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Data(http.StatusOK, img.mimeType, img.data)
+}
@@ -0,0 +1,608 @@
+// Package config holds the server's settings. They are read from an
+// optional YAML or TOML file, overridden by environment variables, and
+// validated once at startup.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/tracing"
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Config is every setting of the server. The env tag of a field names the
+// environment variable that overrides it.
+type Config struct {
+	Server    Server    `yaml:"server" toml:"server"`
+	Gemini    Gemini    `yaml:"gemini" toml:"gemini"`
+	Cache     Cache     `yaml:"cache" toml:"cache"`
+	Auth      Auth      `yaml:"auth" toml:"auth"`
+	Methods   Methods   `yaml:"methods" toml:"methods"`
+	Limits    Limits    `yaml:"limits" toml:"limits"`
+	Workers   Workers   `yaml:"workers" toml:"workers"`
+	Output    Output    `yaml:"output" toml:"output"`
+	Policy    Policy    `yaml:"policy" toml:"policy"`
+	Stores    Stores    `yaml:"stores" toml:"stores"`
+	Database  Database  `yaml:"database" toml:"database"`
+	Secrets   Secrets   `yaml:"secrets" toml:"secrets"`
+	Demo      Demo      `yaml:"demo" toml:"demo"`
+	RateLimit RateLimit `yaml:"rateLimit" toml:"rateLimit"`
+	Registry  Registry  `yaml:"registry" toml:"registry"`
+	Analytics Analytics `yaml:"analytics" toml:"analytics"`
+	Logging   Logging   `yaml:"logging" toml:"logging"`
+	Tracing   Tracing   `yaml:"tracing" toml:"tracing"`
+	CORS      CORS      `yaml:"cors" toml:"cors"`
+}
+
+// Server configures the listeners and the server's lifecycle
+type Server struct {
+	Port string `yaml:"port" toml:"port" env:"PORT"`
+	// PublicBaseURL is the external base URL, for the agent card and links
+	PublicBaseURL string `yaml:"publicBaseUrl" toml:"publicBaseUrl" env:"PUBLIC_BASE_URL"`
+	// GRPCPort also serves the A2A interface over gRPC; empty disables it
+	GRPCPort string `yaml:"grpcPort" toml:"grpcPort" env:"GRPC_PORT"`
+	// AgentkitPath also serves the skills through pkg/agentkit
+	AgentkitPath        string   `yaml:"agentkitPath" toml:"agentkitPath" env:"AGENTKIT_PATH"`
+	ShutdownGracePeriod Duration `yaml:"shutdownGracePeriod" toml:"shutdownGracePeriod" env:"SHUTDOWN_GRACE_PERIOD"`
+	// BatchConcurrency bounds concurrent requests per JSON-RPC batch; zero
+	// uses the handler's default
+	BatchConcurrency int `yaml:"batchConcurrency" toml:"batchConcurrency" env:"BATCH_CONCURRENCY"`
+	// ReadOnly refuses generation during maintenance until ReadOnlyETA
+	ReadOnly    bool      `yaml:"readOnly" toml:"readOnly" env:"READ_ONLY"`
+	ReadOnlyETA time.Time `yaml:"readOnlyEta" toml:"readOnlyEta" env:"READ_ONLY_ETA"`
+	// TrustedProxies are the addresses or CIDR ranges of the reverse
+	// proxies whose X-Forwarded-For and X-Real-IP headers name the client.
+	// Empty trusts none, so per-IP limits count the connection's address.
+	TrustedProxies []string `yaml:"trustedProxies" toml:"trustedProxies" env:"TRUSTED_PROXIES"`
+	// AgentCardFile overlays the agent card's name, description, skills and
+	// provider from a JSON file, reloaded when it changes
+	AgentCardFile string `yaml:"agentCardFile" toml:"agentCardFile" env:"AGENT_CARD_FILE"`
+	// TLS serves HTTPS, and gRPC over TLS, instead of plain text
+	TLS TLS `yaml:"tls" toml:"tls"`
+}
+
+// TLS configures the server certificate, from files or from an ACME CA
+// such as Let's Encrypt; with neither the server serves plain text. With
+// ClientCAFile set, the A2A endpoint accepts callers that present a client
+// certificate signed by one of its CAs.
+type TLS struct {
+	CertFile string `yaml:"certFile" toml:"certFile" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"keyFile" toml:"keyFile" env:"TLS_KEY_FILE"`
+	// AutocertHosts are the hostnames to obtain certificates for
+	AutocertHosts []string `yaml:"autocertHosts" toml:"autocertHosts" env:"TLS_AUTOCERT_HOSTS"`
+	// AutocertEmail is the ACME account contact, for expiry notices
+	AutocertEmail string `yaml:"autocertEmail" toml:"autocertEmail" env:"TLS_AUTOCERT_EMAIL"`
+	// AutocertCacheDir keeps certificates across restarts, so they aren't
+	// requested again
+	AutocertCacheDir string `yaml:"autocertCacheDir" toml:"autocertCacheDir" env:"TLS_AUTOCERT_CACHE_DIR"`
+	// AutocertDirectoryURL is the ACME directory; empty uses Let's Encrypt
+	AutocertDirectoryURL string `yaml:"autocertDirectoryUrl" toml:"autocertDirectoryUrl" env:"TLS_AUTOCERT_DIRECTORY_URL"`
+	// RedirectPort, if set, serves ACME HTTP challenges on this port and
+	// redirects everything else to HTTPS
+	RedirectPort string `yaml:"redirectPort" toml:"redirectPort" env:"TLS_REDIRECT_PORT"`
+	ClientCAFile string `yaml:"clientCaFile" toml:"clientCaFile" env:"TLS_CLIENT_CA_FILE"`
+}
+
+// Gemini configures the model client. Zero limits use the profiler's
+// defaults.
+type Gemini struct {
+	APIKey                 string `yaml:"apiKey" toml:"apiKey" env:"GEMINI_API_KEY"`
+	Model                  string `yaml:"model" toml:"model" env:"GEMINI_MODEL"`
+	FallbackEnabled        bool   `yaml:"fallbackEnabled" toml:"fallbackEnabled" env:"PROFILE_FALLBACK_ENABLED"`
+	SegmentConcurrency     int    `yaml:"segmentConcurrency" toml:"segmentConcurrency" env:"PROFILE_SEGMENT_CONCURRENCY"`
+	MaxInputTokens         int    `yaml:"maxInputTokens" toml:"maxInputTokens" env:"PROFILE_MAX_INPUT_TOKENS"`
+	MaxOutputTokensCeiling int    `yaml:"maxOutputTokensCeiling" toml:"maxOutputTokensCeiling" env:"PROFILE_MAX_OUTPUT_TOKENS_CEILING"`
+	// PromptTemplateFile replaces the profile prompt with a text/template
+	// file, reloaded when it changes
+	PromptTemplateFile string `yaml:"promptTemplateFile" toml:"promptTemplateFile" env:"PROMPT_TEMPLATE_FILE"`
+}
+
+// Cache configures the profile response cache
+type Cache struct {
+	// TTL is how long generated profiles are reused; zero disables the cache
+	TTL Duration `yaml:"ttl" toml:"ttl" env:"PROFILE_CACHE_TTL"`
+}
+
+// Auth holds the tokens of the protected endpoints and features. An empty
+// token turns its feature off.
+type Auth struct {
+	PromptOverrideKey string `yaml:"promptOverrideKey" toml:"promptOverrideKey" env:"PROMPT_OVERRIDE_KEY"`
+	ExtendedCardToken string `yaml:"extendedCardToken" toml:"extendedCardToken" env:"EXTENDED_CARD_TOKEN"`
+	DebugToken        string `yaml:"debugToken" toml:"debugToken" env:"DEBUG_TOKEN"`
+	PreferencesToken  string `yaml:"preferencesToken" toml:"preferencesToken" env:"PREFERENCES_TOKEN"`
+	AdminToken        string `yaml:"adminToken" toml:"adminToken" env:"ADMIN_TOKEN"`
+	// APIKeys maps caller names to the API keys they present in the
+	// X-API-Key header. With any keys here or in APIKeysFile, the A2A
+	// endpoint requires one.
+	APIKeys map[string]string `yaml:"apiKeys" toml:"apiKeys" env:"API_KEYS"`
+	// APIKeysFile lists callers with the SHA-256 hashes of their keys
+	APIKeysFile string `yaml:"apiKeysFile" toml:"apiKeysFile" env:"API_KEYS_FILE"`
+	// JWT accepts bearer tokens from an identity provider, as well as or
+	// instead of API keys
+	JWT JWT `yaml:"jwt" toml:"jwt"`
+}
+
+// JWT configures JWT bearer token authentication; an empty JWKSURL turns
+// it off
+type JWT struct {
+	JWKSURL  string `yaml:"jwksUrl" toml:"jwksUrl" env:"JWT_JWKS_URL"`
+	Issuer   string `yaml:"issuer" toml:"issuer" env:"JWT_ISSUER"`
+	Audience string `yaml:"audience" toml:"audience" env:"JWT_AUDIENCE"`
+}
+
+// Methods configures the JSON-RPC method table
+type Methods struct {
+	// Aliases map alias names to methods; nil keeps the built-in aliases
+	// and an empty map accepts none
+	Aliases       map[string]string `yaml:"aliases" toml:"aliases" env:"METHOD_ALIASES"`
+	Disabled      []string          `yaml:"disabled" toml:"disabled" env:"DISABLED_METHODS"`
+	RejectAliases bool              `yaml:"rejectAliases" toml:"rejectAliases" env:"REJECT_METHOD_ALIASES"`
+}
+
+// Limits configures how requests over the limits are treated
+type Limits struct {
+	// Mode is "strict", which rejects values over limits, or "soft", which
+	// clamps them
+	Mode                     string `yaml:"mode" toml:"mode" env:"LIMIT_MODE"`
+	DataCompressionThreshold int    `yaml:"dataCompressionThreshold" toml:"dataCompressionThreshold" env:"DATA_COMPRESSION_THRESHOLD"`
+	FeedbackRegenerateBelow  int    `yaml:"feedbackRegenerateBelow" toml:"feedbackRegenerateBelow" env:"FEEDBACK_REGENERATE_BELOW"`
+	// GenerationTimeout bounds each generation; zero removes the bound
+	GenerationTimeout Duration `yaml:"generationTimeout" toml:"generationTimeout" env:"GENERATION_TIMEOUT"`
+	// MaxBodySize is the largest request body accepted, in bytes
+	MaxBodySize int `yaml:"maxBodySize" toml:"maxBodySize" env:"MAX_BODY_SIZE"`
+	// MaxJSONDepth bounds how deep a request's JSON may nest
+	MaxJSONDepth int `yaml:"maxJsonDepth" toml:"maxJsonDepth" env:"MAX_JSON_DEPTH"`
+	// MaxMessageParts bounds the parts of a message
+	MaxMessageParts int `yaml:"maxMessageParts" toml:"maxMessageParts" env:"MAX_MESSAGE_PARTS"`
+}
+
+// Workers bounds how many generations run at once
+type Workers struct {
+	// Concurrency is how many generations run at once; zero removes the
+	// bound
+	Concurrency int `yaml:"concurrency" toml:"concurrency" env:"WORKER_CONCURRENCY"`
+	// QueueSize is how many more may wait for a worker before requests get
+	// 429
+	QueueSize int `yaml:"queueSize" toml:"queueSize" env:"WORKER_QUEUE_SIZE"`
+	// WhenBusy is "wait", which holds blocking requests until a worker is
+	// free, or "async", which answers them with their submitted task
+	WhenBusy string `yaml:"whenBusy" toml:"whenBusy" env:"WORKER_WHEN_BUSY"`
+}
+
+// Output configures how profiles are presented
+type Output struct {
+	DisclosureFooter bool   `yaml:"disclosureFooter" toml:"disclosureFooter" env:"AI_DISCLOSURE_FOOTER"`
+	BrandingFile     string `yaml:"brandingFile" toml:"brandingFile" env:"BRANDING_FILE"`
+}
+
+// Policy names the policy files that replace the defaults
+type Policy struct {
+	UsageFile     string `yaml:"usageFile" toml:"usageFile" env:"USAGE_POLICY_FILE"`
+	RedactionFile string `yaml:"redactionFile" toml:"redactionFile" env:"REDACTION_POLICY_FILE"`
+}
+
+// Stores configures the in-memory stores
+type Stores struct {
+	// TTL is how long tasks and contexts are kept; zero disables expiry
+	TTL              Duration `yaml:"ttl" toml:"ttl" env:"STORE_TTL"`
+	SnapshotDir      string   `yaml:"snapshotDir" toml:"snapshotDir" env:"STORE_SNAPSHOT_DIR"`
+	SnapshotInterval Duration `yaml:"snapshotInterval" toml:"snapshotInterval" env:"STORE_SNAPSHOT_INTERVAL"`
+	// RedisURL keeps tasks in Redis instead of memory, shared by replicas;
+	// TTL applies to them too
+	RedisURL       string `yaml:"redisUrl" toml:"redisUrl" env:"REDIS_URL"`
+	RedisKeyPrefix string `yaml:"redisKeyPrefix" toml:"redisKeyPrefix" env:"REDIS_KEY_PREFIX"`
+	// IdempotencyTTL is how long idempotency keys are remembered
+	IdempotencyTTL Duration `yaml:"idempotencyTtl" toml:"idempotencyTtl" env:"IDEMPOTENCY_TTL"`
+}
+
+// Database configures the database of the persistent task store
+type Database struct {
+	URL         string `yaml:"url" toml:"url" env:"DATABASE_URL"`
+	Driver      string `yaml:"driver" toml:"driver" env:"DATABASE_DRIVER"`
+	AutoMigrate bool   `yaml:"autoMigrate" toml:"autoMigrate" env:"AUTO_MIGRATE"`
+	// Username and Password, if set, replace the credentials in the
+	// PostgreSQL URL, e.g. with ones read from Vault
+	Username string `yaml:"username" toml:"username" env:"DATABASE_USERNAME"`
+	Password string `yaml:"password" toml:"password" env:"DATABASE_PASSWORD"`
+}
+
+// Demo configures the public demo mode
+type Demo struct {
+	Enabled           bool `yaml:"enabled" toml:"enabled" env:"DEMO_MODE"`
+	RequestsPerMinute int  `yaml:"requestsPerMinute" toml:"requestsPerMinute" env:"DEMO_REQUESTS_PER_MINUTE"`
+	RequestsPerDay    int  `yaml:"requestsPerDay" toml:"requestsPerDay" env:"DEMO_REQUESTS_PER_DAY"`
+	MaxPersonas       int  `yaml:"maxPersonas" toml:"maxPersonas" env:"DEMO_MAX_PERSONAS"`
+}
+
+// RateLimit limits generation requests with per-minute token buckets; zero
+// turns a limit off
+type RateLimit struct {
+	// IPPerMinute is the requests each client IP may make
+	IPPerMinute int `yaml:"ipPerMinute" toml:"ipPerMinute" env:"RATE_LIMIT_IP_PER_MINUTE"`
+	// KeyPerMinute is the requests each X-API-Key may make, on top of
+	// the per-IP limit
+	KeyPerMinute int `yaml:"keyPerMinute" toml:"keyPerMinute" env:"RATE_LIMIT_KEY_PER_MINUTE"`
+}
+
+// Secrets configures the secret managers that settings written as
+// references, such as gcpsm://projects/acme/secrets/gemini-api-key, are
+// read from at startup
+type Secrets struct {
+	// RotationInterval is how often references are read again, so rotated
+	// secrets apply without a restart; zero reads them only at startup
+	RotationInterval Duration     `yaml:"rotationInterval" toml:"rotationInterval" env:"SECRETS_ROTATION_INTERVAL"`
+	GCP              GCPSecrets   `yaml:"gcp" toml:"gcp"`
+	Vault            VaultSecrets `yaml:"vault" toml:"vault"`
+}
+
+// GCPSecrets configures Google Cloud Secret Manager, which is reached with
+// Application Default Credentials
+type GCPSecrets struct {
+	// Project is the project of gcpsm:// references that name only a secret
+	Project string `yaml:"project" toml:"project" env:"SECRETS_GCP_PROJECT"`
+}
+
+// VaultSecrets configures HashiCorp Vault, for vault:// references; an
+// empty address turns it off
+type VaultSecrets struct {
+	Address   string `yaml:"address" toml:"address" env:"VAULT_ADDR"`
+	Namespace string `yaml:"namespace" toml:"namespace" env:"VAULT_NAMESPACE"`
+	// AuthMethod is approle, kubernetes or token
+	AuthMethod string `yaml:"authMethod" toml:"authMethod" env:"VAULT_AUTH_METHOD"`
+	// AuthMount is where the auth method is mounted; empty uses its name
+	AuthMount string `yaml:"authMount" toml:"authMount" env:"VAULT_AUTH_MOUNT"`
+	Token     string `yaml:"token" toml:"token" env:"VAULT_TOKEN"`
+	RoleID    string `yaml:"roleId" toml:"roleId" env:"VAULT_ROLE_ID"`
+	SecretID  string `yaml:"secretId" toml:"secretId" env:"VAULT_SECRET_ID"`
+	// Role is the Kubernetes auth role, logged in to with the service
+	// account token in KubernetesTokenFile
+	Role                string `yaml:"role" toml:"role" env:"VAULT_ROLE"`
+	KubernetesTokenFile string `yaml:"kubernetesTokenFile" toml:"kubernetesTokenFile" env:"VAULT_KUBERNETES_TOKEN_FILE"`
+	CACertFile          string `yaml:"caCertFile" toml:"caCertFile" env:"VAULT_CACERT"`
+}
+
+// Registry configures announcing the agent to an agent registry; an empty
+// URL turns it off
+type Registry struct {
+	URL     string `yaml:"url" toml:"url" env:"REGISTRY_URL"`
+	Token   string `yaml:"token" toml:"token" env:"REGISTRY_TOKEN"`
+	AgentID string `yaml:"agentId" toml:"agentId" env:"REGISTRY_AGENT_ID"`
+	// HeartbeatInterval of zero uses the registry client's default
+	HeartbeatInterval Duration `yaml:"heartbeatInterval" toml:"heartbeatInterval" env:"REGISTRY_HEARTBEAT_INTERVAL"`
+}
+
+// Analytics configures the persona analytics integration
+type Analytics struct {
+	// ClusterInterval of zero uses the analytics default
+	ClusterInterval Duration `yaml:"clusterInterval" toml:"clusterInterval" env:"PERSONA_CLUSTER_INTERVAL"`
+}
+
+// Logging configures the server's log output
+type Logging struct {
+	// Level is debug, info, warn or error; request and response bodies are
+	// only logged at debug
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	// Format is "text" or "json"
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+	// Requests logs each request at info level, whatever the level
+	Requests RequestLogging `yaml:"requests" toml:"requests"`
+}
+
+// RequestLogging configures the log of incoming requests. Credential
+// headers and fields are always masked; the lists add to them.
+type RequestLogging struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"LOG_REQUESTS"`
+	// MaxBodyBytes truncates logged bodies; zero logs no body
+	MaxBodyBytes int `yaml:"maxBodyBytes" toml:"maxBodyBytes" env:"LOG_REQUESTS_MAX_BODY_BYTES"`
+	// SampleRate is the fraction of requests logged, from 0 to 1
+	SampleRate    float64  `yaml:"sampleRate" toml:"sampleRate" env:"LOG_REQUESTS_SAMPLE_RATE"`
+	RedactHeaders []string `yaml:"redactHeaders" toml:"redactHeaders" env:"LOG_REQUESTS_REDACT_HEADERS"`
+	RedactFields  []string `yaml:"redactFields" toml:"redactFields" env:"LOG_REQUESTS_REDACT_FIELDS"`
+}
+
+// CORS configures cross-origin access for browser-based tools; no allowed
+// origins turns it off. Empty methods and headers use the cors package's
+// defaults.
+type CORS struct {
+	AllowedOrigins []string `yaml:"allowedOrigins" toml:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods []string `yaml:"allowedMethods" toml:"allowedMethods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders []string `yaml:"allowedHeaders" toml:"allowedHeaders" env:"CORS_ALLOWED_HEADERS"`
+	// MaxAge is how long browsers may cache preflight answers
+	MaxAge Duration `yaml:"maxAge" toml:"maxAge" env:"CORS_MAX_AGE"`
+}
+
+// Tracing configures span export. Spans are only exported by binaries
+// built with the otlp tag; an empty endpoint turns export off.
+type Tracing struct {
+	// Endpoint is the OTLP/HTTP collector URL, e.g. http://localhost:4318
+	Endpoint string `yaml:"endpoint" toml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	// ServiceName names the server in traces
+	ServiceName string `yaml:"serviceName" toml:"serviceName" env:"OTEL_SERVICE_NAME"`
+}
+
+// Default returns the settings used when neither the file nor the
+// environment sets them
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:                "8080",
+			ShutdownGracePeriod: Duration(30 * time.Second),
+			TLS:                 TLS{AutocertCacheDir: "autocert-cache"},
+		},
+		Gemini: Gemini{Model: profiler.ModelName},
+		Cache:  Cache{TTL: Duration(30 * time.Minute)},
+		Limits: Limits{
+			Mode:              "strict",
+			GenerationTimeout: Duration(a2a.DefaultGenerationTimeout),
+			MaxBodySize:       a2a.DefaultMaxBodySize,
+			MaxJSONDepth:      a2a.DefaultMaxJSONDepth,
+			MaxMessageParts:   a2a.DefaultMaxMessageParts,
+		},
+		Workers: Workers{
+			Concurrency: a2a.DefaultWorkers,
+			QueueSize:   a2a.DefaultWorkerQueue,
+			WhenBusy:    a2a.BusyWait,
+		},
+		Stores: Stores{
+			TTL:              Duration(a2a.DefaultStoreTTL),
+			SnapshotInterval: Duration(time.Minute),
+			IdempotencyTTL:   Duration(a2a.DefaultIdempotencyTTL),
+			RedisKeyPrefix:   "profiler:",
+		},
+		Database: Database{Driver: "postgres"},
+		Secrets:  Secrets{RotationInterval: Duration(10 * time.Minute)},
+		Demo: Demo{
+			RequestsPerMinute: 3,
+			RequestsPerDay:    20,
+			MaxPersonas:       a2a.DemoMaxPersonas,
+		},
+		Logging: Logging{
+			Level:    "info",
+			Format:   "text",
+			Requests: RequestLogging{MaxBodyBytes: 4096, SampleRate: 1},
+		},
+		Tracing: Tracing{ServiceName: tracing.ServiceName},
+	}
+}
+
+// Load reads the settings from the file at path, if path is not empty,
+// applies the environment overrides and validates the result. The format
+// follows the file extension: .yaml, .yml or .toml. Unknown keys are
+// rejected so typos don't go unnoticed.
+func Load(path string) (*Config, error) {
+	config := Default()
+	if path != "" {
+		if err := config.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// An empty file keeps the defaults
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data), yaml.DisallowUnknownField())
+		if err := decoder.Decode(c); err != nil {
+			return fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(c); err != nil {
+			return fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("config %s: unknown format %q; use .yaml, .yml or .toml", path, ext)
+	}
+	return nil
+}
+
+// Validate checks the settings, reporting every problem at once
+func (c *Config) Validate() error {
+	var problems []error
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	checkPort := func(value, field string) {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			problem("%s %q must be a port number", field, value)
+		}
+	}
+	checkPort(c.Server.Port, "server.port")
+	if c.Server.GRPCPort != "" {
+		checkPort(c.Server.GRPCPort, "server.grpcPort")
+		if c.Server.GRPCPort == c.Server.Port {
+			problem("server.grpcPort must differ from server.port")
+		}
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				problem("server.trustedProxies %q must be an IP address or CIDR range", proxy)
+			}
+		}
+	}
+
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		problem("server.tls.certFile and server.tls.keyFile must be set together")
+	}
+	tlsEnabled := c.Server.TLS.CertFile != "" || len(c.Server.TLS.AutocertHosts) > 0
+	if c.Server.TLS.CertFile != "" && len(c.Server.TLS.AutocertHosts) > 0 {
+		problem("server.tls.certFile and server.tls.autocertHosts are alternatives; set one")
+	}
+	if len(c.Server.TLS.AutocertHosts) > 0 && c.Server.TLS.AutocertCacheDir == "" {
+		problem("server.tls.autocertCacheDir must not be empty, so certificates survive restarts")
+	}
+	if c.Server.TLS.ClientCAFile != "" && !tlsEnabled {
+		problem("server.tls.clientCaFile needs server.tls.certFile or server.tls.autocertHosts, since client certificates need TLS")
+	}
+	if c.Server.TLS.RedirectPort != "" {
+		checkPort(c.Server.TLS.RedirectPort, "server.tls.redirectPort")
+		if !tlsEnabled {
+			problem("server.tls.redirectPort needs server.tls.certFile or server.tls.autocertHosts")
+		}
+		if c.Server.TLS.RedirectPort == c.Server.Port || c.Server.TLS.RedirectPort == c.Server.GRPCPort {
+			problem("server.tls.redirectPort must differ from server.port and server.grpcPort")
+		}
+	}
+
+	checkURL := func(value, field string) {
+		if value == "" {
+			return
+		}
+		if parsed, err := url.Parse(value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problem("%s %q must be an absolute http(s) URL", field, value)
+		}
+	}
+	checkURL(c.Server.PublicBaseURL, "server.publicBaseUrl")
+	checkURL(c.Registry.URL, "registry.url")
+	checkURL(c.Server.TLS.AutocertDirectoryURL, "server.tls.autocertDirectoryUrl")
+	checkURL(c.Tracing.Endpoint, "tracing.endpoint")
+	checkURL(c.Auth.JWT.JWKSURL, "auth.jwt.jwksUrl")
+	if c.Auth.JWT.JWKSURL != "" && (c.Auth.JWT.Issuer == "" || c.Auth.JWT.Audience == "") {
+		problem("auth.jwt.jwksUrl needs auth.jwt.issuer and auth.jwt.audience, so tokens minted for other services are refused")
+	}
+	if c.Registry.URL != "" && c.Server.PublicBaseURL == "" {
+		problem("registry.url needs server.publicBaseUrl, so the registered card has an absolute URL")
+	}
+
+	if c.Gemini.Model == "" {
+		problem("gemini.model must not be empty")
+	}
+
+	nonNegative := func(value int64, field string) {
+		if value < 0 {
+			problem("%s must not be negative", field)
+		}
+	}
+	nonNegative(int64(c.Server.ShutdownGracePeriod), "server.shutdownGracePeriod")
+	nonNegative(int64(c.Cache.TTL), "cache.ttl")
+	nonNegative(int64(c.Limits.GenerationTimeout), "limits.generationTimeout")
+	nonNegative(int64(c.Stores.TTL), "stores.ttl")
+	nonNegative(int64(c.Stores.IdempotencyTTL), "stores.idempotencyTtl")
+	nonNegative(int64(c.Registry.HeartbeatInterval), "registry.heartbeatInterval")
+	nonNegative(int64(c.Analytics.ClusterInterval), "analytics.clusterInterval")
+	nonNegative(int64(c.Secrets.RotationInterval), "secrets.rotationInterval")
+	if c.Stores.SnapshotInterval <= 0 {
+		problem("stores.snapshotInterval must be positive")
+	}
+
+	nonNegative(int64(c.Server.BatchConcurrency), "server.batchConcurrency")
+	nonNegative(int64(c.Gemini.SegmentConcurrency), "gemini.segmentConcurrency")
+	nonNegative(int64(c.Gemini.MaxInputTokens), "gemini.maxInputTokens")
+	nonNegative(int64(c.Gemini.MaxOutputTokensCeiling), "gemini.maxOutputTokensCeiling")
+	nonNegative(int64(c.Limits.DataCompressionThreshold), "limits.dataCompressionThreshold")
+	nonNegative(int64(c.Limits.MaxBodySize), "limits.maxBodySize")
+	nonNegative(int64(c.Limits.MaxJSONDepth), "limits.maxJsonDepth")
+	nonNegative(int64(c.Limits.MaxMessageParts), "limits.maxMessageParts")
+	nonNegative(int64(c.Workers.Concurrency), "workers.concurrency")
+	nonNegative(int64(c.Workers.QueueSize), "workers.queueSize")
+	if c.Workers.WhenBusy != a2a.BusyWait && c.Workers.WhenBusy != a2a.BusyAsync {
+		problem("workers.whenBusy %q must be wait or async", c.Workers.WhenBusy)
+	}
+	nonNegative(int64(c.Limits.FeedbackRegenerateBelow), "limits.feedbackRegenerateBelow")
+	nonNegative(int64(c.Demo.RequestsPerMinute), "demo.requestsPerMinute")
+	nonNegative(int64(c.Demo.RequestsPerDay), "demo.requestsPerDay")
+	nonNegative(int64(c.Demo.MaxPersonas), "demo.maxPersonas")
+	nonNegative(int64(c.RateLimit.IPPerMinute), "rateLimit.ipPerMinute")
+	nonNegative(int64(c.RateLimit.KeyPerMinute), "rateLimit.keyPerMinute")
+
+	if c.Limits.Mode != "strict" && c.Limits.Mode != "soft" {
+		problem("limits.mode %q must be strict or soft", c.Limits.Mode)
+	}
+	if c.Database.Driver != "postgres" && c.Database.Driver != "sqlite" {
+		problem("database.driver %q must be postgres or sqlite", c.Database.Driver)
+	}
+	if (c.Database.Username != "" || c.Database.Password != "") && c.Database.Driver != "postgres" {
+		problem("database.username and database.password need the postgres driver")
+	}
+	if vault := c.Secrets.Vault; vault.Address != "" {
+		checkURL(vault.Address, "secrets.vault.address")
+		switch vault.AuthMethod {
+		case "approle":
+			if vault.RoleID == "" || vault.SecretID == "" {
+				problem("secrets.vault.authMethod approle needs secrets.vault.roleId and secrets.vault.secretId")
+			}
+		case "kubernetes":
+			if vault.Role == "" {
+				problem("secrets.vault.authMethod kubernetes needs secrets.vault.role")
+			}
+		case "token":
+			if vault.Token == "" {
+				problem("secrets.vault.authMethod token needs secrets.vault.token")
+			}
+		default:
+			problem("secrets.vault.authMethod %q must be approle, kubernetes or token", vault.AuthMethod)
+		}
+	}
+	if _, err := logging.ParseLevel(c.Logging.Level); err != nil {
+		problem("logging.level %q must be debug, info, warn or error", c.Logging.Level)
+	}
+	if c.Logging.Format != "text" && c.Logging.Format != "json" {
+		problem("logging.format %q must be text or json", c.Logging.Format)
+	}
+	nonNegative(int64(c.Logging.Requests.MaxBodyBytes), "logging.requests.maxBodyBytes")
+	if rate := c.Logging.Requests.SampleRate; rate < 0 || rate > 1 {
+		problem("logging.requests.sampleRate %v must be between 0 and 1", rate)
+	}
+	if c.Tracing.Endpoint != "" && c.Tracing.ServiceName == "" {
+		problem("tracing.serviceName must not be empty when tracing.endpoint is set")
+	}
+	for name, key := range c.Auth.APIKeys {
+		if name == "" || key == "" {
+			problem("auth.apiKeys must map non-empty caller names to keys")
+			break
+		}
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		// Browsers send the origin alone, so a path or trailing slash
+		// would never match
+		if parsed, err := url.Parse(origin); origin != "*" && (err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.Path != "") {
+			problem("cors.allowedOrigins %q must be * or an origin like https://tools.example.com", origin)
+		}
+	}
+	nonNegative(int64(c.CORS.MaxAge), "cors.maxAge")
+	for alias, method := range c.Methods.Aliases {
+		if alias == "" || method == "" {
+			problem("methods.aliases must map non-empty names to methods")
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(problems...))
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration written as a string such as "30s" or "24h"
+// in config files
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+var (
+	durationType = reflect.TypeOf(Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// applyEnv overrides the settings whose environment variable is set. An
+// empty variable counts as unset, except for maps, where it means an
+// empty map.
+func applyEnv(config *Config) error {
+	return applyEnvFields(reflect.ValueOf(config).Elem())
+}
+
+func applyEnvFields(section reflect.Value) error {
+	for i := 0; i < section.NumField(); i++ {
+		field, info := section.Field(i), section.Type().Field(i)
+		if info.Type.Kind() == reflect.Struct && info.Type != timeType {
+			if err := applyEnvFields(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := info.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		raw, ok := os.LookupEnv(name)
+		if !ok || (raw == "" && field.Kind() != reflect.Map) {
+			continue
+		}
+		if err := setField(field, raw); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", name, raw, err)
+		}
+	}
+	return nil
+}
+
+// setField parses raw into a setting of one of the types Config uses
+func setField(field reflect.Value, raw string) error {
+	switch {
+	case field.Type() == durationType:
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case field.Type() == timeType:
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+	case field.Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		field.SetBool(parsed)
+	case field.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		field.SetInt(int64(parsed))
+	case field.Kind() == reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		field.SetFloat(parsed)
+	case field.Kind() == reflect.Slice:
+		field.Set(reflect.ValueOf(SplitList(raw)))
+	case field.Kind() == reflect.Map:
+		pairs, err := ParsePairs(raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(pairs))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// ParsePairs reads comma-separated name=value pairs, such as method
+// aliases; an empty string gives an empty map
+func ParsePairs(raw string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, pair := range SplitList(raw) {
+		name, value, ok := strings.Cut(pair, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("%q is not a name=value pair", pair)
+		}
+		pairs[name] = value
+	}
+	return pairs, nil
+}
+
+// SplitList splits a comma-separated list, dropping empty entries
+func SplitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,128 @@
+// Package cors lets browser-based tools on other origins call the agent, by
+// answering preflight requests and adding the CORS response headers for the
+// origins the operator allows.
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMethods are the methods allowed when the config names none
+var DefaultMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}
+
+// DefaultHeaders are the request headers allowed when the config names
+// none: those the A2A endpoint reads, and the trace context
+var DefaultHeaders = []string{
+	"Content-Type", "Accept", "Authorization", "X-API-Key", "X-Tenant-ID",
+	"X-Request-ID", "Last-Event-ID", "X-Prompt-Override-Key", "Idempotency-Key", "traceparent", "tracestate",
+}
+
+// exposedHeaders are the response headers browser scripts may read
+var exposedHeaders = []string{"X-Request-ID", "Retry-After", "Deprecation", "Idempotent-Replayed"}
+
+// Config lists what cross-origin callers may do. Credentials travel in
+// headers, never cookies, so credentialed requests aren't enabled.
+type Config struct {
+	// AllowedOrigins are the origins allowed, e.g. https://tools.example.com;
+	// "*" allows any
+	AllowedOrigins []string
+	// AllowedMethods defaults to DefaultMethods
+	AllowedMethods []string
+	// AllowedHeaders defaults to DefaultHeaders
+	AllowedHeaders []string
+	// MaxAge is how long browsers may cache a preflight answer; zero leaves
+	// it to the browser
+	MaxAge time.Duration
+}
+
+// AllowsOrigin reports whether requests from origin are allowed
+func (config Config) AllowsOrigin(origin string) bool {
+	for _, allowed := range config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware adds CORS headers to responses for allowed origins, and
+// answers their preflight requests with 204 No Content. Preflights from
+// other origins, or for methods or headers not allowed, get 403 Forbidden;
+// plain requests from other origins are served without CORS headers, so
+// browsers keep their responses from the calling script.
+func Middleware(config Config) gin.HandlerFunc {
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultMethods
+	}
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+	anyOrigin := config.AllowsOrigin("*")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if !anyOrigin {
+			// Caches must not serve one origin's answer to another
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !config.AllowsOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if anyOrigin {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if !preflight {
+			c.Header("Access-Control-Expose-Headers", strings.Join(exposedHeaders, ", "))
+			c.Next()
+			return
+		}
+
+		if !containsFold(methods, c.GetHeader("Access-Control-Request-Method")) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		for _, requested := range strings.Split(c.GetHeader("Access-Control-Request-Headers"), ",") {
+			if requested = strings.TrimSpace(requested); requested != "" && !containsFold(headers, requested) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+		}
+		c.Header("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		c.Header("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		if config.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// containsFold reports whether values holds value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Package document extracts plain text from uploaded business documents
+// such as pitch decks and business plans
+package document
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxSize caps how many bytes of a document are read
+const MaxSize = 10 << 20
+
+// ErrUnsupported means the document's type can't be read as text
+var ErrUnsupported = errors.New("unsupported document type")
+
+// ErrNoText means the document was read but holds no extractable text,
+// for example a scanned PDF
+var ErrNoText = errors.New("document contains no extractable text")
+
+const (
+	mimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	mimePPTX = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	mimePDF  = "application/pdf"
+)
+
+// Supported lists the MIME types Extract understands
+var Supported = []string{"text/plain", "text/markdown", "text/html", mimePDF, mimeDOCX, mimePPTX}
+
+// Extract returns the text of a document. The MIME type may be empty, in
+// which case it is guessed from the file name and then the content.
+func Extract(name, mimeType string, data []byte) (string, error) {
+	mimeType = DetectType(name, mimeType, data)
+
+	var text string
+	var err error
+	switch {
+	case mimeType == "text/html":
+		text = stripHTML(string(data))
+	case strings.HasPrefix(mimeType, "text/"):
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("%w: %s is not valid UTF-8", ErrUnsupported, mimeType)
+		}
+		text = string(data)
+	case mimeType == mimePDF:
+		text, err = extractPDF(data)
+	case mimeType == mimeDOCX:
+		text, err = extractOffice(data, "word/document.xml", "p")
+	case mimeType == mimePPTX:
+		text, err = extractOffice(data, "ppt/slides/slide", "p")
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupported, mimeType)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	text = normalizeSpace(text)
+	if text == "" {
+		return "", ErrNoText
+	}
+	return text, nil
+}
+
+// DetectType resolves a document's MIME type from the declared type, the
+// file name's extension and finally its content
+func DetectType(name, mimeType string, data []byte) string {
+	if mimeType != "" {
+		if parsed, _, err := mime.ParseMediaType(mimeType); err == nil {
+			return parsed
+		}
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		return "text/markdown"
+	case ".txt":
+		return "text/plain"
+	case ".docx":
+		return mimeDOCX
+	case ".pptx":
+		return mimePPTX
+	}
+	if byExt := mime.TypeByExtension(filepath.Ext(name)); byExt != "" {
+		parsed, _, _ := mime.ParseMediaType(byExt)
+		return parsed
+	}
+	parsed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return parsed
+}
+
+var (
+	htmlSkipped = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	htmlBreaks  = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6]|/tr)[^>]*>`)
+	htmlTags    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+func stripHTML(source string) string {
+	source = htmlSkipped.ReplaceAllString(source, "")
+	source = htmlBreaks.ReplaceAllString(source, "\n")
+	return html.UnescapeString(htmlTags.ReplaceAllString(source, ""))
+}
+
+// normalizeSpace trims each line and collapses runs of blank lines
+func normalizeSpace(text string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,83 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// extractOffice reads the text runs of the Office Open XML parts whose
+// names start with prefix, ending a line at each paragraph element
+func extractOffice(data []byte, prefix, paragraph string) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open document: %w", err)
+	}
+
+	var files []*zip.File
+	for _, file := range archive.File {
+		if strings.HasPrefix(file.Name, prefix) && strings.HasSuffix(file.Name, ".xml") {
+			files = append(files, file)
+		}
+	}
+	// Slides are numbered slide1.xml, slide2.xml, ... slide10.xml
+	sort.Slice(files, func(i, j int) bool {
+		return partNumber(files[i].Name, prefix) < partNumber(files[j].Name, prefix)
+	})
+
+	var builder strings.Builder
+	for _, file := range files {
+		if err := readRuns(file, paragraph, &builder); err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String(), nil
+}
+
+// readRuns appends the character data of the file's <t> elements
+func readRuns(file *zip.File, paragraph string, builder *strings.Builder) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	decoder := xml.NewDecoder(io.LimitReader(reader, MaxSize))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			inText = t.Name.Local == "t"
+		case xml.EndElement:
+			inText = false
+			if t.Name.Local == paragraph {
+				builder.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				builder.Write(t)
+			}
+		}
+	}
+}
+
+func partNumber(name, prefix string) int {
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".xml"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
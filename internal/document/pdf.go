@@ -0,0 +1,205 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// pdfStream matches a stream object's dictionary and the start of its data
+var pdfStream = regexp.MustCompile(`(?s)obj\s*<<(.*?)>>\s*stream\r?\n`)
+
+// extractPDF pulls the text shown by the page content streams of a PDF.
+// It is best effort: text drawn with embedded CID fonts or scanned as
+// images is not recovered.
+func extractPDF(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("%PDF-")) {
+		return "", errors.New("document is not a PDF")
+	}
+
+	var builder strings.Builder
+	for _, match := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		dict := data[match[2]:match[3]]
+		start := match[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			continue
+		}
+
+		content := data[start : start+end]
+		if bytes.Contains(dict, []byte("/Filter")) {
+			if !bytes.Contains(dict, []byte("/FlateDecode")) {
+				continue
+			}
+			reader, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			// A truncated stream still yields the text decoded so far
+			content, _ = io.ReadAll(io.LimitReader(reader, MaxSize))
+		}
+		if bytes.Contains(content, []byte("BT")) {
+			writeShownText(content, &builder)
+		}
+	}
+	return builder.String(), nil
+}
+
+// writeShownText scans a content stream for the strings passed to the text
+// showing operators Tj, TJ, ' and "
+func writeShownText(content []byte, builder *strings.Builder) {
+	var pending []string
+	for i := 0; i < len(content); {
+		switch ch := content[i]; {
+		case ch == '(':
+			text, next := literalString(content, i+1)
+			pending = append(pending, text)
+			i = next
+		case ch == '<' && i+1 < len(content) && content[i+1] != '<':
+			text, next := hexString(content, i+1)
+			pending = append(pending, text)
+			i = next
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			// Large negative kerning inside a TJ array separates words
+			start := i
+			for i < len(content) && (content[i] == '-' || content[i] == '.' || (content[i] >= '0' && content[i] <= '9')) {
+				i++
+			}
+			if len(pending) > 0 && content[start] == '-' && i-start > 3 {
+				pending = append(pending, " ")
+			}
+		case isOperatorChar(ch):
+			start := i
+			for i < len(content) && isOperatorChar(content[i]) {
+				i++
+			}
+			switch string(content[start:i]) {
+			case "Tj", "TJ", "'", "\"":
+				builder.WriteString(strings.Join(pending, ""))
+				pending = nil
+			case "Td", "TD", "T*", "ET":
+				builder.WriteString("\n")
+				pending = nil
+			default:
+				pending = nil
+			}
+		default:
+			i++
+		}
+	}
+}
+
+func isOperatorChar(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '*' || ch == '\'' || ch == '"'
+}
+
+// literalString decodes a (...) string starting after its open paren and
+// returns it with the index after the close paren
+func literalString(content []byte, i int) (string, int) {
+	var raw []byte
+	depth := 1
+	for ; i < len(content); i++ {
+		ch := content[i]
+		switch ch {
+		case '\\':
+			i++
+			if i >= len(content) {
+				return decodePDFString(raw), i
+			}
+			switch esc := content[i]; esc {
+			case 'n':
+				raw = append(raw, '\n')
+			case 'r':
+				raw = append(raw, '\r')
+			case 't':
+				raw = append(raw, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// An escaped line break continues the string
+			default:
+				if esc >= '0' && esc <= '7' {
+					value := 0
+					for n := 0; n < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7'; n++ {
+						value = value*8 + int(content[i]-'0')
+						i++
+					}
+					i--
+					raw = append(raw, byte(value))
+				} else {
+					raw = append(raw, esc)
+				}
+			}
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return decodePDFString(raw), i + 1
+			}
+		}
+		raw = append(raw, ch)
+	}
+	return decodePDFString(raw), i
+}
+
+// hexString decodes a <...> string starting after its open bracket
+func hexString(content []byte, i int) (string, int) {
+	var raw []byte
+	var digits []byte
+	for ; i < len(content) && content[i] != '>'; i++ {
+		if v, ok := hexValue(content[i]); ok {
+			digits = append(digits, v)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, 0)
+	}
+	for j := 0; j < len(digits); j += 2 {
+		raw = append(raw, digits[j]<<4|digits[j+1])
+	}
+	return decodePDFString(raw), i + 1
+}
+
+func hexValue(ch byte) (byte, bool) {
+	switch {
+	case ch >= '0' && ch <= '9':
+		return ch - '0', true
+	case ch >= 'a' && ch <= 'f':
+		return ch - 'a' + 10, true
+	case ch >= 'A' && ch <= 'F':
+		return ch - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// decodePDFString reads UTF-16 strings marked with a byte order mark and
+// treats the rest as Latin-1. Strings that decode to unprintable glyph
+// codes, as with CID fonts, are dropped.
+func decodePDFString(raw []byte) string {
+	var runes []rune
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		units := make([]uint16, 0, len(raw)/2)
+		for j := 2; j+1 < len(raw); j += 2 {
+			units = append(units, uint16(raw[j])<<8|uint16(raw[j+1]))
+		}
+		runes = utf16.Decode(units)
+	} else {
+		runes = make([]rune, len(raw))
+		for j, b := range raw {
+			runes[j] = rune(b)
+		}
+	}
+
+	for _, r := range runes {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return ""
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,124 @@
+// Package health serves the liveness and readiness probes. Liveness only
+// says the process answers; readiness runs a cheap check of each dependency
+// the server needs to generate profiles and reports each one's status.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+var logger = logging.For("health")
+
+// CheckTimeout bounds each dependency check of a readiness probe
+const CheckTimeout = 5 * time.Second
+
+// Check reports whether a dependency is usable. Checks run on every
+// readiness probe, so they should be cheap or cache their result.
+type Check func(ctx context.Context) error
+
+// Status is the outcome of one dependency check
+type Status struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report is the readiness response
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Status `json:"checks"`
+}
+
+// Checker holds the dependency checks of the readiness probe
+type Checker struct {
+	mu     sync.Mutex
+	checks map[string]Check
+}
+
+// New returns a checker without checks, which is always ready
+func New() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+// Add registers the check of the dependency name
+func (checker *Checker) Add(name string, check Check) {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	checker.checks[name] = check
+}
+
+// Run runs every check concurrently and reports the result. The server is
+// ready only if every check passed.
+func (checker *Checker) Run(ctx context.Context) Report {
+	checker.mu.Lock()
+	names := make([]string, 0, len(checker.checks))
+	for name := range checker.checks {
+		names = append(names, name)
+	}
+	checks := make([]Check, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		checks[i] = checker.checks[name]
+	}
+	checker.mu.Unlock()
+
+	statuses := make([]Status, len(names))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, CheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := checks[i](checkCtx)
+			statuses[i] = Status{Status: "ok", DurationMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				statuses[i].Status = "error"
+				statuses[i].Error = err.Error()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	report := Report{Status: "ok", Checks: make(map[string]Status, len(names))}
+	for i, name := range names {
+		report.Checks[name] = statuses[i]
+		if statuses[i].Status != "ok" {
+			report.Status = "error"
+		}
+	}
+	return report
+}
+
+// Liveness answers 200 while the process can serve requests at all. It
+// checks no dependency, so an outage of one doesn't get the server
+// restarted.
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness answers 200 with the status of each dependency when every
+// check passes, and 503 when any fails, so load balancers route around a
+// server that can't generate profiles
+func (checker *Checker) Readiness(c *gin.Context) {
+	report := checker.Run(c.Request.Context())
+	if report.Status != "ok" {
+		for name, status := range report.Checks {
+			if status.Status != "ok" {
+				logger.WarnContext(c.Request.Context(), "Readiness check failed", "check", name, "error", status.Error)
+			}
+		}
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,98 @@
+// Package latency records how long each stage of the profile pipeline takes.
+package latency
+
+import (
+	"sync"
+	"time"
+)
+
+// Pipeline stages, in the order a request passes through them
+const (
+	// StageParse covers reading the message, files and options
+	StageParse = "parse"
+	// StageGuard covers the vagueness check and the usage policy
+	StageGuard = "guard"
+	// StageLLM covers model calls, including concurrent ones as wall time
+	StageLLM = "llm"
+	// StageParseOutput covers parsing and normalizing model output
+	StageParseOutput = "parse_output"
+	// StageEnrich covers the interest graph, avatars and stored context
+	StageEnrich = "enrich"
+	// StageFormat covers rendering the task result
+	StageFormat = "format"
+)
+
+// Stages lists every stage of a breakdown
+var Stages = []string{StageParse, StageGuard, StageLLM, StageParseOutput, StageEnrich, StageFormat}
+
+// reportKeys name the stages in Milliseconds reports
+var reportKeys = map[string]string{
+	StageParse:       "parseMs",
+	StageGuard:       "guardMs",
+	StageLLM:         "llmMs",
+	StageParseOutput: "parseOutputMs",
+	StageEnrich:      "enrichMs",
+	StageFormat:      "formatMs",
+}
+
+// Breakdown accumulates time per stage. It is safe for concurrent use, and
+// a nil *Breakdown ignores everything recorded on it.
+type Breakdown struct {
+	mu     sync.Mutex
+	start  time.Time
+	stages map[string]time.Duration
+}
+
+// New starts a breakdown whose total runs from now
+func New() *Breakdown {
+	return &Breakdown{start: time.Now(), stages: make(map[string]time.Duration, len(Stages))}
+}
+
+// Add records d against stage
+func (b *Breakdown) Add(stage string, d time.Duration) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.stages[stage] += d
+	b.mu.Unlock()
+}
+
+// Track starts timing stage; call the returned func when the stage ends.
+// Only the first call counts, so it is safe to also defer it.
+func (b *Breakdown) Track(stage string) func() {
+	start := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.Add(stage, time.Since(start)) })
+	}
+}
+
+// Get returns the time recorded against stage
+func (b *Breakdown) Get(stage string) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stages[stage]
+}
+
+// Total is the time since the breakdown started
+func (b *Breakdown) Total() time.Duration {
+	if b == nil {
+		return 0
+	}
+	return time.Since(b.start)
+}
+
+// Milliseconds reports every stage and the total in whole milliseconds,
+// keyed like "llmMs" and "totalMs"
+func (b *Breakdown) Milliseconds() map[string]int64 {
+	report := make(map[string]int64, len(Stages)+1)
+	for _, stage := range Stages {
+		report[reportKeys[stage]] = b.Get(stage).Milliseconds()
+	}
+	report["totalMs"] = b.Total().Milliseconds()
+	return report
+}
@@ -0,0 +1,158 @@
+// Package locale defines the output languages profiles can be written in.
+package locale
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Default is used when a request doesn't ask for a language
+const Default = "en"
+
+// Headings are the fixed labels used when formatting a profile as text
+type Headings struct {
+	ProfileFor        string
+	NoProfiles        string
+	TemplatePersona   string
+	Demographics      string
+	Age               string
+	Gender            string
+	Location          string
+	Occupation        string
+	Income            string
+	PainPoints        string
+	Motivations       string
+	Interests         string
+	PreferredChannels string
+	Sources           string
+	AIGenerated       string
+	// Persona labels one persona when personas are split into separate artifacts
+	Persona string
+	// Summary and Keywords label the market summary section
+	Summary  string
+	Keywords string
+	// FitWarning flags a persona the readback check found not to fit the idea
+	FitWarning string
+	// Journey, Touchpoints, Goal, PainPoint and Opportunity label journey maps
+	Journey     string
+	Touchpoints string
+	Goal        string
+	PainPoint   string
+	Opportunity string
+}
+
+// Locale is a supported output language
+type Locale struct {
+	// Tag is the BCP 47 tag the caller asked for, e.g. "es-MX"
+	Tag string
+	// Name is the English language name used when prompting the model
+	Name     string
+	Headings Headings
+}
+
+var supported = map[string]Locale{
+	"en": {Name: "English", Headings: Headings{
+		ProfileFor: "Customer Profile for", NoProfiles: "No customer profiles generated.",
+		TemplatePersona: "Template persona (degraded mode)", Demographics: "Demographics",
+		Age: "Age", Gender: "Gender", Location: "Location", Occupation: "Occupation", Income: "Income",
+		PainPoints: "Pain Points", Motivations: "Motivations", Interests: "Interests",
+		PreferredChannels: "Preferred Channels", Sources: "Sources", AIGenerated: "AI-generated content",
+		Persona: "Persona", Summary: "Market Summary", Keywords: "Keywords",
+		FitWarning: "May not fit this idea",
+		Journey:    "Customer Journey", Touchpoints: "Touchpoints", Goal: "Goal", PainPoint: "Pain Point", Opportunity: "Opportunity",
+	}},
+	"es": {Name: "Spanish", Headings: Headings{
+		ProfileFor: "Perfil de cliente para", NoProfiles: "No se generaron perfiles de cliente.",
+		TemplatePersona: "Perfil de plantilla (modo degradado)", Demographics: "Datos demográficos",
+		Age: "Edad", Gender: "Género", Location: "Ubicación", Occupation: "Ocupación", Income: "Ingresos",
+		PainPoints: "Puntos de dolor", Motivations: "Motivaciones", Interests: "Intereses",
+		PreferredChannels: "Canales preferidos", Sources: "Fuentes", AIGenerated: "Contenido generado por IA",
+		Persona: "Perfil", Summary: "Resumen del mercado", Keywords: "Palabras clave",
+		FitWarning: "Puede no encajar con esta idea",
+		Journey:    "Recorrido del cliente", Touchpoints: "Puntos de contacto", Goal: "Objetivo", PainPoint: "Punto de dolor", Opportunity: "Oportunidad",
+	}},
+	"fr": {Name: "French", Headings: Headings{
+		ProfileFor: "Profil client pour", NoProfiles: "Aucun profil client généré.",
+		TemplatePersona: "Persona modèle (mode dégradé)", Demographics: "Données démographiques",
+		Age: "Âge", Gender: "Genre", Location: "Localisation", Occupation: "Profession", Income: "Revenu",
+		PainPoints: "Points de friction", Motivations: "Motivations", Interests: "Centres d'intérêt",
+		PreferredChannels: "Canaux préférés", Sources: "Sources", AIGenerated: "Contenu généré par IA",
+		Persona: "Persona", Summary: "Synthèse du marché", Keywords: "Mots-clés",
+		FitWarning: "Peut ne pas correspondre à cette idée",
+		Journey:    "Parcours client", Touchpoints: "Points de contact", Goal: "Objectif", PainPoint: "Point de friction", Opportunity: "Opportunité",
+	}},
+	"de": {Name: "German", Headings: Headings{
+		ProfileFor: "Kundenprofil für", NoProfiles: "Keine Kundenprofile erstellt.",
+		TemplatePersona: "Vorlagen-Persona (eingeschränkter Modus)", Demographics: "Demografie",
+		Age: "Alter", Gender: "Geschlecht", Location: "Standort", Occupation: "Beruf", Income: "Einkommen",
+		PainPoints: "Probleme", Motivations: "Motivationen", Interests: "Interessen",
+		PreferredChannels: "Bevorzugte Kanäle", Sources: "Quellen", AIGenerated: "KI-generierter Inhalt",
+		Persona: "Persona", Summary: "Marktüberblick", Keywords: "Schlüsselwörter",
+		FitWarning: "Passt möglicherweise nicht zur Idee",
+		Journey:    "Customer Journey", Touchpoints: "Kontaktpunkte", Goal: "Ziel", PainPoint: "Problem", Opportunity: "Chance",
+	}},
+	"pt": {Name: "Portuguese", Headings: Headings{
+		ProfileFor: "Perfil de cliente para", NoProfiles: "Nenhum perfil de cliente gerado.",
+		TemplatePersona: "Persona modelo (modo degradado)", Demographics: "Dados demográficos",
+		Age: "Idade", Gender: "Gênero", Location: "Localização", Occupation: "Ocupação", Income: "Renda",
+		PainPoints: "Dores", Motivations: "Motivações", Interests: "Interesses",
+		PreferredChannels: "Canais preferidos", Sources: "Fontes", AIGenerated: "Conteúdo gerado por IA",
+		Persona: "Persona", Summary: "Resumo do mercado", Keywords: "Palavras-chave",
+		FitWarning: "Pode não se encaixar nesta ideia",
+		Journey:    "Jornada do cliente", Touchpoints: "Pontos de contato", Goal: "Objetivo", PainPoint: "Dor", Opportunity: "Oportunidade",
+	}},
+	"it": {Name: "Italian", Headings: Headings{
+		ProfileFor: "Profilo cliente per", NoProfiles: "Nessun profilo cliente generato.",
+		TemplatePersona: "Persona modello (modalità ridotta)", Demographics: "Dati demografici",
+		Age: "Età", Gender: "Genere", Location: "Località", Occupation: "Professione", Income: "Reddito",
+		PainPoints: "Criticità", Motivations: "Motivazioni", Interests: "Interessi",
+		PreferredChannels: "Canali preferiti", Sources: "Fonti", AIGenerated: "Contenuto generato dall'IA",
+		Persona: "Persona", Summary: "Sintesi di mercato", Keywords: "Parole chiave",
+		FitWarning: "Potrebbe non adattarsi a questa idea",
+		Journey:    "Percorso del cliente", Touchpoints: "Punti di contatto", Goal: "Obiettivo", PainPoint: "Criticità", Opportunity: "Opportunità",
+	}},
+	"sw": {Name: "Swahili", Headings: Headings{
+		ProfileFor: "Wasifu wa mteja kwa", NoProfiles: "Hakuna wasifu wa mteja uliotengenezwa.",
+		TemplatePersona: "Wasifu wa kiolezo (hali ya dharura)", Demographics: "Demografia",
+		Age: "Umri", Gender: "Jinsia", Location: "Mahali", Occupation: "Kazi", Income: "Kipato",
+		PainPoints: "Changamoto", Motivations: "Motisha", Interests: "Mambo yanayompendeza",
+		PreferredChannels: "Njia zinazopendelewa", Sources: "Vyanzo", AIGenerated: "Maudhui yaliyotengenezwa na AI",
+		Persona: "Mteja", Summary: "Muhtasari wa soko", Keywords: "Maneno muhimu",
+		FitWarning: "Huenda asiendane na wazo hili",
+		Journey:    "Safari ya mteja", Touchpoints: "Sehemu za mawasiliano", Goal: "Lengo", PainPoint: "Changamoto", Opportunity: "Fursa",
+	}},
+}
+
+// Resolve looks up a BCP 47 tag by its primary language subtag; an empty
+// tag resolves to English
+func Resolve(tag string) (Locale, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		tag = Default
+	}
+
+	base := strings.ToLower(strings.SplitN(strings.ReplaceAll(tag, "_", "-"), "-", 2)[0])
+	loc, ok := supported[base]
+	if !ok {
+		return Locale{}, fmt.Errorf("unsupported language %q (supported: %s)", tag, strings.Join(Supported(), ", "))
+	}
+
+	loc.Tag = tag
+	return loc, nil
+}
+
+// Supported lists the supported primary language subtags
+func Supported() []string {
+	tags := make([]string, 0, len(supported))
+	for tag := range supported {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// IsEnglish reports whether the locale needs no translation instruction
+func (l Locale) IsEnglish() bool {
+	return l.Name == supported[Default].Name
+}
@@ -0,0 +1,138 @@
+// Package logging configures the process-wide slog logger and hands out the
+// per-component loggers the other packages log through.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/auth"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/requestid"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/tracing"
+)
+
+// level is the minimum level logged, adjustable while running
+var level slog.LevelVar
+
+// Setup makes a logger writing to w at the named level and format the
+// default, so component loggers and the standard log package write through
+// it. Records logged with a request's context carry its request_id, the
+// authenticated caller, and its trace_id and span_id when it is traced.
+func Setup(w io.Writer, name, format string) error {
+	if err := SetLevel(name); err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: &level}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("unknown log format %q (want text or json)", format)
+	}
+	slog.SetDefault(slog.New(requestHandler{handler}))
+	return nil
+}
+
+// SetLevel changes the minimum level logged, by name as for ParseLevel
+func SetLevel(name string) error {
+	lvl, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	UseLevel(lvl)
+	return nil
+}
+
+// UseLevel changes the minimum level logged
+func UseLevel(lvl slog.Level) {
+	level.Set(lvl)
+}
+
+// Level is the minimum level logged
+func Level() slog.Level {
+	return level.Level()
+}
+
+// ParseLevel reads a level name: debug, info, warn or error. An empty name
+// is info.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", name)
+	}
+	return level, nil
+}
+
+// For returns the logger of a component, which tags its records with
+// component=name. It writes through whatever logger is the default at the
+// time of the call, so packages can create theirs before Setup runs.
+func For(component string) *slog.Logger {
+	return slog.New(&componentHandler{
+		wrap: func(h slog.Handler) slog.Handler {
+			return h.WithAttrs([]slog.Attr{slog.String("component", component)})
+		},
+	})
+}
+
+// requestHandler adds the request ID, caller and trace IDs of the record's
+// context
+type requestHandler struct {
+	slog.Handler
+}
+
+func (h requestHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if identity, ok := auth.FromContext(ctx); ok {
+		record.AddAttrs(slog.String("caller", identity.Name))
+	}
+	if traceID, spanID := tracing.IDs(ctx); traceID != "" {
+		record.AddAttrs(slog.String("trace_id", traceID), slog.String("span_id", spanID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h requestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestHandler) WithGroup(name string) slog.Handler {
+	return requestHandler{h.Handler.WithGroup(name)}
+}
+
+// componentHandler applies its attributes and groups to the current default
+// handler at each record
+type componentHandler struct {
+	wrap func(slog.Handler) slog.Handler
+}
+
+func (h *componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.wrap(slog.Default().Handler()).Handle(ctx, record)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &componentHandler{wrap: func(inner slog.Handler) slog.Handler {
+		return h.wrap(inner).WithAttrs(attrs)
+	}}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return &componentHandler{wrap: func(inner slog.Handler) slog.Handler {
+		return h.wrap(inner).WithGroup(name)
+	}}
+}
@@ -0,0 +1,117 @@
+//go:build !minimal
+
+// Package metrics exposes Prometheus metrics in the OpenMetrics format.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultTenant labels usage from callers that don't identify a tenant
+const DefaultTenant = "default"
+
+var registry = prometheus.NewRegistry()
+
+var tokenUsage = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "llm_token_usage",
+		Help:    "Tokens consumed per LLM call, by model, tenant and token kind (prompt or completion).",
+		Buckets: prometheus.ExponentialBuckets(16, 2, 11), // 16 .. 16384
+	},
+	[]string{"model", "tenant", "kind"},
+)
+
+var stageDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "pipeline_stage_duration_seconds",
+		Help:    "Time spent per profile request in each pipeline stage.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 9), // 1ms .. ~65s
+	},
+	[]string{"stage"},
+)
+
+var feedbackRating = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "profile_feedback_rating",
+		Help:    "End-user ratings of generated profiles, from 1 to 5, by prompt variant.",
+		Buckets: prometheus.LinearBuckets(1, 1, 5), // 1 .. 5
+	},
+	[]string{"prompt_variant"},
+)
+
+var generationWorkers = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "generation_workers",
+		Help: "Generations running on a worker (busy) and waiting for one (queued).",
+	},
+	[]string{"state"},
+)
+
+var generationRejected = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "generation_rejected_total",
+		Help: "Generation requests refused because every worker was busy and the queue was full.",
+	},
+)
+
+func init() {
+	registry.MustRegister(
+		tokenUsage,
+		stageDuration,
+		feedbackRating,
+		generationWorkers,
+		generationRejected,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ObserveTokenUsage records the prompt and completion token counts of one LLM call
+func ObserveTokenUsage(model, tenant string, promptTokens, completionTokens int32) {
+	if tenant == "" {
+		tenant = DefaultTenant
+	}
+	tokenUsage.WithLabelValues(model, tenant, "prompt").Observe(float64(promptTokens))
+	tokenUsage.WithLabelValues(model, tenant, "completion").Observe(float64(completionTokens))
+}
+
+// ObserveStageDuration records the time one request spent in a pipeline stage
+func ObserveStageDuration(stage string, d time.Duration) {
+	stageDuration.WithLabelValues(stage).Observe(d.Seconds())
+}
+
+// ObserveFeedback records one end-user rating of a task's profiles
+func ObserveFeedback(promptVariant string, rating int) {
+	feedbackRating.WithLabelValues(promptVariant).Observe(float64(rating))
+}
+
+// SetGenerationWorkers records how many generations are running and how
+// many are queued
+func SetGenerationWorkers(busy, queued int) {
+	generationWorkers.WithLabelValues("busy").Set(float64(busy))
+	generationWorkers.WithLabelValues("queued").Set(float64(queued))
+}
+
+// CountGenerationRejected counts a generation refused for lack of workers
+func CountGenerationRejected() {
+	generationRejected.Inc()
+}
+
+// Registry returns the registry backing the metrics endpoint, for packages
+// that register their own collectors
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Handler serves every registered metric, negotiating OpenMetrics when the
+// scraper asks for it
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
@@ -0,0 +1,25 @@
+//go:build minimal
+
+// Package metrics is a no-op in minimal builds, which leave out the
+// Prometheus client and the /metrics endpoint.
+package metrics
+
+import "time"
+
+// DefaultTenant labels usage from callers that don't identify a tenant
+const DefaultTenant = "default"
+
+// ObserveTokenUsage does nothing in minimal builds
+func ObserveTokenUsage(model, tenant string, promptTokens, completionTokens int32) {}
+
+// ObserveStageDuration does nothing in minimal builds
+func ObserveStageDuration(stage string, d time.Duration) {}
+
+// ObserveFeedback does nothing in minimal builds
+func ObserveFeedback(promptVariant string, rating int) {}
+
+// SetGenerationWorkers does nothing in minimal builds
+func SetGenerationWorkers(busy, queued int) {}
+
+// CountGenerationRejected does nothing in minimal builds
+func CountGenerationRejected() {}
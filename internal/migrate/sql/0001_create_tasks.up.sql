@@ -0,0 +1,11 @@
+CREATE TABLE tasks (
+    id         TEXT PRIMARY KEY,
+    context_id TEXT NOT NULL DEFAULT '',
+    state      TEXT NOT NULL,
+    task       TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX tasks_context_id_idx ON tasks (context_id);
+CREATE INDEX tasks_updated_at_idx ON tasks (updated_at);
@@ -0,0 +1,19 @@
+CREATE TABLE profiles (
+    task_id       TEXT PRIMARY KEY,
+    context_id    TEXT NOT NULL DEFAULT '',
+    business_idea TEXT NOT NULL,
+    caller        TEXT NOT NULL DEFAULT '',
+    tenant        TEXT NOT NULL DEFAULT '',
+    profile_count INTEGER NOT NULL,
+    degraded      BOOLEAN NOT NULL DEFAULT FALSE,
+    response      TEXT NOT NULL,
+    artifacts     TEXT NOT NULL,
+    latency       TEXT NOT NULL,
+    started_at    TIMESTAMP NOT NULL,
+    finished_at   TIMESTAMP NOT NULL,
+    duration_ms   INTEGER NOT NULL
+);
+
+CREATE INDEX profiles_finished_at_idx ON profiles (finished_at);
+CREATE INDEX profiles_caller_idx ON profiles (caller, finished_at);
+CREATE INDEX profiles_tenant_idx ON profiles (tenant, finished_at);
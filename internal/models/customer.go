@@ -1,24 +1,17 @@
+// Package models re-exports the profile types of pkg/models so existing
+// imports keep working.
 package models
 
-// CustomerProfile represents a detailed customer persona
-type CustomerProfile struct {
-	Age               string   `json:"age"`
-	Gender            string   `json:"gender"`
-	Location          string   `json:"location"`
-	Occupation        string   `json:"occupation"`
-	Income            string   `json:"income"`
-	Motivations       []string `json:"motivations"`
-	Interests         []string `json:"interests"`
-	PainPoints        []string `json:"pain_points"`
-	BuyingBehaviors   []string `json:"buying_behaviors"`
-	PreferredChannels []string `json:"preferred_channels"`
-}
+import "github.com/BerylCAtieno/customer-profiler-agent/pkg/models"
 
-// ProfileResponse contains mulriple customer profiles related to a given business idea
-
-type ProfileResponse struct {
-	BusinessIdea string            `json:"business_idea"`
-	Profiles     []CustomerProfile `json:"profiles"`
-	Summary      string            `json:"summary"`
-	Keywords     []string          `json:"keywords"`
-}
+type (
+	CustomerProfile = models.CustomerProfile
+	ProfileResponse = models.ProfileResponse
+	Readback        = models.Readback
+	Journey         = models.Journey
+	JourneyStage    = models.JourneyStage
+	Source          = models.Source
+	InterestGraph   = models.InterestGraph
+	PersonaNode     = models.PersonaNode
+	OverlapEdge     = models.OverlapEdge
+)
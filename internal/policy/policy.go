@@ -0,0 +1,118 @@
+// Package policy describes the deployment's usage policy and enforces its
+// industry and category restrictions before profiles are generated.
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+	"github.com/gin-gonic/gin"
+)
+
+// Enforcer decides whether a business idea may be profiled. A non-nil
+// error blocks generation; *Violation errors are shown to the caller.
+type Enforcer interface {
+	Check(ctx context.Context, businessIdea string) error
+}
+
+// Category is a disallowed kind of business, matched by keyword
+type Category struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords"`
+}
+
+// Policy is the published usage policy of a deployment
+type Policy struct {
+	Description string `json:"description,omitempty"`
+	// AllowedIndustries restricts generation to these industries, as
+	// classified by profiler.ClassifyIndustry; empty allows every industry
+	AllowedIndustries []string `json:"allowed_industries,omitempty"`
+	// BlockedCategories are refused regardless of industry
+	BlockedCategories []Category `json:"blocked_categories,omitempty"`
+	// DataRetention describes how long each kind of data is kept
+	DataRetention map[string]string `json:"data_retention"`
+}
+
+// Violation explains why a business idea was refused
+type Violation struct {
+	Category string
+	Reason   string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("usage policy violation (%s): %s", v.Category, v.Reason)
+}
+
+// DefaultPolicy allows every idea and describes the built-in in-memory retention
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Description: "All industries are allowed. Nothing is written to disk; all data is held in memory and lost on restart.",
+		DataRetention: map[string]string{
+			"tasks":         "most recent 1000 tasks",
+			"personas":      "most recent 1000 personas, redacted",
+			"request_log":   "most recent 100 requests, redacted",
+			"avatars":       "24 hours",
+			"profile_cache": "PROFILE_CACHE_TTL (default 30 minutes)",
+		},
+	}
+}
+
+// LoadPolicy reads a policy from a JSON file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage policy: %w", err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse usage policy: %w", err)
+	}
+
+	for i, category := range policy.BlockedCategories {
+		if category.Name == "" || len(category.Keywords) == 0 {
+			return nil, fmt.Errorf("blocked category %d needs a name and keywords", i)
+		}
+	}
+	return &policy, nil
+}
+
+// Check refuses ideas in a blocked category or outside the allowed industries
+func (p *Policy) Check(ctx context.Context, businessIdea string) error {
+	normalized := " " + profiler.NormalizeIdea(businessIdea) + " "
+	for _, category := range p.BlockedCategories {
+		for _, keyword := range category.Keywords {
+			if strings.Contains(normalized, " "+profiler.NormalizeIdea(keyword)+" ") {
+				return &Violation{
+					Category: category.Name,
+					Reason:   fmt.Sprintf("%s businesses are not supported by this deployment", strings.ReplaceAll(category.Name, "_", " ")),
+				}
+			}
+		}
+	}
+
+	if len(p.AllowedIndustries) == 0 {
+		return nil
+	}
+
+	industry := profiler.ClassifyIndustry(businessIdea)
+	for _, allowed := range p.AllowedIndustries {
+		if allowed == industry {
+			return nil
+		}
+	}
+	return &Violation{
+		Category: industry,
+		Reason:   fmt.Sprintf("this deployment only profiles %s businesses", strings.ReplaceAll(strings.Join(p.AllowedIndustries, ", "), "_", " ")),
+	}
+}
+
+// Handler serves the policy as JSON
+func (p *Policy) Handler(c *gin.Context) {
+	c.JSON(http.StatusOK, p)
+}
@@ -0,0 +1,53 @@
+package profiler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// RetiredClientGrace is how long the client of a replaced API key stays
+// open, so generations that started with it can finish
+const RetiredClientGrace = 10 * time.Minute
+
+// geminiConn is a Gemini client and its model, made with one API key
+type geminiConn struct {
+	client *genai.Client
+	model  *genai.GenerativeModel
+	apiKey string
+}
+
+// dial makes a client and model for apiKey
+func (g *GeminiClient) dial(apiKey string) (*geminiConn, error) {
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+	model := client.GenerativeModel(g.modelName)
+	model.SetTemperature(0.7)
+	model.SetTopP(0.95)
+	model.SetMaxOutputTokens(defaultMaxOutputTokens)
+	return &geminiConn{client: client, model: model, apiKey: apiKey}, nil
+}
+
+// current is the connection new calls use
+func (g *GeminiClient) current() *geminiConn {
+	return g.conn.Load()
+}
+
+// SetAPIKey switches to a new API key, e.g. once it is rotated. New calls
+// use it at once; the old key's client is closed after RetiredClientGrace.
+func (g *GeminiClient) SetAPIKey(apiKey string) error {
+	conn, err := g.dial(apiKey)
+	if err != nil {
+		return err
+	}
+	retired := g.conn.Swap(conn)
+	time.AfterFunc(RetiredClientGrace, func() {
+		retired.client.Close()
+	})
+	return nil
+}
@@ -0,0 +1,119 @@
+package profiler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+)
+
+// AvatarModelName is the Imagen model used for persona avatars
+const AvatarModelName = "imagen-3.0-generate-002"
+
+// AvatarGenerator renders a representative portrait for a persona
+type AvatarGenerator interface {
+	GenerateAvatar(ctx context.Context, profile models.CustomerProfile) (image []byte, mimeType string, err error)
+}
+
+type imagenRequest struct {
+	Instances  []imagenInstance `json:"instances"`
+	Parameters imagenParameters `json:"parameters"`
+}
+
+type imagenInstance struct {
+	Prompt string `json:"prompt"`
+}
+
+type imagenParameters struct {
+	SampleCount int    `json:"sampleCount"`
+	AspectRatio string `json:"aspectRatio"`
+}
+
+type imagenResponse struct {
+	Predictions []struct {
+		BytesBase64Encoded string `json:"bytesBase64Encoded"`
+		MimeType           string `json:"mimeType"`
+	} `json:"predictions"`
+}
+
+// GenerateAvatar asks Imagen for a square, photo-style portrait of the persona
+func (g *GeminiClient) GenerateAvatar(ctx context.Context, profile models.CustomerProfile) ([]byte, string, error) {
+	payload, err := json.Marshal(imagenRequest{
+		Instances:  []imagenInstance{{Prompt: avatarPrompt(profile)}},
+		Parameters: imagenParameters{SampleCount: 1, AspectRatio: "1:1"},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode avatar request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:predict?key=%s",
+		generativeLanguageBaseURL, AvatarModelName, url.QueryEscape(g.current().apiKey))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build avatar request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate avatar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read avatar response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("avatar generation failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var parsed imagenResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, "", fmt.Errorf("failed to decode avatar response: %w", err)
+	}
+
+	if len(parsed.Predictions) == 0 || parsed.Predictions[0].BytesBase64Encoded == "" {
+		return nil, "", fmt.Errorf("no avatar generated")
+	}
+
+	image, err := base64.StdEncoding.DecodeString(parsed.Predictions[0].BytesBase64Encoded)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode avatar image: %w", err)
+	}
+
+	mimeType := parsed.Predictions[0].MimeType
+	if mimeType == "" {
+		mimeType = "image/png"
+	}
+
+	return image, mimeType, nil
+}
+
+func avatarPrompt(profile models.CustomerProfile) string {
+	var subject []string
+	for _, detail := range []string{profile.Age, profile.Gender, profile.Occupation} {
+		if detail = strings.TrimSpace(detail); detail != "" && !strings.EqualFold(detail, "any") {
+			subject = append(subject, detail)
+		}
+	}
+	if len(subject) == 0 {
+		subject = append(subject, "adult customer")
+	}
+
+	prompt := fmt.Sprintf("Friendly head-and-shoulders portrait photo of a %s", strings.Join(subject, " "))
+	if location := strings.TrimSpace(profile.Location); location != "" {
+		prompt += fmt.Sprintf(" in a %s setting", strings.ToLower(location))
+	}
+
+	return prompt + ", natural lighting, neutral background, realistic, no text"
+}
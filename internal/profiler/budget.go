@@ -0,0 +1,129 @@
+package profiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/google/generative-ai-go/genai"
+)
+
+const (
+	// DefaultMaxInputTokens is the budget for the business idea itself
+	DefaultMaxInputTokens = 2000
+	// maxInputBudgetMultiple is how far over budget an input may be before
+	// it is rejected outright instead of condensed
+	maxInputBudgetMultiple = 50
+	// charsPerToken approximates token counts when CountTokens is unavailable
+	charsPerToken = 4
+)
+
+var (
+	// ErrInputTooLarge means the idea is too long to condense into the budget
+	ErrInputTooLarge = errors.New("business idea is too large to process")
+	// ErrInputUnusable means the idea contains no usable text
+	ErrInputUnusable = errors.New("business idea contains no usable text")
+)
+
+// isInputError reports whether err is caused by the caller's input rather
+// than the model, so it should not be masked by fallbacks
+func isInputError(err error) bool {
+	return errors.Is(err, ErrInputTooLarge) || errors.Is(err, ErrInputUnusable)
+}
+
+// fitInputBudget returns the idea unchanged if it fits the input token
+// budget. Oversized ideas are summarized by the model, or truncated at a
+// sentence boundary if summarizing fails; the bool reports whether the idea
+// was condensed.
+func (g *GeminiClient) fitInputBudget(ctx context.Context, businessIdea string) (string, bool, error) {
+	if !hasUsableText(businessIdea) {
+		return "", false, ErrInputUnusable
+	}
+
+	tokens := g.countTokens(ctx, businessIdea)
+	if tokens <= g.maxInputTokens {
+		return businessIdea, false, nil
+	}
+
+	if tokens > g.maxInputTokens*maxInputBudgetMultiple {
+		return "", false, fmt.Errorf("%w: about %d tokens, limit is %d", ErrInputTooLarge, tokens, g.maxInputTokens*maxInputBudgetMultiple)
+	}
+
+	logger.WarnContext(ctx, "Business idea over the token budget, condensing", "tokens", tokens, "budget", g.maxInputTokens)
+
+	summary, err := g.summarizeIdea(ctx, businessIdea)
+	if err == nil && g.countTokens(ctx, summary) <= g.maxInputTokens {
+		return summary, true, nil
+	}
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to summarize oversized idea, truncating instead", "error", err)
+	}
+
+	return truncateToTokens(businessIdea, g.maxInputTokens), true, nil
+}
+
+// countTokens asks the API for an exact count, estimating from length if that fails
+func (g *GeminiClient) countTokens(ctx context.Context, text string) int {
+	resp, err := g.current().model.CountTokens(ctx, genai.Text(text))
+	if err != nil {
+		logger.WarnContext(ctx, "CountTokens failed, estimating", "error", err)
+		return len(text)/charsPerToken + 1
+	}
+	return int(resp.TotalTokens)
+}
+
+// summarizeIdea condenses a long business plan into a short idea description
+func (g *GeminiClient) summarizeIdea(ctx context.Context, businessIdea string) (string, error) {
+	words := g.maxInputTokens / 2
+	prompt := fmt.Sprintf(`Summarize the following business plan as a single plain-text paragraph of at most %d words. Keep the product or service, target market, pricing and geography. Output only the summary.
+
+%s`, words, redact.ForLLM(businessIdea))
+
+	resp, err := g.current().model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize idea: %w", err)
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return "", fmt.Errorf("no summary generated")
+	}
+
+	summary := strings.TrimSpace(completionFromCandidate(resp.Candidates[0]).text)
+	if summary == "" {
+		return "", fmt.Errorf("no summary generated")
+	}
+	return summary, nil
+}
+
+// truncateToTokens cuts text to roughly maxTokens, preferring to end on a
+// sentence boundary
+func truncateToTokens(text string, maxTokens int) string {
+	limit := maxTokens * charsPerToken
+	if len(text) <= limit {
+		return text
+	}
+
+	cut := text[:limit]
+	// Don't split a multi-byte rune
+	for !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+
+	if idx := strings.LastIndexAny(cut, ".!?\n"); idx > limit/2 {
+		cut = cut[:idx+1]
+	}
+	return strings.TrimSpace(cut)
+}
+
+// hasUsableText reports whether text contains at least one letter
+func hasUsableText(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package profiler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+)
+
+// DefaultCacheMaxEntries bounds the number of cached responses
+const DefaultCacheMaxEntries = 1000
+
+// fillerWords are dropped when normalizing ideas so trivially reworded
+// requests share a cache entry.
+var fillerWords = map[string]bool{
+	"a": true, "an": true, "the": true, "for": true, "of": true,
+	"generate": true, "customer": true, "profile": true, "profiles": true,
+}
+
+type cacheEntry struct {
+	response  models.ProfileResponse
+	expiresAt time.Time
+}
+
+// ResponseCache memoizes generated profiles keyed on a normalized business idea
+type ResponseCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// CacheStats is a snapshot of cache effectiveness
+type CacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+}
+
+func NewResponseCache(ttl time.Duration, maxEntries int) *ResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	return &ResponseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+// Get returns a cached response for key, if present and not expired
+func (c *ResponseCache) Get(key string) (*models.ProfileResponse, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	resp := cloneResponse(entry.response)
+	return &resp, true
+}
+
+// Set stores a response under key, evicting the oldest entry when full
+func (c *ResponseCache) Set(key string, resp *models.ProfileResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		c.evictLocked()
+	}
+
+	c.entries[key] = cacheEntry{
+		response:  cloneResponse(*resp),
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+// cloneResponse copies a response down to its nested slices and maps, so
+// callers that edit theirs, by redacting or clamping it, leave the cached
+// entry alone
+func cloneResponse(resp models.ProfileResponse) models.ProfileResponse {
+	if resp.Profiles != nil {
+		profiles := make([]models.CustomerProfile, len(resp.Profiles))
+		for i, profile := range resp.Profiles {
+			profile.Motivations = cloneStrings(profile.Motivations)
+			profile.Interests = cloneStrings(profile.Interests)
+			profile.PainPoints = cloneStrings(profile.PainPoints)
+			profile.BuyingBehaviors = cloneStrings(profile.BuyingBehaviors)
+			profile.PreferredChannels = cloneStrings(profile.PreferredChannels)
+			if profile.Custom != nil {
+				custom := make(map[string]string, len(profile.Custom))
+				for key, value := range profile.Custom {
+					custom[key] = value
+				}
+				profile.Custom = custom
+			}
+			profiles[i] = profile
+		}
+		resp.Profiles = profiles
+	}
+	resp.Keywords = cloneStrings(resp.Keywords)
+	resp.Warnings = cloneStrings(resp.Warnings)
+	if resp.Sources != nil {
+		resp.Sources = append(make([]models.Source, 0, len(resp.Sources)), resp.Sources...)
+	}
+	if resp.InterestGraph != nil {
+		graph := *resp.InterestGraph
+		if graph.Nodes != nil {
+			graph.Nodes = append(make([]models.PersonaNode, 0, len(graph.Nodes)), graph.Nodes...)
+		}
+		if graph.Edges != nil {
+			edges := make([]models.OverlapEdge, len(graph.Edges))
+			for i, edge := range graph.Edges {
+				edge.SharedInterests = cloneStrings(edge.SharedInterests)
+				edge.SharedChannels = cloneStrings(edge.SharedChannels)
+				edges[i] = edge
+			}
+			graph.Edges = edges
+		}
+		resp.InterestGraph = &graph
+	}
+	if resp.Readback != nil {
+		readback := *resp.Readback
+		resp.Readback = &readback
+	}
+	if resp.Journeys != nil {
+		journeys := make([]models.Journey, len(resp.Journeys))
+		for i, journey := range resp.Journeys {
+			if journey.Stages != nil {
+				stages := make([]models.JourneyStage, len(journey.Stages))
+				for j, stage := range journey.Stages {
+					stage.Touchpoints = cloneStrings(stage.Touchpoints)
+					stages[j] = stage
+				}
+				journey.Stages = stages
+			}
+			journeys[i] = journey
+		}
+		resp.Journeys = journeys
+	}
+	return resp
+}
+
+// cloneStrings copies a slice, keeping nil and empty apart as JSON does
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(values)), values...)
+}
+
+// evictLocked drops expired entries, or the entry closest to expiry if none have
+func (c *ResponseCache) evictLocked() {
+	now := time.Now()
+	var oldestKey string
+	var oldest time.Time
+
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey = key
+			oldest = entry.expiresAt
+		}
+	}
+
+	if len(c.entries) >= c.maxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+func (c *ResponseCache) Stats() CacheStats {
+	c.mu.Lock()
+	size := len(c.entries)
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: size,
+	}
+}
+
+// Flush drops every entry and returns how many there were
+func (c *ResponseCache) Flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flushed := len(c.entries)
+	c.entries = make(map[string]cacheEntry)
+	return flushed
+}
+
+// NormalizeIdea reduces a business idea to a canonical form: lowercase,
+// punctuation stripped, filler words removed, whitespace collapsed.
+func NormalizeIdea(idea string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, idea)
+
+	var words []string
+	for _, word := range strings.Fields(cleaned) {
+		if !fillerWords[word] {
+			words = append(words, word)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// cacheKey derives the cache key for an idea and the options that affect output
+func cacheKey(businessIdea string, opts GenerateOptions) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%t\x00%d\x00%t\x00%s\x00%t\x00%s\x00%s\x00%s\x00%t\x00%t\x00%s\x00%s\x00%t",
+		NormalizeIdea(businessIdea), opts.PromptOverride, opts.Reproducible, opts.personaCount(), opts.Grounded, opts.Language, opts.Summary,
+		opts.Tone, opts.Region, strings.Join(opts.CustomFields, ","), opts.Readback, opts.Journeys, opts.BrandVoice, hintLines(opts.Hints),
+		// The lookup runs before the input limit, so a soft-truncated
+		// response must not answer a strict request for the same idea
+		opts.SoftLimits)))
+	return hex.EncodeToString(sum[:])
+}
+
+func logCacheResult(ctx context.Context, hit bool, stats CacheStats) {
+	logger.DebugContext(ctx, "Profile cache lookup", "hit", hit, "hits", stats.Hits, "misses", stats.Misses, "entries", stats.Entries)
+}
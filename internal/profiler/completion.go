@@ -0,0 +1,269 @@
+package profiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/metrics"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/tracing"
+	"github.com/google/generative-ai-go/genai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultMaxOutputTokens is the output budget for a first attempt
+	defaultMaxOutputTokens = 2048
+	// DefaultMaxOutputTokensCeiling caps the max-token bump on truncated outputs
+	DefaultMaxOutputTokensCeiling = 8192
+)
+
+// ErrTruncatedOutput means the model ran out of output tokens even after a retry
+var ErrTruncatedOutput = errors.New("model output truncated at the token limit")
+
+// ModerationError means Gemini's safety filters blocked the prompt or response
+type ModerationError struct {
+	// Reason is the block or finish reason reported by the API
+	Reason string
+	// Categories lists the harm categories that triggered the block
+	Categories []string
+}
+
+func (e *ModerationError) Error() string {
+	if len(e.Categories) == 0 {
+		return fmt.Sprintf("content blocked by safety filters (%s)", e.Reason)
+	}
+	return fmt.Sprintf("content blocked by safety filters (%s: %s)", e.Reason, strings.Join(e.Categories, ", "))
+}
+
+// citationSpan is a byte range of the output attributed to a recited source
+type citationSpan struct {
+	start, end int
+}
+
+// completion is a model response normalized across the SDK and REST paths
+type completion struct {
+	text         string
+	finishReason genai.FinishReason
+	citations    []citationSpan
+	sources      []models.Source
+}
+
+// complete runs prompt once through the SDK, or the REST API when grounded
+func (g *GeminiClient) complete(ctx context.Context, model *genai.GenerativeModel, prompt string, opts GenerateOptions) (*completion, error) {
+	ctx, span := tracer.Start(ctx, "gemini.generate", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("gen_ai.system", "gemini"),
+		attribute.String("gen_ai.request.model", g.modelName),
+		attribute.Bool("gemini.grounded", opts.Grounded),
+	))
+	defer span.End()
+
+	comp, err := g.completeOnce(ctx, model, prompt, opts)
+	if err != nil {
+		tracing.Fail(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("gen_ai.response.finish_reason", comp.finishReason.String()))
+	g.health.succeeded()
+	return comp, nil
+}
+
+// completeOnce does the work of complete within its span
+func (g *GeminiClient) completeOnce(ctx context.Context, model *genai.GenerativeModel, prompt string, opts GenerateOptions) (*completion, error) {
+	if opts.Grounded {
+		return g.generateGroundedText(ctx, model, prompt, opts.Tenant)
+	}
+
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		var blocked *genai.BlockedError
+		if errors.As(err, &blocked) {
+			return completionFromBlocked(blocked)
+		}
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if resp.UsageMetadata != nil {
+		g.observeUsage(ctx, opts.Tenant, resp.UsageMetadata.PromptTokenCount, resp.UsageMetadata.CandidatesTokenCount)
+	}
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("no content generated")
+	}
+
+	return completionFromCandidate(resp.Candidates[0]), nil
+}
+
+// observeUsage records the tokens of a call in the metrics and on its span
+func (g *GeminiClient) observeUsage(ctx context.Context, tenant string, promptTokens, completionTokens int32) {
+	metrics.ObserveTokenUsage(g.modelName, tenant, promptTokens, completionTokens)
+	tracing.SetAttributes(ctx,
+		attribute.Int("gen_ai.usage.input_tokens", int(promptTokens)),
+		attribute.Int("gen_ai.usage.output_tokens", int(completionTokens)))
+}
+
+// completionFromBlocked turns a blocked response into a moderation error, or
+// for recitation blocks, into a completion whose cited spans can be stripped
+func completionFromBlocked(blocked *genai.BlockedError) (*completion, error) {
+	if blocked.Candidate != nil && blocked.Candidate.FinishReason == genai.FinishReasonRecitation &&
+		blocked.Candidate.Content != nil && len(blocked.Candidate.Content.Parts) > 0 {
+		return completionFromCandidate(blocked.Candidate), nil
+	}
+
+	moderation := &ModerationError{Reason: "SAFETY"}
+	var ratings []*genai.SafetyRating
+	if blocked.Candidate != nil {
+		moderation.Reason = strings.ToUpper(strings.TrimPrefix(blocked.Candidate.FinishReason.String(), "FinishReason"))
+		ratings = blocked.Candidate.SafetyRatings
+	}
+	if blocked.PromptFeedback != nil {
+		moderation.Reason = "PROMPT_" + strings.ToUpper(strings.TrimPrefix(blocked.PromptFeedback.BlockReason.String(), "BlockReason"))
+		ratings = blocked.PromptFeedback.SafetyRatings
+	}
+
+	for _, rating := range ratings {
+		if rating.Blocked || rating.Probability >= genai.HarmProbabilityMedium {
+			moderation.Categories = append(moderation.Categories, rating.Category.String())
+		}
+	}
+
+	return nil, moderation
+}
+
+func completionFromCandidate(candidate *genai.Candidate) *completion {
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		if t, ok := part.(genai.Text); ok {
+			text.WriteString(string(t))
+		}
+	}
+
+	comp := &completion{
+		text:         text.String(),
+		finishReason: candidate.FinishReason,
+	}
+
+	if candidate.CitationMetadata != nil {
+		for _, source := range candidate.CitationMetadata.CitationSources {
+			if source.StartIndex != nil && source.EndIndex != nil {
+				comp.citations = append(comp.citations, citationSpan{
+					start: int(*source.StartIndex),
+					end:   int(*source.EndIndex),
+				})
+			}
+		}
+	}
+
+	return comp
+}
+
+// completeWithFinishHandling runs prompt and reacts to the finish reason:
+// MAX_TOKENS retries once with a larger token budget, RECITATION strips the
+// recited spans, and SAFETY surfaces as a ModerationError from complete.
+func (g *GeminiClient) completeWithFinishHandling(ctx context.Context, model *genai.GenerativeModel, prompt string, opts GenerateOptions) (*completion, error) {
+	comp, err := g.complete(ctx, model, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if isTruncated(comp) {
+		bumped, ok := withRaisedTokenLimit(model, g.maxOutputTokensCeiling)
+		if !ok {
+			return nil, ErrTruncatedOutput
+		}
+
+		logger.WarnContext(ctx, "Output looks truncated, retrying with more output tokens", "finish_reason", comp.finishReason, "max_output_tokens", *bumped.MaxOutputTokens)
+		comp, err = g.complete(ctx, bumped, prompt, opts)
+		if err != nil {
+			return nil, err
+		}
+		if isTruncated(comp) {
+			return nil, ErrTruncatedOutput
+		}
+	}
+
+	if comp.finishReason == genai.FinishReasonRecitation {
+		logger.WarnContext(ctx, "Output flagged for recitation, stripping cited spans", "citations", len(comp.citations))
+		comp.text = stripCitations(comp.text, comp.citations)
+	}
+
+	if strings.TrimSpace(comp.text) == "" {
+		return nil, fmt.Errorf("no content generated (finish reason %s)", comp.finishReason)
+	}
+
+	return comp, nil
+}
+
+// isTruncated reports whether the model stopped at the token limit or
+// left a JSON object or array open
+func isTruncated(comp *completion) bool {
+	return comp.finishReason == genai.FinishReasonMaxTokens || unbalancedJSON(comp.text)
+}
+
+// unbalancedJSON reports whether text opens more JSON objects, arrays or
+// strings than it closes. Text without any brackets is never unbalanced.
+func unbalancedJSON(text string) bool {
+	depth := 0
+	inString, escaped := false, false
+	for _, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && r == '\\':
+			escaped = true
+		case r == '"':
+			inString = !inString
+		case inString:
+		case r == '{' || r == '[':
+			depth++
+		case r == '}' || r == ']':
+			depth--
+		}
+	}
+	return depth > 0 || (inString && strings.ContainsAny(text, "{["))
+}
+
+// withRaisedTokenLimit returns a copy of model with double the output token
+// budget, or false if the ceiling has already been reached
+func withRaisedTokenLimit(model *genai.GenerativeModel, ceiling int32) (*genai.GenerativeModel, bool) {
+	current := int32(defaultMaxOutputTokens)
+	if model.MaxOutputTokens != nil {
+		current = *model.MaxOutputTokens
+	}
+	if current >= ceiling {
+		return nil, false
+	}
+
+	bumped := *model
+	bumped.SetMaxOutputTokens(min(current*2, ceiling))
+	return &bumped, true
+}
+
+// stripCitations removes the byte ranges attributed to recited sources
+func stripCitations(text string, spans []citationSpan) string {
+	if len(spans) == 0 {
+		return text
+	}
+
+	sorted := append([]citationSpan(nil), spans...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start < sorted[j].start })
+
+	var builder strings.Builder
+	cursor := 0
+	for _, span := range sorted {
+		start := max(span.start, cursor)
+		end := min(span.end, len(text))
+		if start >= end {
+			continue
+		}
+		builder.WriteString(text[cursor:start])
+		cursor = end
+	}
+	builder.WriteString(text[cursor:])
+
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
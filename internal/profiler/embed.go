@@ -0,0 +1,44 @@
+package profiler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+const (
+	embeddingModelName = "text-embedding-004"
+	// embeddingBatchSize is the API's per-request limit for batch embeddings
+	embeddingBatchSize = 100
+)
+
+// EmbedTexts returns one clustering-tuned embedding vector per input text
+func (g *GeminiClient) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	model := g.current().client.EmbeddingModel(embeddingModelName)
+	model.TaskType = genai.TaskTypeClustering
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+
+		batch := model.NewBatch()
+		for _, text := range texts[start:end] {
+			batch.AddContent(genai.Text(text))
+		}
+
+		resp, err := model.BatchEmbedContents(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed texts: %w", err)
+		}
+		if len(resp.Embeddings) != end-start {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", end-start, len(resp.Embeddings))
+		}
+
+		for _, embedding := range resp.Embeddings {
+			vectors = append(vectors, embedding.Values)
+		}
+	}
+
+	return vectors, nil
+}
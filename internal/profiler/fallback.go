@@ -0,0 +1,149 @@
+package profiler
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:embed fallback.json
+var fallbackData []byte
+
+type fallbackTemplate struct {
+	Industry string                 `json:"industry"`
+	Keywords []string               `json:"keywords"`
+	Profile  models.CustomerProfile `json:"profile"`
+}
+
+type fallbackDataset struct {
+	Industries []fallbackTemplate `json:"industries"`
+	Default    fallbackTemplate   `json:"default"`
+}
+
+var fallbackTemplates fallbackDataset
+
+func init() {
+	if err := json.Unmarshal(fallbackData, &fallbackTemplates); err != nil {
+		panic(fmt.Sprintf("invalid fallback.json: %v", err))
+	}
+}
+
+// FallbackProfiles returns a clearly labeled template persona for the
+// industry that best matches the idea. It is used when the model is
+// unavailable and never calls out to the network.
+func FallbackProfiles(businessIdea string) *models.ProfileResponse {
+	template := matchFallbackTemplate(businessIdea)
+
+	profile := template.Profile
+	profile.Motivations = append([]string(nil), profile.Motivations...)
+	profile.Interests = append([]string(nil), profile.Interests...)
+	profile.PainPoints = append([]string(nil), profile.PainPoints...)
+	profile.BuyingBehaviors = append([]string(nil), profile.BuyingBehaviors...)
+	profile.PreferredChannels = append([]string(nil), profile.PreferredChannels...)
+
+	return &models.ProfileResponse{
+		BusinessIdea: businessIdea,
+		Profiles:     []models.CustomerProfile{profile},
+		Summary: fmt.Sprintf("Template persona for the %s industry. The AI model is currently unavailable, "+
+			"so this profile was not generated for your specific idea.", strings.ReplaceAll(template.Industry, "_", " ")),
+		Keywords:         []string{template.Industry},
+		Degraded:         true,
+		FallbackIndustry: template.Industry,
+	}
+}
+
+// statusError is a non-200 answer from a Gemini REST call
+type statusError struct {
+	op   string
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s failed with status %d: %s", e.op, e.code, e.body)
+}
+
+// modelUnavailable tells whether err means the model couldn't be reached
+// or couldn't serve: a transport failure, a timeout, or a 5xx or 429
+// answer. Only these get a fallback template; output the model did produce
+// but that was truncated or unparsable fails the request as it is.
+func modelUnavailable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) {
+		return true
+	}
+
+	var restErr *statusError
+	if errors.As(err, &restErr) {
+		return unavailableStatus(restErr.code)
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return unavailableStatus(apiErr.Code)
+	}
+	// The Gemini SDK reports API errors as gax-go APIErrors, which carry
+	// the HTTP status or the gRPC status depending on the transport
+	var httpErr interface{ HTTPCode() int }
+	if errors.As(err, &httpErr) && httpErr.HTTPCode() > 0 {
+		return unavailableStatus(httpErr.HTTPCode())
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Internal:
+			return true
+		}
+	}
+	return false
+}
+
+// unavailableStatus tells whether an HTTP status means the model is down
+// or overloaded rather than that the request was wrong
+func unavailableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// ClassifyIndustry returns the bundled industry whose keywords best match
+// the idea, or "general" when none match
+func ClassifyIndustry(businessIdea string) string {
+	return matchFallbackTemplate(businessIdea).Industry
+}
+
+// matchFallbackTemplate picks the industry with the most keyword hits
+func matchFallbackTemplate(businessIdea string) fallbackTemplate {
+	words := strings.Fields(NormalizeIdea(businessIdea))
+	normalized := " " + strings.Join(words, " ") + " "
+
+	best := fallbackTemplates.Default
+	bestScore := 0
+
+	for _, template := range fallbackTemplates.Industries {
+		score := 0
+		for _, keyword := range template.Keywords {
+			if strings.Contains(normalized, " "+NormalizeIdea(keyword)+" ") {
+				score++
+			}
+		}
+		if score > bestScore {
+			best = template
+			bestScore = score
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,86 @@
+package profiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+)
+
+// BuildInterestGraph links personas that share interests or preferred
+// channels. It returns nil for fewer than two personas.
+func BuildInterestGraph(profiles []models.CustomerProfile) *models.InterestGraph {
+	if len(profiles) < 2 {
+		return nil
+	}
+
+	graph := &models.InterestGraph{
+		Nodes: make([]models.PersonaNode, 0, len(profiles)),
+		Edges: []models.OverlapEdge{},
+	}
+
+	for i, profile := range profiles {
+		graph.Nodes = append(graph.Nodes, models.PersonaNode{
+			ID:    personaNodeID(i),
+			Label: personaLabel(profile),
+		})
+	}
+
+	for i := 0; i < len(profiles); i++ {
+		for j := i + 1; j < len(profiles); j++ {
+			interests := sharedItems(profiles[i].Interests, profiles[j].Interests)
+			channels := sharedItems(profiles[i].PreferredChannels, profiles[j].PreferredChannels)
+			if len(interests) == 0 && len(channels) == 0 {
+				continue
+			}
+
+			graph.Edges = append(graph.Edges, models.OverlapEdge{
+				Source:          personaNodeID(i),
+				Target:          personaNodeID(j),
+				SharedInterests: interests,
+				SharedChannels:  channels,
+				Weight:          len(interests) + len(channels),
+			})
+		}
+	}
+
+	return graph
+}
+
+func personaNodeID(index int) string {
+	return fmt.Sprintf("persona-%d", index+1)
+}
+
+func personaLabel(profile models.CustomerProfile) string {
+	label := strings.TrimSpace(profile.Occupation)
+	if label == "" {
+		label = "Persona"
+	}
+	if age := strings.TrimSpace(profile.Age); age != "" {
+		label = fmt.Sprintf("%s (%s)", label, age)
+	}
+	return label
+}
+
+// sharedItems returns the case-insensitive intersection of two lists, sorted
+func sharedItems(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	for _, item := range a {
+		if key := strings.ToLower(strings.TrimSpace(item)); key != "" {
+			seen[key] = true
+		}
+	}
+
+	var shared []string
+	for _, item := range b {
+		key := strings.ToLower(strings.TrimSpace(item))
+		if seen[key] {
+			shared = append(shared, key)
+			delete(seen, key)
+		}
+	}
+
+	sort.Strings(shared)
+	return shared
+}
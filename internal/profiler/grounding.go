@@ -0,0 +1,208 @@
+package profiler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// The genai SDK predates the google_search tool, so grounded requests go
+// straight to the REST API.
+const generativeLanguageBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+type groundedRequest struct {
+	Contents         []groundedContent        `json:"contents"`
+	Tools            []map[string]interface{} `json:"tools"`
+	GenerationConfig groundedGenerationConfig `json:"generationConfig"`
+}
+
+type groundedContent struct {
+	Role  string         `json:"role,omitempty"`
+	Parts []groundedPart `json:"parts"`
+}
+
+type groundedPart struct {
+	Text string `json:"text"`
+}
+
+type groundedGenerationConfig struct {
+	Temperature     *float32 `json:"temperature,omitempty"`
+	TopP            *float32 `json:"topP,omitempty"`
+	TopK            *int32   `json:"topK,omitempty"`
+	MaxOutputTokens *int32   `json:"maxOutputTokens,omitempty"`
+}
+
+type groundedResponse struct {
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	Candidates []struct {
+		Content          groundedContent `json:"content"`
+		FinishReason     string          `json:"finishReason"`
+		CitationMetadata *struct {
+			CitationSources []struct {
+				StartIndex *int `json:"startIndex"`
+				EndIndex   *int `json:"endIndex"`
+			} `json:"citationSources"`
+		} `json:"citationMetadata"`
+		SafetyRatings []struct {
+			Category    string `json:"category"`
+			Probability string `json:"probability"`
+			Blocked     bool   `json:"blocked"`
+		} `json:"safetyRatings"`
+		GroundingMetadata *struct {
+			GroundingChunks []struct {
+				Web *struct {
+					URI   string `json:"uri"`
+					Title string `json:"title"`
+				} `json:"web"`
+			} `json:"groundingChunks"`
+		} `json:"groundingMetadata"`
+	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int32 `json:"promptTokenCount"`
+		CandidatesTokenCount int32 `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// groundedFinishReasons maps REST finish reasons onto the SDK's enum
+var groundedFinishReasons = map[string]genai.FinishReason{
+	"STOP":       genai.FinishReasonStop,
+	"MAX_TOKENS": genai.FinishReasonMaxTokens,
+	"SAFETY":     genai.FinishReasonSafety,
+	"RECITATION": genai.FinishReasonRecitation,
+}
+
+// generateGroundedText runs prompt with Google Search grounding enabled and
+// returns the completion along with the web sources the model cited
+func (g *GeminiClient) generateGroundedText(ctx context.Context, model *genai.GenerativeModel, prompt, tenant string) (*completion, error) {
+	reqBody := groundedRequest{
+		Contents: []groundedContent{{Role: "user", Parts: []groundedPart{{Text: prompt}}}},
+		Tools:    []map[string]interface{}{{"google_search": map[string]interface{}{}}},
+		GenerationConfig: groundedGenerationConfig{
+			Temperature:     model.Temperature,
+			TopP:            model.TopP,
+			TopK:            model.TopK,
+			MaxOutputTokens: model.MaxOutputTokens,
+		},
+	}
+
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode grounded request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent?key=%s",
+		generativeLanguageBaseURL, g.modelName, url.QueryEscape(g.current().apiKey))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build grounded request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate grounded content: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grounded response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{op: "grounded generation", code: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+
+	var parsed groundedResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode grounded response: %w", err)
+	}
+
+	if parsed.UsageMetadata != nil {
+		g.observeUsage(ctx, tenant, parsed.UsageMetadata.PromptTokenCount, parsed.UsageMetadata.CandidatesTokenCount)
+	}
+
+	if parsed.PromptFeedback != nil && parsed.PromptFeedback.BlockReason != "" {
+		return nil, &ModerationError{Reason: "PROMPT_" + parsed.PromptFeedback.BlockReason}
+	}
+
+	if len(parsed.Candidates) == 0 {
+		return nil, fmt.Errorf("no content generated")
+	}
+
+	candidate := parsed.Candidates[0]
+
+	finishReason, ok := groundedFinishReasons[candidate.FinishReason]
+	if !ok {
+		finishReason = genai.FinishReasonOther
+	}
+
+	if finishReason == genai.FinishReasonSafety {
+		moderation := &ModerationError{Reason: candidate.FinishReason}
+		for _, rating := range candidate.SafetyRatings {
+			if rating.Blocked || rating.Probability == "MEDIUM" || rating.Probability == "HIGH" {
+				moderation.Categories = append(moderation.Categories, rating.Category)
+			}
+		}
+		return nil, moderation
+	}
+
+	if len(candidate.Content.Parts) == 0 {
+		return nil, fmt.Errorf("no content generated")
+	}
+
+	comp := &completion{finishReason: finishReason}
+
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		text.WriteString(part.Text)
+	}
+	comp.text = text.String()
+
+	if candidate.CitationMetadata != nil {
+		for _, source := range candidate.CitationMetadata.CitationSources {
+			if source.StartIndex != nil && source.EndIndex != nil {
+				comp.citations = append(comp.citations, citationSpan{start: *source.StartIndex, end: *source.EndIndex})
+			}
+		}
+	}
+
+	if candidate.GroundingMetadata != nil {
+		for _, chunk := range candidate.GroundingMetadata.GroundingChunks {
+			if chunk.Web != nil && chunk.Web.URI != "" {
+				comp.sources = append(comp.sources, models.Source{Title: chunk.Web.Title, URI: chunk.Web.URI})
+			}
+		}
+	}
+
+	return comp, nil
+}
+
+// mergeSources concatenates source lists, dropping duplicate URIs
+func mergeSources(lists ...[]models.Source) []models.Source {
+	seen := make(map[string]bool)
+	var merged []models.Source
+
+	for _, list := range lists {
+		for _, source := range list {
+			if seen[source.URI] {
+				continue
+			}
+			seen[source.URI] = true
+			merged = append(merged, source)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,77 @@
+package profiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthCheckInterval is how long a successful model call, or the outcome
+// of a check, vouches for the model before Check asks Gemini again
+const HealthCheckInterval = time.Minute
+
+// modelHealth remembers when the model last answered, so readiness probes
+// rarely need to call Gemini themselves
+type modelHealth struct {
+	// lastSuccess is the UnixNano time of the last successful call
+	lastSuccess atomic.Int64
+
+	// mu serializes checks, so concurrent probes share one call
+	mu        sync.Mutex
+	checkedAt time.Time
+	checkErr  error
+}
+
+// succeeded records a successful model call
+func (h *modelHealth) succeeded() {
+	h.lastSuccess.Store(time.Now().UnixNano())
+}
+
+// recentSuccess reports whether a call succeeded within the interval
+func (h *modelHealth) recentSuccess() bool {
+	return time.Since(time.Unix(0, h.lastSuccess.Load())) < HealthCheckInterval
+}
+
+// Check reports whether the model is reachable with the configured API key.
+// A call that succeeded within HealthCheckInterval is enough; otherwise
+// the model's metadata is fetched, which costs no generation quota, and
+// the outcome reused for the interval so frequent probes don't each call
+// Gemini.
+func (g *GeminiClient) Check(ctx context.Context) error {
+	h := &g.health
+	if h.recentSuccess() {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.recentSuccess() {
+		return nil
+	}
+	if time.Since(h.checkedAt) < HealthCheckInterval {
+		return h.checkErr
+	}
+
+	if _, err := g.current().model.Info(ctx); err != nil {
+		h.checkErr = fmt.Errorf("model %s unavailable: %w", g.modelName, withoutURL(err))
+	} else {
+		h.checkErr = nil
+		h.succeeded()
+	}
+	h.checkedAt = time.Now()
+	return h.checkErr
+}
+
+// withoutURL drops the request URL from transport errors, since it carries
+// the API key and check errors are served by the readiness probe
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
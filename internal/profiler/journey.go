@@ -0,0 +1,115 @@
+package profiler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// JourneyStages are the stages of a journey map, in order
+var JourneyStages = []string{"awareness", "consideration", "purchase", "retention", "advocacy"}
+
+// mapJourneys fills in a journey map for every persona of resp with one
+// more model call
+func (g *GeminiClient) mapJourneys(ctx context.Context, model *genai.GenerativeModel, resp *models.ProfileResponse, opts GenerateOptions) error {
+	var personas strings.Builder
+	for i, profile := range resp.Profiles {
+		fmt.Fprintf(&personas, "Persona %d: %s\n", i+1, formatSimpleProfile(profile))
+	}
+
+	prompt := fmt.Sprintf(`You are an expert market researcher mapping customer journeys for the business idea "%s". These are the customer profiles:
+
+						%s
+						For every persona, describe each journey stage (%s) in one line and nothing else, in this format:
+						persona: 1; stage: awareness; touchpoints: 1-3 comma-separated touchpoints; goal: what the persona wants at this stage; pain_point: what gets in their way; opportunity: what the business can do about it
+
+						Write the lines in persona order and stage order, without markdown.`,
+		redact.ForLLM(resp.BusinessIdea), redact.ForLLM(personas.String()), strings.Join(JourneyStages, ", "))
+
+	if opts.Language != "" {
+		prompt += fmt.Sprintf(`
+
+						Write the values in %s. Keep the keys and the stage names in English.`, opts.Language)
+	}
+
+	opts.Grounded = false
+	opts.Progress = nil
+	comp, err := g.completeWithFinishHandling(ctx, model, prompt, opts)
+	if err != nil {
+		return err
+	}
+
+	journeys := parseJourneys(comp.text, len(resp.Profiles))
+	if len(journeys) == 0 {
+		return fmt.Errorf("journey map missing from model output")
+	}
+	resp.Journeys = journeys
+	return nil
+}
+
+// parseJourneys reads the "persona: N; stage: ...; ..." lines of a journey
+// completion into one journey per persona that has stages, in persona order.
+// Unknown personas and stages are skipped.
+func parseJourneys(text string, count int) []models.Journey {
+	stages := make(map[int][]models.JourneyStage)
+	for _, line := range strings.Split(text, "\n") {
+		fields := make(map[string]string)
+		for _, field := range strings.Split(line, ";") {
+			key, value, ok := strings.Cut(field, ":")
+			if ok {
+				fields[strings.ToLower(strings.Trim(key, " *-"))] = strings.Trim(value, " *")
+			}
+		}
+
+		persona, err := strconv.Atoi(fields["persona"])
+		if err != nil || persona < 1 || persona > count {
+			continue
+		}
+		stage := strings.ToLower(fields["stage"])
+		if journeyStageIndex(stage) < 0 {
+			continue
+		}
+
+		var touchpoints []string
+		for _, touchpoint := range strings.Split(fields["touchpoints"], ",") {
+			if touchpoint = strings.TrimSpace(touchpoint); touchpoint != "" {
+				touchpoints = append(touchpoints, touchpoint)
+			}
+		}
+
+		stages[persona] = append(stages[persona], models.JourneyStage{
+			Stage:       stage,
+			Touchpoints: touchpoints,
+			Goal:        fields["goal"],
+			PainPoint:   fields["pain_point"],
+			Opportunity: fields["opportunity"],
+		})
+	}
+
+	var journeys []models.Journey
+	for persona := 1; persona <= count; persona++ {
+		if len(stages[persona]) == 0 {
+			continue
+		}
+		sort.SliceStable(stages[persona], func(i, j int) bool {
+			return journeyStageIndex(stages[persona][i].Stage) < journeyStageIndex(stages[persona][j].Stage)
+		})
+		journeys = append(journeys, models.Journey{Persona: persona, Stages: stages[persona]})
+	}
+	return journeys
+}
+
+func journeyStageIndex(stage string) int {
+	for i, known := range JourneyStages {
+		if stage == known {
+			return i
+		}
+	}
+	return -1
+}
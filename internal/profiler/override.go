@@ -0,0 +1,59 @@
+package profiler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxPromptOverrideLength caps the number of characters a caller may append
+// to the system prompt.
+const MaxPromptOverrideLength = 500
+
+var ErrPromptOverrideRejected = errors.New("prompt override rejected")
+
+// blockedOverridePhrases are common prompt injection openers. Overrides may
+// refine the persona, not replace the instructions or output format.
+var blockedOverridePhrases = []string{
+	"ignore previous",
+	"ignore all",
+	"ignore the above",
+	"disregard",
+	"forget your instructions",
+	"system prompt",
+	"you are now",
+	"output format",
+	"new instructions",
+}
+
+// SanitizePromptOverride normalizes a caller-supplied prompt override and
+// rejects anything that looks like an attempt to hijack the base prompt.
+func SanitizePromptOverride(override string) (string, error) {
+	// Collapse newlines and control characters so the override can't
+	// fake additional prompt sections.
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, override)
+	cleaned = strings.Join(strings.Fields(cleaned), " ")
+
+	if cleaned == "" {
+		return "", nil
+	}
+
+	if len([]rune(cleaned)) > MaxPromptOverrideLength {
+		return "", fmt.Errorf("%w: exceeds %d characters", ErrPromptOverrideRejected, MaxPromptOverrideLength)
+	}
+
+	lower := strings.ToLower(cleaned)
+	for _, phrase := range blockedOverridePhrases {
+		if strings.Contains(lower, phrase) {
+			return "", fmt.Errorf("%w: contains %q", ErrPromptOverrideRejected, phrase)
+		}
+	}
+
+	return cleaned, nil
+}
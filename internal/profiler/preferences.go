@@ -0,0 +1,147 @@
+package profiler
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+)
+
+const (
+	// MaxCustomFields caps how many extra fields each persona may carry
+	MaxCustomFields = 5
+	// MaxPreferenceLength caps the tone and region preferences in characters
+	MaxPreferenceLength = 100
+	// MaxHints caps how many hints a message may carry
+	MaxHints = 10
+)
+
+// customFieldName is the shape of an extra field key, e.g. "budget" or "tech_savviness"
+var customFieldName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,29}$`)
+
+// builtinFields are the keys of the single-line profile format
+var builtinFields = map[string]bool{
+	"age": true, "gender": true, "location": true, "occupation": true, "income": true,
+	"pain_points": true, "motivations": true, "interests": true, "channel": true,
+}
+
+// ValidateCustomFields checks the names of extra profile fields
+func ValidateCustomFields(fields []string) error {
+	if len(fields) > MaxCustomFields {
+		return fmt.Errorf("at most %d custom fields are allowed", MaxCustomFields)
+	}
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !customFieldName.MatchString(field) {
+			return fmt.Errorf("custom field %q must be lowercase letters, digits and underscores, starting with a letter", field)
+		}
+		if builtinFields[field] || seen[field] {
+			return fmt.Errorf("custom field %q is already a profile field", field)
+		}
+		seen[field] = true
+	}
+	return nil
+}
+
+// SanitizePreference cleans a tone or region preference the same way as a
+// prompt override, with a shorter length limit
+func SanitizePreference(value string) (string, error) {
+	cleaned, err := SanitizePromptOverride(value)
+	if err != nil {
+		return "", err
+	}
+	if len([]rune(cleaned)) > MaxPreferenceLength {
+		return "", fmt.Errorf("%w: exceeds %d characters", ErrPromptOverrideRejected, MaxPreferenceLength)
+	}
+	return cleaned, nil
+}
+
+// SanitizeHints cleans the keys and values of message hints like
+// preferences. Keys and values must not be empty.
+func SanitizeHints(hints map[string]string) (map[string]string, error) {
+	if len(hints) > MaxHints {
+		return nil, fmt.Errorf("at most %d hints are allowed", MaxHints)
+	}
+	cleaned := make(map[string]string, len(hints))
+	for key, value := range hints {
+		cleanKey, err := SanitizePreference(key)
+		if err != nil {
+			return nil, err
+		}
+		cleanValue, err := SanitizePreference(value)
+		if err != nil {
+			return nil, fmt.Errorf("hint %q: %w", cleanKey, err)
+		}
+		if cleanKey == "" || cleanValue == "" {
+			return nil, fmt.Errorf("hint %q must have a key and a value", key)
+		}
+		cleaned[cleanKey] = cleanValue
+	}
+	return cleaned, nil
+}
+
+// hintLines writes hints as sorted "key: value" lines
+func hintLines(hints map[string]string) string {
+	keys := make([]string, 0, len(hints))
+	for key := range hints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&builder, "%s: %s\n", key, hints[key])
+	}
+	return builder.String()
+}
+
+// preferenceInstructions are the prompt sections for a tenant's tone,
+// region and custom fields and the message's brand voice and hints
+func preferenceInstructions(opts GenerateOptions) string {
+	var prompt string
+	if opts.Region != "" {
+		prompt += fmt.Sprintf(`
+
+						The customers are in %s. Reflect it in the location, income and channel values.`, redact.ForLLM(opts.Region))
+	}
+	if opts.Tone != "" {
+		prompt += fmt.Sprintf(`
+
+						Write the values in a %s tone.`, redact.ForLLM(opts.Tone))
+	}
+	if opts.BrandVoice != "" {
+		prompt += fmt.Sprintf(`
+
+						Word the values in this brand voice: %s`, redact.ForLLM(opts.BrandVoice))
+	}
+	if len(opts.Hints) > 0 {
+		prompt += fmt.Sprintf(`
+
+						The caller shared these facts about the business; take them into account:
+						%s`, redact.ForLLM(hintLines(opts.Hints)))
+	}
+	if len(opts.CustomFields) > 0 {
+		prompt += fmt.Sprintf(`
+
+						After channel, also add these keys in this order, each with one short value that contains no commas: %s`, strings.Join(opts.CustomFields, ", "))
+	}
+	return prompt
+}
+
+// customFieldValues writes a profile's custom fields in the single-line format
+func customFieldValues(p models.CustomerProfile) string {
+	keys := make([]string, 0, len(p.Custom))
+	for key := range p.Custom {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&builder, ", %s: %s", key, p.Custom[key])
+	}
+	return builder.String()
+}
@@ -2,69 +2,425 @@ package profiler
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/latency"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
 	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/tracing"
 	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var logger = logging.For("profiler")
+
+// tracer starts the spans of generation and the Gemini calls
+var tracer = otel.Tracer("github.com/BerylCAtieno/customer-profiler-agent/internal/profiler")
+
+// ProfileGenerator produces customer profiles for a business idea.
+// GeminiClient is the production implementation.
+type ProfileGenerator interface {
+	GenerateCustomerProfiles(ctx context.Context, businessIdea string, opts GenerateOptions) (*models.ProfileResponse, error)
+}
+
+// ModelName is the Gemini model used for profile generation unless the
+// client is configured with another
+const ModelName = "gemini-2.5-flash-lite"
+
 type GeminiClient struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
+	conn       atomic.Pointer[geminiConn]
+	modelName  string
+	cache      *ResponseCache
+	httpClient *http.Client
+
+	fallbackEnabled    bool
+	segmentConcurrency int
+	maxInputTokens     int
+
+	maxOutputTokensCeiling int32
+
+	health modelHealth
+	prompt promptTemplate
 }
 
-func NewGeminiClient(apiKey string) (*GeminiClient, error) {
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+// ClientConfig holds optional client behaviour configured at startup
+type ClientConfig struct {
+	// CacheTTL is how long generated profiles are reused for the same
+	// normalized idea. Zero disables the cache.
+	CacheTTL time.Duration
+	// CacheMaxEntries bounds the cache size; zero uses DefaultCacheMaxEntries.
+	CacheMaxEntries int
+	// FallbackEnabled serves a bundled template persona when generation
+	// fails instead of returning the error.
+	FallbackEnabled bool
+	// SegmentConcurrency bounds concurrent model calls when a request asks
+	// for several personas; zero uses DefaultSegmentConcurrency.
+	SegmentConcurrency int
+	// MaxInputTokens is the token budget for the business idea; longer ideas
+	// are condensed before prompting. Zero uses DefaultMaxInputTokens.
+	MaxInputTokens int
+	// MaxOutputTokensCeiling caps the raised output budget used to retry a
+	// truncated response; zero uses DefaultMaxOutputTokensCeiling.
+	MaxOutputTokensCeiling int
+	// Model is the Gemini model to generate with; empty uses ModelName.
+	Model string
+}
+
+func NewGeminiClient(apiKey string, config ClientConfig) (*GeminiClient, error) {
+	modelName := config.Model
+	if modelName == "" {
+		modelName = ModelName
+	}
+
+	var cache *ResponseCache
+	if config.CacheTTL > 0 {
+		cache = NewResponseCache(config.CacheTTL, config.CacheMaxEntries)
+	}
+
+	segmentConcurrency := config.SegmentConcurrency
+	if segmentConcurrency <= 0 {
+		segmentConcurrency = DefaultSegmentConcurrency
+	}
+
+	maxInputTokens := config.MaxInputTokens
+	if maxInputTokens <= 0 {
+		maxInputTokens = DefaultMaxInputTokens
+	}
+
+	maxOutputTokensCeiling := config.MaxOutputTokensCeiling
+	if maxOutputTokensCeiling <= 0 {
+		maxOutputTokensCeiling = DefaultMaxOutputTokensCeiling
 	}
 
-	model := client.GenerativeModel("gemini-2.5-flash-lite")
-	model.SetTemperature(0.7)
-	model.SetTopP(0.95)
-	model.SetMaxOutputTokens(2048)
+	g := &GeminiClient{
+		modelName:  modelName,
+		cache:      cache,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+
+		fallbackEnabled:    config.FallbackEnabled,
+		segmentConcurrency: segmentConcurrency,
+		maxInputTokens:     maxInputTokens,
+
+		maxOutputTokensCeiling: int32(maxOutputTokensCeiling),
+	}
+	conn, err := g.dial(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	g.conn.Store(conn)
+	return g, nil
+}
 
-	return &GeminiClient{
-		client: client,
-		model:  model,
-	}, nil
+// ModelName returns the Gemini model the client generates with
+func (g *GeminiClient) ModelName() string {
+	return g.modelName
 }
 
 func (g *GeminiClient) Close() {
-	g.client.Close()
+	g.current().client.Close()
+}
+
+// GenerateOptions carries per-request knobs for profile generation.
+type GenerateOptions struct {
+	// PromptOverride holds extra caller instructions appended to the prompt.
+	// It must already have passed SanitizePromptOverride.
+	PromptOverride string
+	// NoCache bypasses the response cache for both lookup and storage.
+	NoCache bool
+	// Reproducible pins sampling to greedy decoding and sorts list fields so
+	// repeated runs for the same idea are comparable.
+	Reproducible bool
+	// PersonaCount is the number of personas to generate, one model call
+	// each. Values outside [1, MaxPersonaCount] are clamped.
+	PersonaCount int
+	// Grounded enables Google Search grounding so personas reflect current
+	// market data; cited pages are returned as Sources.
+	Grounded bool
+	// Tenant identifies the calling tenant for usage accounting; empty
+	// means metrics.DefaultTenant.
+	Tenant string
+	// Language is the English name of the language profile values are
+	// written in, e.g. "Spanish"; empty means English.
+	Language string
+	// Progress, if set, is called after each persona is generated with the
+	// number finished so far. Calls may come from several goroutines.
+	Progress func(generated, total int)
+	// Phase, if set, is called with one of the Phase constants as
+	// generation enters each phase
+	Phase func(phase string)
+	// Previous, if set, is refined instead of starting over: the business
+	// idea argument is read as a follow-up instruction such as "make persona
+	// 2 younger", and every persona of Previous is rewritten to follow it.
+	// Refinements bypass the cache and the fallback.
+	Previous *models.ProfileResponse
+	// Summary asks for a market summary and keyword list, at the cost of
+	// one more model call. A failed summary is logged and left empty.
+	Summary bool
+	// Readback checks every persona against the idea with one more model
+	// call, then rewrites those that don't fit with one call each. A failed
+	// check is logged and the personas are returned unchecked.
+	Readback bool
+	// Journeys adds a journey map for every persona with one more model
+	// call. With Previous set, its personas are mapped as they are instead
+	// of being refined, and the business idea argument is ignored.
+	Journeys bool
+	// Tone and Region steer the wording and market of every persona, and
+	// CustomFields names extra values to add to each. They come from tenant
+	// preferences and must have passed SanitizePreference and
+	// ValidateCustomFields.
+	Tone         string
+	Region       string
+	CustomFields []string
+	// BrandVoice and Hints come from the caller's message metadata: the
+	// voice to write in and facts about the business, such as its industry
+	// or audience. They must have passed SanitizePreference and
+	// SanitizeHints.
+	BrandVoice string
+	Hints      map[string]string
+	// Latency, if set, records time spent calling the model, parsing its
+	// output and building the interest graph.
+	Latency *latency.Breakdown
+	// SoftLimits truncates an idea too large to condense instead of
+	// failing with ErrInputTooLarge, and records a warning.
+	SoftLimits bool
+}
+
+// CacheStats reports response cache effectiveness; ok is false when caching is disabled
+func (g *GeminiClient) CacheStats() (stats CacheStats, ok bool) {
+	if g.cache == nil {
+		return CacheStats{}, false
+	}
+	return g.cache.Stats(), true
 }
 
-func (g *GeminiClient) GenerateCustomerProfiles(ctx context.Context, businessIdea string) (*models.ProfileResponse, error) {
-	prompt := g.buildPrompt(businessIdea)
+// FlushCache empties the response cache and returns how many entries it
+// dropped; ok is false when caching is disabled
+func (g *GeminiClient) FlushCache() (flushed int, ok bool) {
+	if g.cache == nil {
+		return 0, false
+	}
+	return g.cache.Flush(), true
+}
+
+func (g *GeminiClient) GenerateCustomerProfiles(ctx context.Context, businessIdea string, opts GenerateOptions) (*models.ProfileResponse, error) {
+	ctx, span := tracer.Start(ctx, "profiler.generate", trace.WithAttributes(attribute.String("gen_ai.request.model", g.modelName)))
+	defer span.End()
+
+	useCache := g.cache != nil && !opts.NoCache && opts.Previous == nil
+
+	var key string
+	if useCache {
+		key = cacheKey(businessIdea, opts)
+		cached, hit := g.cache.Get(key)
+		logCacheResult(ctx, hit, g.cache.Stats())
+		span.SetAttributes(attribute.Bool("profiler.cache_hit", hit))
+		if hit {
+			if !cached.InputCondensed {
+				cached.BusinessIdea = businessIdea
+			}
+			return cached, nil
+		}
+	}
 
-	resp, err := g.model.GenerateContent(ctx, genai.Text(prompt))
+	resp, err := g.generate(ctx, businessIdea, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
+		if g.fallbackEnabled && opts.Previous == nil && modelUnavailable(err) {
+			logger.WarnContext(ctx, "Generation failed, serving fallback template", "error", err)
+			span.SetAttributes(attribute.Bool("profiler.fallback", true))
+			return FallbackProfiles(businessIdea), nil
+		}
+		tracing.Fail(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("profiler.profiles", len(resp.Profiles)))
+
+	if useCache {
+		g.cache.Set(key, resp)
+	}
+
+	return resp, nil
+}
+
+func (g *GeminiClient) generate(ctx context.Context, businessIdea string, opts GenerateOptions) (*models.ProfileResponse, error) {
+	model := g.modelFor(opts)
+	if opts.Journeys && opts.Previous != nil {
+		return g.mapPrevious(ctx, model, opts)
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no content generated")
+	stopLLM := opts.Latency.Track(latency.StageLLM)
+	defer stopLLM()
+
+	var warnings []string
+	opts.enter(PhaseAnalyzing)
+	businessIdea, condensed, err := g.fitInputBudget(ctx, businessIdea)
+	if errors.Is(err, ErrInputTooLarge) && opts.SoftLimits {
+		logger.WarnContext(ctx, "Input over budget; truncating under soft limits", "error", err)
+		warnings = append(warnings, fmt.Sprintf("%v; the business idea was truncated", err))
+		businessIdea, condensed, err = truncateToTokens(businessIdea, g.maxInputTokens), true, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	text := fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0])
+	var prompts []string
+	if opts.Previous != nil {
+		prompts = g.refinePrompts(opts.Previous, businessIdea, opts)
+	} else {
+		prompts = g.segmentPrompts(businessIdea, opts)
+	}
 
-	profile, err := g.parseSimpleProfile(text)
+	opts.enter(PhaseGenerating)
+	results, err := g.generateSegments(ctx, model, prompts, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse simple profile: %w", err)
+		return nil, err
 	}
+	stopLLM()
 
-	return &models.ProfileResponse{
+	profiles := make([]models.CustomerProfile, len(results))
+	sources := make([][]models.Source, len(results))
+	for i, result := range results {
+		profiles[i] = result.profile
+		sources[i] = result.sources
+	}
+
+	profileResp := &models.ProfileResponse{
 		BusinessIdea: businessIdea,
-		Profiles:     []models.CustomerProfile{*profile},
+		Profiles:     profiles,
 		Summary:      "",
 		Keywords:     []string{},
-	}, nil
+		Sources:      mergeSources(sources...),
+		Warnings:     warnings,
+
+		InputCondensed: condensed,
+	}
+
+	if previous := opts.Previous; previous != nil {
+		profileResp.BusinessIdea = previous.BusinessIdea
+		profileResp.InputCondensed = previous.InputCondensed
+		profileResp.Sources = mergeSources(previous.Sources, profileResp.Sources)
+		profileResp.Refinement = businessIdea
+	}
+
+	if opts.Readback {
+		opts.enter(PhaseCheckingFit)
+		stop := opts.Latency.Track(latency.StageLLM)
+		err := g.readback(ctx, model, profileResp, opts)
+		stop()
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to read back profiles", "error", err)
+		}
+	}
+
+	stopEnrich := opts.Latency.Track(latency.StageEnrich)
+	profileResp.InterestGraph = BuildInterestGraph(profileResp.Profiles)
+	stopEnrich()
+
+	if opts.Journeys {
+		opts.enter(PhaseMappingJourneys)
+		stop := opts.Latency.Track(latency.StageLLM)
+		err := g.mapJourneys(ctx, model, profileResp, opts)
+		stop()
+		if err != nil {
+			return nil, fmt.Errorf("failed to map customer journeys: %w", err)
+		}
+	}
+
+	if opts.Summary {
+		opts.enter(PhaseSummarizing)
+		stop := opts.Latency.Track(latency.StageLLM)
+		err := g.summarize(ctx, model, profileResp, opts)
+		stop()
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to summarize profiles", "error", err)
+		}
+	}
+
+	if opts.Reproducible {
+		defer opts.Latency.Track(latency.StageParseOutput)()
+		sortProfileLists(profileResp)
+		profileResp.Reproducible = true
+		profileResp.PromptHash = hashPrompt(strings.Join(prompts, "\x00"))
+	}
+
+	return profileResp, nil
+}
+
+// Phases of a generation reported through GenerateOptions.Phase
+const (
+	PhaseAnalyzing       = "analyzing"
+	PhaseGenerating      = "generating"
+	PhaseCheckingFit     = "checking-fit"
+	PhaseMappingJourneys = "mapping-journeys"
+	PhaseSummarizing     = "summarizing"
+)
+
+// enter reports that generation entered phase
+func (opts GenerateOptions) enter(phase string) {
+	if opts.Phase != nil {
+		opts.Phase(phase)
+	}
 }
 
-func (g *GeminiClient) parseSimpleProfile(text string) (*models.CustomerProfile, error) {
+// modelFor returns the model to generate with, pinned to greedy decoding
+// for reproducible requests
+func (g *GeminiClient) modelFor(opts GenerateOptions) *genai.GenerativeModel {
+	if !opts.Reproducible {
+		return g.current().model
+	}
+	// Copy the model so the shared sampling config stays untouched
+	pinned := *g.current().model
+	pinned.SetTemperature(0)
+	pinned.SetTopK(1)
+	return &pinned
+}
+
+// mapPrevious adds journey maps to a copy of opts.Previous without
+// regenerating its personas
+func (g *GeminiClient) mapPrevious(ctx context.Context, model *genai.GenerativeModel, opts GenerateOptions) (*models.ProfileResponse, error) {
+	profileResp := *opts.Previous
+	profileResp.Profiles = append([]models.CustomerProfile(nil), opts.Previous.Profiles...)
+	profileResp.Refinement = ""
+
+	opts.enter(PhaseMappingJourneys)
+	defer opts.Latency.Track(latency.StageLLM)()
+	if err := g.mapJourneys(ctx, model, &profileResp, opts); err != nil {
+		return nil, fmt.Errorf("failed to map customer journeys: %w", err)
+	}
+	return &profileResp, nil
+}
+
+// sortProfileLists trims and sorts every list field so output order doesn't
+// depend on how the model happened to enumerate items
+func sortProfileLists(resp *models.ProfileResponse) {
+	for i := range resp.Profiles {
+		p := &resp.Profiles[i]
+		for _, list := range [][]string{p.Motivations, p.Interests, p.PainPoints, p.BuyingBehaviors, p.PreferredChannels} {
+			for j := range list {
+				list[j] = strings.TrimSpace(list[j])
+			}
+			sort.Strings(list)
+		}
+	}
+	sort.Strings(resp.Keywords)
+}
+
+func hashPrompt(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+func (g *GeminiClient) parseSimpleProfile(text string, customFields []string) (*models.CustomerProfile, error) {
 	profile := models.CustomerProfile{}
 
 	text = strings.TrimSpace(text)
@@ -94,23 +450,41 @@ func (g *GeminiClient) parseSimpleProfile(text string) (*models.CustomerProfile,
 
 	profile.PreferredChannels = []string{data["channel"]}
 
+	for _, field := range customFields {
+		if value := data[field]; value != "" {
+			if profile.Custom == nil {
+				profile.Custom = make(map[string]string, len(customFields))
+			}
+			profile.Custom[field] = value
+		}
+	}
+
 	return &profile, nil
 }
 
-func (g *GeminiClient) buildPrompt(businessIdea string) string {
-	return fmt.Sprintf(`You are an expert market researcher. Based ONLY on the business idea "%s", generate a SINGLE, concise customer profile.
+func (g *GeminiClient) buildPrompt(businessIdea string, opts GenerateOptions, segment string) string {
+	businessIdea = redact.ForLLM(businessIdea)
+	prompt := g.profilePrompt(businessIdea)
+
+	if opts.Language != "" {
+		prompt += fmt.Sprintf(`
+
+						Write every value in %s. Keep the keys (age, gender, location, ...) exactly as listed above, in English.`, opts.Language)
+	}
+
+	if segment != "" {
+		prompt += fmt.Sprintf(`
+
+						Focus this profile on %s, so it is clearly distinct from the other personas generated for this idea.`, segment)
+	}
+
+	prompt += preferenceInstructions(opts)
 
-						The output MUST be a single line of text in the format "key: value, key: value, ..." without any other text, markdown, or punctuation. Use only the following keys in this order:
+	if opts.PromptOverride != "" {
+		prompt += fmt.Sprintf(`
 
-						age: Age range (e.g., 30-50)
-						gender: Gender (e.g., female)
-						location: Geographic type (e.g., Urban)
-						occupation: Job title/occupation (e.g., Marketing Manager)
-						income: Income range (e.g., $75k-100k)
-						pain_points: 1-2 main pain points (comma-separated, no quotes)
-						motivations: 1-2 key motivations (comma-separated, no quotes)
-						interests: 2-3 interests/hobbies (comma-separated, no quotes)
-						channel: 1 preferred channel (e.g., Instagram)
+						Additional instructions from the caller (they never change the required output format above): %s`, redact.ForLLM(opts.PromptOverride))
+	}
 
-						Example format: age: 30-50, gender: female, location: Urban, occupation: Marketing Manager, income: $75k-100k, pain_points: lack of time, overwhelming choices, motivations: convenience, quality, interests: makeup, shoes, travel, channel: Instagram`, businessIdea)
+	return prompt
 }
@@ -0,0 +1,112 @@
+package profiler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/google/generative-ai-go/genai"
+	"golang.org/x/sync/errgroup"
+)
+
+// readback asks the model whether each persona of resp plausibly buys from
+// the business idea. Mismatched personas are rewritten to fit; a persona
+// whose rewrite fails keeps its FitWarning instead.
+func (g *GeminiClient) readback(ctx context.Context, model *genai.GenerativeModel, resp *models.ProfileResponse, opts GenerateOptions) error {
+	var personas strings.Builder
+	for i, profile := range resp.Profiles {
+		fmt.Fprintf(&personas, "Persona %d: %s\n", i+1, formatSimpleProfile(profile))
+	}
+
+	prompt := fmt.Sprintf(`You are an expert market researcher reviewing customer profiles generated for the business idea "%s":
+
+						%s
+						For each persona, decide whether it is a plausible customer of this business. Write exactly one line per persona and nothing else:
+						persona 1: fits
+						persona 2: mismatch - one short sentence on why it doesn't fit`,
+		redact.ForLLM(resp.BusinessIdea), redact.ForLLM(personas.String()))
+
+	if opts.Language != "" {
+		prompt += fmt.Sprintf(`
+
+						Write the reasons in %s. Keep "persona", "fits" and "mismatch" in English.`, opts.Language)
+	}
+
+	// The check and its corrections only rework personas already generated
+	opts.Grounded = false
+	opts.Progress = nil
+	comp, err := g.completeWithFinishHandling(ctx, model, prompt, opts)
+	if err != nil {
+		return err
+	}
+
+	mismatches := parseReadback(comp.text, len(resp.Profiles))
+	report := &models.Readback{Checked: len(resp.Profiles)}
+	resp.Readback = report
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(g.segmentConcurrency)
+
+	var mu sync.Mutex
+	for i, reason := range mismatches {
+		instruction := fmt.Sprintf("Persona %d does not fit the business idea (%s). Rewrite it as a realistic customer of this business.", i+1, reason)
+		result := segmentResult{prompt: g.buildRefinePrompt(resp.BusinessIdea, personas.String(), instruction, i+1, opts)}
+
+		group.Go(func() error {
+			err := g.generateSegment(groupCtx, model, opts, &result)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logger.WarnContext(ctx, "Failed to correct persona, flagging it", "persona", i+1, "error", err)
+				resp.Profiles[i].FitWarning = reason
+				report.Flagged++
+				return nil
+			}
+			resp.Profiles[i] = result.profile
+			report.Corrected++
+			return nil
+		})
+	}
+	return group.Wait()
+}
+
+// parseReadback reads the "persona N: mismatch - reason" lines of a readback
+// completion into reasons keyed by persona index. Lines for unknown personas
+// and personas that fit are skipped.
+func parseReadback(text string, count int) map[int]string {
+	mismatches := make(map[int]string)
+	for _, line := range strings.Split(text, "\n") {
+		label, verdict, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(strings.ToLower(strings.Trim(label, " *")))
+		if len(fields) != 2 || fields[0] != "persona" {
+			continue
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 1 || n > count {
+			continue
+		}
+
+		verdict = strings.TrimSpace(verdict)
+		if !strings.HasPrefix(strings.ToLower(verdict), "mismatch") {
+			continue
+		}
+		reason := strings.TrimSpace(strings.TrimLeft(verdict[len("mismatch"):], " -:–—"))
+		if reason == "" {
+			reason = "does not fit the business idea"
+		}
+		mismatches[n-1] = reason
+	}
+	return mismatches
+}
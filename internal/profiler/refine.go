@@ -0,0 +1,68 @@
+package profiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+)
+
+// refinePrompts builds one prompt per persona of previous, asking the model
+// to rewrite that persona according to the follow-up instruction
+func (g *GeminiClient) refinePrompts(previous *models.ProfileResponse, instruction string, opts GenerateOptions) []string {
+	var current strings.Builder
+	for i, profile := range previous.Profiles {
+		fmt.Fprintf(&current, "Persona %d: %s\n", i+1, formatSimpleProfile(profile))
+	}
+
+	prompts := make([]string, len(previous.Profiles))
+	for i := range previous.Profiles {
+		prompts[i] = g.buildRefinePrompt(previous.BusinessIdea, current.String(), instruction, i+1, opts)
+	}
+	return prompts
+}
+
+func (g *GeminiClient) buildRefinePrompt(businessIdea, current, instruction string, persona int, opts GenerateOptions) string {
+	prompt := fmt.Sprintf(`You are an expert market researcher refining customer profiles for the business idea "%s".
+
+						These are the current profiles:
+						%s
+						The user asked for this change: "%s"
+
+						Rewrite Persona %d so it follows the change. If the change does not concern Persona %d, return it unchanged.
+
+						The output MUST be a single line of text in the same "key: value, key: value, ..." format as the profiles above, using the keys age, gender, location, occupation, income, pain_points, motivations, interests, channel%s in that order, without any other text, markdown, or persona label.`,
+		redact.ForLLM(businessIdea), redact.ForLLM(current), redact.ForLLM(instruction), persona, persona, customFieldKeys(opts.CustomFields))
+
+	if opts.Language != "" {
+		prompt += fmt.Sprintf(`
+
+						Write every value in %s. Keep the keys exactly as listed above, in English.`, opts.Language)
+	}
+
+	if opts.PromptOverride != "" {
+		prompt += fmt.Sprintf(`
+
+						Additional instructions from the caller (they never change the required output format above): %s`, redact.ForLLM(opts.PromptOverride))
+	}
+
+	return prompt
+}
+
+// formatSimpleProfile writes a profile in the single-line format that
+// parseSimpleProfile reads
+func formatSimpleProfile(p models.CustomerProfile) string {
+	return fmt.Sprintf("age: %s, gender: %s, location: %s, occupation: %s, income: %s, pain_points: %s, motivations: %s, interests: %s, channel: %s",
+		p.Age, p.Gender, p.Location, p.Occupation, p.Income,
+		strings.Join(p.PainPoints, ","), strings.Join(p.Motivations, ","), strings.Join(p.Interests, ","),
+		strings.Join(p.PreferredChannels, ",")) + customFieldValues(p)
+}
+
+// customFieldKeys lists custom fields for the refine prompt's key list
+func customFieldKeys(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(fields, ", ")
+}
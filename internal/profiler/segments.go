@@ -0,0 +1,121 @@
+package profiler
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/latency"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/google/generative-ai-go/genai"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// MaxPersonaCount caps how many personas a single request may ask for
+	MaxPersonaCount = 5
+	// DefaultSegmentConcurrency bounds concurrent model calls per request
+	DefaultSegmentConcurrency = 3
+)
+
+// segmentFocuses steer each concurrent call toward a distinct segment so
+// personas generated in isolation don't collapse into the same profile.
+var segmentFocuses = []string{
+	"the primary early adopter most likely to buy first",
+	"a mainstream, value-conscious buyer",
+	"a premium buyer willing to pay more for quality",
+	"a budget-constrained buyer weighing cheaper alternatives",
+	"an organizational or business buyer purchasing for a team",
+}
+
+// personaCount clamps the requested number of personas to [1, MaxPersonaCount]
+func (o GenerateOptions) personaCount() int {
+	switch {
+	case o.PersonaCount < 1:
+		return 1
+	case o.PersonaCount > MaxPersonaCount:
+		return MaxPersonaCount
+	default:
+		return o.PersonaCount
+	}
+}
+
+// segmentFocus returns the steering hint for persona i of count, or "" when
+// only one persona is requested
+func segmentFocus(i, count int) string {
+	if count <= 1 {
+		return ""
+	}
+	return segmentFocuses[i%len(segmentFocuses)]
+}
+
+// segmentResult is the outcome of generating one persona
+type segmentResult struct {
+	profile models.CustomerProfile
+	prompt  string
+	sources []models.Source
+}
+
+// segmentPrompts builds one prompt per requested persona
+func (g *GeminiClient) segmentPrompts(businessIdea string, opts GenerateOptions) []string {
+	count := opts.personaCount()
+	prompts := make([]string, count)
+	for i := range prompts {
+		prompts[i] = g.buildPrompt(businessIdea, opts, segmentFocus(i, count))
+	}
+	return prompts
+}
+
+// generateSegments issues one model call per prompt, bounded by the
+// client's segment concurrency, and returns results in prompt order.
+func (g *GeminiClient) generateSegments(ctx context.Context, model *genai.GenerativeModel, prompts []string, opts GenerateOptions) ([]segmentResult, error) {
+	count := len(prompts)
+	results := make([]segmentResult, count)
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(g.segmentConcurrency)
+
+	var generated atomic.Int32
+
+	for i := 0; i < count; i++ {
+		results[i].prompt = prompts[i]
+
+		group.Go(func() error {
+			if err := g.generateSegment(groupCtx, model, opts, &results[i]); err != nil {
+				if count > 1 {
+					return fmt.Errorf("segment %d: %w", i+1, err)
+				}
+				return err
+			}
+			if opts.Progress != nil {
+				opts.Progress(int(generated.Add(1)), count)
+			}
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// generateSegment runs result.prompt and fills in the parsed profile and any sources
+func (g *GeminiClient) generateSegment(ctx context.Context, model *genai.GenerativeModel, opts GenerateOptions, result *segmentResult) error {
+	comp, err := g.completeWithFinishHandling(ctx, model, result.prompt, opts)
+	if err != nil {
+		return err
+	}
+	result.sources = comp.sources
+
+	stopParse := opts.Latency.Track(latency.StageParseOutput)
+	profile, err := g.parseSimpleProfile(comp.text, opts.CustomFields)
+	stopParse()
+	if err != nil {
+		return fmt.Errorf("failed to parse simple profile: %w", err)
+	}
+
+	result.profile = *profile
+	return nil
+}
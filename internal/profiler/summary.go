@@ -0,0 +1,76 @@
+package profiler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// maxKeywords caps the keyword list of a summary
+const maxKeywords = 8
+
+// summarize fills in the market summary and keywords of resp with one more
+// model call
+func (g *GeminiClient) summarize(ctx context.Context, model *genai.GenerativeModel, resp *models.ProfileResponse, opts GenerateOptions) error {
+	var personas strings.Builder
+	for i, profile := range resp.Profiles {
+		fmt.Fprintf(&personas, "Persona %d: %s\n", i+1, formatSimpleProfile(profile))
+	}
+
+	prompt := fmt.Sprintf(`You are an expert market researcher. These customer profiles were generated for the business idea "%s":
+
+						%s
+						Write exactly two lines and nothing else:
+						summary: two sentences on who the target market is and how to reach it
+						keywords: up to %d short comma-separated keywords for the target market`,
+		redact.ForLLM(resp.BusinessIdea), redact.ForLLM(personas.String()), maxKeywords)
+
+	if opts.Language != "" {
+		prompt += fmt.Sprintf(`
+
+						Write the summary and keywords in %s. Keep the line prefixes "summary:" and "keywords:" in English.`, opts.Language)
+	}
+
+	// Search grounding adds nothing when summarizing profiles already generated
+	opts.Grounded = false
+	comp, err := g.completeWithFinishHandling(ctx, model, prompt, opts)
+	if err != nil {
+		return err
+	}
+
+	summary, keywords := parseSummary(comp.text)
+	if summary == "" {
+		return fmt.Errorf("summary missing from model output")
+	}
+	resp.Summary = summary
+	resp.Keywords = keywords
+	return nil
+}
+
+// parseSummary reads the "summary:" and "keywords:" lines of a summary completion
+func parseSummary(text string) (string, []string) {
+	var summary string
+	keywords := []string{}
+
+	for _, line := range strings.Split(text, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.Trim(key, " *")) {
+		case "summary":
+			summary = strings.TrimSpace(value)
+		case "keywords":
+			for _, keyword := range strings.Split(value, ",") {
+				if keyword = strings.TrimSpace(keyword); keyword != "" && len(keywords) < maxKeywords {
+					keywords = append(keywords, keyword)
+				}
+			}
+		}
+	}
+	return summary, keywords
+}
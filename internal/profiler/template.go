@@ -0,0 +1,110 @@
+package profiler
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"text/template"
+)
+
+// DefaultProfilePrompt is the template of the prompt generating each
+// persona. Templates get the business idea as {{.BusinessIdea}}; the
+// language, segment, preference and override instructions are appended
+// after it.
+const DefaultProfilePrompt = `You are an expert market researcher. Based ONLY on the business idea "{{.BusinessIdea}}", generate a SINGLE, concise customer profile.
+
+						The output MUST be a single line of text in the format "key: value, key: value, ..." without any other text, markdown, or punctuation. Use only the following keys in this order:
+
+						age: Age range (e.g., 30-50)
+						gender: Gender (e.g., female)
+						location: Geographic type (e.g., Urban)
+						occupation: Job title/occupation (e.g., Marketing Manager)
+						income: Income range (e.g., $75k-100k)
+						pain_points: 1-2 main pain points (comma-separated, no quotes)
+						motivations: 1-2 key motivations (comma-separated, no quotes)
+						interests: 2-3 interests/hobbies (comma-separated, no quotes)
+						channel: 1 preferred channel (e.g., Instagram)
+
+						Example format: age: 30-50, gender: female, location: Urban, occupation: Marketing Manager, income: $75k-100k, pain_points: lack of time, overwhelming choices, motivations: convenience, quality, interests: makeup, shoes, travel, channel: Instagram`
+
+// defaultProfileTemplate is DefaultProfilePrompt parsed
+var defaultProfileTemplate = template.Must(parseProfilePrompt(DefaultProfilePrompt))
+
+// profilePromptData is what profile prompt templates are executed with
+type profilePromptData struct {
+	BusinessIdea string
+}
+
+// promptTemplate holds the profile prompt template, swapped in whole
+type promptTemplate struct {
+	current atomic.Pointer[template.Template]
+}
+
+func parseProfilePrompt(text string) (*template.Template, error) {
+	return template.New("profile").Option("missingkey=error").Parse(text)
+}
+
+// ProfilePrompt is a checked profile prompt template, ready to be swapped
+// in with UseProfilePrompt
+type ProfilePrompt struct {
+	tmpl *template.Template
+}
+
+// ParseProfilePrompt checks a profile prompt template; empty text is
+// DefaultProfilePrompt. The template must use {{.BusinessIdea}} and ask for
+// the format parseProfile reads.
+func ParseProfilePrompt(text string) (*ProfilePrompt, error) {
+	if text == "" {
+		return &ProfilePrompt{tmpl: defaultProfileTemplate}, nil
+	}
+	tmpl, err := parseProfilePrompt(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile prompt template: %w", err)
+	}
+	const marker = "\x00idea\x00"
+	var b strings.Builder
+	if err := tmpl.Execute(&b, profilePromptData{BusinessIdea: marker}); err != nil {
+		return nil, fmt.Errorf("invalid profile prompt template: %w", err)
+	}
+	if !strings.Contains(b.String(), marker) {
+		return nil, fmt.Errorf("invalid profile prompt template: it must include {{.BusinessIdea}}")
+	}
+	return &ProfilePrompt{tmpl: tmpl}, nil
+}
+
+// SetProfilePrompt replaces the profile prompt template with text, checked
+// as by ParseProfilePrompt
+func (g *GeminiClient) SetProfilePrompt(text string) error {
+	prompt, err := ParseProfilePrompt(text)
+	if err != nil {
+		return err
+	}
+	g.UseProfilePrompt(prompt)
+	return nil
+}
+
+// UseProfilePrompt replaces the profile prompt template. Generations in
+// flight keep the prompt they started with. The response cache is flushed,
+// since its entries came from the old prompt.
+func (g *GeminiClient) UseProfilePrompt(prompt *ProfilePrompt) {
+	g.prompt.current.Store(prompt.tmpl)
+	if g.cache != nil {
+		g.cache.Flush()
+	}
+}
+
+// profilePrompt renders the current profile prompt template for an idea
+// already redacted for the model
+func (g *GeminiClient) profilePrompt(businessIdea string) string {
+	tmpl := g.prompt.current.Load()
+	if tmpl == nil {
+		tmpl = defaultProfileTemplate
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, profilePromptData{BusinessIdea: businessIdea}); err != nil {
+		logger.Error("Failed to render the profile prompt template; using the default", "error", err)
+		b.Reset()
+		_ = defaultProfileTemplate.Execute(&b, profilePromptData{BusinessIdea: businessIdea})
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+// Package profilestore keeps every generated ProfileResponse, with the
+// artifacts it was served as, who asked for it and how long it took, in a
+// database, for history, analytics and export.
+package profilestore
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/models"
+)
+
+// Record is one generation: the profiles of a completed task
+type Record struct {
+	TaskID       string `json:"taskId"`
+	ContextID    string `json:"contextId,omitempty"`
+	BusinessIdea string `json:"businessIdea"`
+	// Caller is the authenticated caller, as method:name, and Tenant the
+	// caller's X-Tenant-ID; either may be empty
+	Caller    string                 `json:"caller,omitempty"`
+	Tenant    string                 `json:"tenant,omitempty"`
+	Response  models.ProfileResponse `json:"response"`
+	Artifacts []a2a.Artifact         `json:"artifacts,omitempty"`
+	// Latency is the per-stage breakdown, keyed like "llmMs" and "totalMs"
+	Latency    map[string]int64 `json:"latency,omitempty"`
+	StartedAt  time.Time        `json:"startedAt"`
+	FinishedAt time.Time        `json:"finishedAt"`
+}
+
+// Duration is how long the generation took, from parsing the message to
+// the finished task
+func (r Record) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Page sizes of List
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ErrInvalidCursor is returned by List for a cursor it didn't issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Filter selects the records List returns, newest first
+type Filter struct {
+	// Caller and Tenant, if set, must match exactly
+	Caller string
+	Tenant string
+	// Since and Until, if set, bound when generations finished; Since is
+	// inclusive and Until exclusive
+	Since time.Time
+	Until time.Time
+	// Cursor continues from the page that returned it
+	Cursor string
+	// Limit is the page size, DefaultPageSize if zero, at most MaxPageSize
+	Limit int
+}
+
+// pageSize is the filter's page size within bounds
+func (f Filter) pageSize() int {
+	switch {
+	case f.Limit <= 0:
+		return DefaultPageSize
+	case f.Limit > MaxPageSize:
+		return MaxPageSize
+	}
+	return f.Limit
+}
+
+// Page is one page of a listing. Next is the cursor of the following
+// page, or empty on the last one.
+type Page struct {
+	Records []Record
+	Next    string
+}
+
+// Repository stores generation records. Saving a task again, e.g. once
+// refined, replaces its record.
+type Repository interface {
+	Save(ctx context.Context, record Record) error
+	Get(ctx context.Context, taskID string) (Record, bool, error)
+	// List returns a page of the records matching filter, without their
+	// artifacts
+	List(ctx context.Context, filter Filter) (Page, error)
+}
+
+// cursor is the position after the last record of a page. Records are
+// ordered by finish time, then task ID, so ties don't repeat or skip.
+type cursor struct {
+	finishedAt time.Time
+	taskID     string
+}
+
+func (c cursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.finishedAt.UTC().Format(time.RFC3339Nano) + "|" + c.taskID))
+}
+
+func decodeCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	at, taskID, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return cursor{}, ErrInvalidCursor
+	}
+	finishedAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	return cursor{finishedAt: finishedAt, taskID: taskID}, nil
+}
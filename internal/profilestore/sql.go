@@ -0,0 +1,192 @@
+package profilestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/migrate"
+)
+
+// SQLRepository is a Repository in the profiles table of a PostgreSQL or
+// SQLite database, created by migration 0002. The response, artifacts and
+// latency are stored as JSON text, and times in UTC to the microsecond,
+// PostgreSQL's precision, so cursors match stored times exactly.
+type SQLRepository struct {
+	db      *sql.DB
+	dialect migrate.Dialect
+}
+
+func NewSQLRepository(db *sql.DB, dialect migrate.Dialect) *SQLRepository {
+	return &SQLRepository{db: db, dialect: dialect}
+}
+
+// bind rewrites the ? parameters of query into the repository's dialect
+func (r *SQLRepository) bind(query string) string {
+	if r.dialect != migrate.Postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, ch := range query {
+		if ch == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+const profileColumns = `task_id, context_id, business_idea, caller, tenant, profile_count, degraded,
+	response, artifacts, latency, started_at, finished_at, duration_ms`
+
+func (r *SQLRepository) Save(ctx context.Context, record Record) error {
+	response, err := json.Marshal(record.Response)
+	if err != nil {
+		return fmt.Errorf("failed to encode the profiles of task %s: %w", record.TaskID, err)
+	}
+	artifacts, err := json.Marshal(record.Artifacts)
+	if err != nil {
+		return fmt.Errorf("failed to encode the artifacts of task %s: %w", record.TaskID, err)
+	}
+	latency, err := json.Marshal(record.Latency)
+	if err != nil {
+		return fmt.Errorf("failed to encode the latency of task %s: %w", record.TaskID, err)
+	}
+
+	_, err = r.db.ExecContext(ctx, r.bind(`INSERT INTO profiles (`+profileColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (task_id) DO UPDATE SET
+			context_id = excluded.context_id, business_idea = excluded.business_idea,
+			caller = excluded.caller, tenant = excluded.tenant,
+			profile_count = excluded.profile_count, degraded = excluded.degraded,
+			response = excluded.response, artifacts = excluded.artifacts, latency = excluded.latency,
+			started_at = excluded.started_at, finished_at = excluded.finished_at,
+			duration_ms = excluded.duration_ms`),
+		record.TaskID, record.ContextID, record.BusinessIdea, record.Caller, record.Tenant,
+		len(record.Response.Profiles), record.Response.Degraded,
+		string(response), string(artifacts), string(latency),
+		storedTime(record.StartedAt), storedTime(record.FinishedAt), record.Duration().Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to save the profiles of task %s: %w", record.TaskID, err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) Get(ctx context.Context, taskID string) (Record, bool, error) {
+	row := r.db.QueryRowContext(ctx, r.bind(`SELECT `+profileColumns+` FROM profiles WHERE task_id = ?`), taskID)
+	record, err := scanRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Record{}, false, nil
+	}
+	if err != nil {
+		return Record{}, false, fmt.Errorf("failed to load the profiles of task %s: %w", taskID, err)
+	}
+	return record, true, nil
+}
+
+func (r *SQLRepository) List(ctx context.Context, filter Filter) (Page, error) {
+	var where []string
+	var args []interface{}
+	if filter.Caller != "" {
+		where = append(where, "caller = ?")
+		args = append(args, filter.Caller)
+	}
+	if filter.Tenant != "" {
+		where = append(where, "tenant = ?")
+		args = append(args, filter.Tenant)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "finished_at >= ?")
+		args = append(args, storedTime(filter.Since))
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "finished_at < ?")
+		args = append(args, storedTime(filter.Until))
+	}
+	if filter.Cursor != "" {
+		after, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		where = append(where, "(finished_at < ? OR (finished_at = ? AND task_id < ?))")
+		args = append(args, storedTime(after.finishedAt), storedTime(after.finishedAt), after.taskID)
+	}
+
+	// One extra row tells whether another page follows
+	size := filter.pageSize()
+	query := `SELECT ` + profileColumns + ` FROM profiles`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(` ORDER BY finished_at DESC, task_id DESC LIMIT %d`, size+1)
+
+	rows, err := r.db.QueryContext(ctx, r.bind(query), args...)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var page Page
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return Page{}, fmt.Errorf("failed to list profiles: %w", err)
+		}
+		record.Artifacts = nil
+		page.Records = append(page.Records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return Page{}, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	if len(page.Records) > size {
+		page.Records = page.Records[:size]
+		last := page.Records[size-1]
+		page.Next = cursor{finishedAt: last.FinishedAt, taskID: last.TaskID}.encode()
+	}
+	return page, nil
+}
+
+// storedTime is t as stored, in UTC to the microsecond
+func storedTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// Check pings the database, for the readiness probe
+func (r *SQLRepository) Check(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database unavailable: %w", err)
+	}
+	return nil
+}
+
+// scanRecord reads a row of profileColumns
+func scanRecord(row interface{ Scan(...interface{}) error }) (Record, error) {
+	var record Record
+	var profileCount, durationMs int64
+	var degraded bool
+	var response, artifacts, latency string
+	err := row.Scan(&record.TaskID, &record.ContextID, &record.BusinessIdea, &record.Caller, &record.Tenant,
+		&profileCount, &degraded, &response, &artifacts, &latency, &record.StartedAt, &record.FinishedAt, &durationMs)
+	if err != nil {
+		return Record{}, err
+	}
+	if err := json.Unmarshal([]byte(response), &record.Response); err != nil {
+		return Record{}, fmt.Errorf("failed to decode profiles: %w", err)
+	}
+	if err := json.Unmarshal([]byte(artifacts), &record.Artifacts); err != nil {
+		return Record{}, fmt.Errorf("failed to decode artifacts: %w", err)
+	}
+	if err := json.Unmarshal([]byte(latency), &record.Latency); err != nil {
+		return Record{}, fmt.Errorf("failed to decode latency: %w", err)
+	}
+	record.StartedAt = record.StartedAt.UTC()
+	record.FinishedAt = record.FinishedAt.UTC()
+	return record, nil
+}
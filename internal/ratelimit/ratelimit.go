@@ -0,0 +1,94 @@
+// Package ratelimit limits how many requests each client may make, per
+// minute with a token bucket and per day with a fixed window
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// sweepInterval is how often clients idle for a day are forgotten
+const sweepInterval = time.Hour
+
+// Limits are the requests one client may make; zero disables a limit
+type Limits struct {
+	PerMinute int
+	PerDay    int
+}
+
+// Limiter tracks the requests of each client key, such as an IP address.
+// It is safe for concurrent use.
+type Limiter struct {
+	limits Limits
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	lastSweep time.Time
+}
+
+type client struct {
+	minute   *rate.Limiter
+	dayStart time.Time
+	dayCount int
+	lastSeen time.Time
+}
+
+func New(limits Limits) *Limiter {
+	return &Limiter{limits: limits, clients: make(map[string]*client), lastSweep: time.Now()}
+}
+
+// Limits returns the limits the limiter enforces
+func (l *Limiter) Limits() Limits {
+	return l.limits
+}
+
+// Allow counts a request of key if it is within the limits. Otherwise
+// nothing is counted and retryAfter is how long until it would be allowed.
+func (l *Limiter) Allow(key string) (allowed bool, retryAfter time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	c, ok := l.clients[key]
+	if !ok {
+		c = &client{dayStart: now}
+		if l.limits.PerMinute > 0 {
+			c.minute = rate.NewLimiter(rate.Every(time.Minute/time.Duration(l.limits.PerMinute)), l.limits.PerMinute)
+		}
+		l.clients[key] = c
+	}
+	c.lastSeen = now
+
+	if now.Sub(c.dayStart) >= 24*time.Hour {
+		c.dayStart, c.dayCount = now, 0
+	}
+	if l.limits.PerDay > 0 && c.dayCount >= l.limits.PerDay {
+		return false, c.dayStart.Add(24 * time.Hour).Sub(now)
+	}
+	if c.minute != nil {
+		reservation := c.minute.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return false, delay
+		}
+	}
+	c.dayCount++
+	return true, 0
+}
+
+// sweep forgets clients idle for a day; the caller holds mu
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, c := range l.clients {
+		if now.Sub(c.lastSeen) >= 24*time.Hour {
+			delete(l.clients, key)
+		}
+	}
+}
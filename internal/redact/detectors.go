@@ -0,0 +1,73 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// detector finds one category of sensitive data
+type detector struct {
+	re *regexp.Regexp
+	// valid optionally filters regex matches to cut false positives
+	valid func(match string) bool
+}
+
+var detectors = map[string]detector{
+	"email": {re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	"phone": {re: regexp.MustCompile(`\+?\d[\d\s().\-]{7,}\d`), valid: func(m string) bool {
+		return countDigits(m) >= 9 && countDigits(m) <= 15
+	}},
+	"credit_card": {re: regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`), valid: luhnValid},
+	"ssn":         {re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	"ipv4":        {re: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)},
+	"api_key":     {re: regexp.MustCompile(`\b(?:sk|pk|rk|AIza|ghp|gho|xox[abp])[A-Za-z0-9_\-]{16,}\b|\b[A-Fa-f0-9]{32,}\b`)},
+}
+
+// redact replaces every valid match with a placeholder naming the detector
+func (d detector) redact(name, text string) string {
+	return d.re.ReplaceAllStringFunc(text, func(match string) string {
+		if d.valid != nil && !d.valid(match) {
+			return match
+		}
+		return placeholder(name)
+	})
+}
+
+func countDigits(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum
+func luhnValid(s string) bool {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,36 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SensitiveFields are JSON fields whose values are credentials, such as
+// the token of a push notification config
+var SensitiveFields = []string{
+	"token", "credentials", "password", "secret", "apiKey", "api_key",
+	"authorization", "accessToken", "refreshToken", "clientSecret",
+}
+
+// FieldMasker masks the values of named JSON fields. It matches the raw
+// text rather than decoding it, so truncated JSON is masked too.
+type FieldMasker struct {
+	re *regexp.Regexp
+}
+
+// NewFieldMasker masks SensitiveFields and the fields in extra, matching
+// names without regard to case
+func NewFieldMasker(extra ...string) *FieldMasker {
+	names := make([]string, 0, len(SensitiveFields)+len(extra))
+	for _, name := range append(append([]string{}, SensitiveFields...), extra...) {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	// A string value, escapes included, or a bare number, literal or the
+	// unterminated rest of a truncated body
+	return &FieldMasker{re: regexp.MustCompile(`(?i)("(?:` + strings.Join(names, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*(?:"|$)|[^,}\]\s]+)`)}
+}
+
+// Mask replaces the value of every named field with a placeholder
+func (m *FieldMasker) Mask(text string) string {
+	return m.re.ReplaceAllString(text, "${1}\""+placeholder("field")+"\"")
+}
@@ -0,0 +1,180 @@
+// Package redact applies a deployment-wide redaction policy to text before
+// it is logged, stored, or sent to the language model.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Target is a destination the policy controls
+type Target string
+
+const (
+	TargetLog   Target = "log"
+	TargetStore Target = "store"
+	TargetLLM   Target = "llm"
+)
+
+// Rule is a custom regex redaction
+type Rule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+// Policy lists, per target, which named detectors and custom rules apply.
+// Detectors run in the listed order, so list the most specific first.
+type Policy struct {
+	Targets map[Target][]string `json:"targets"`
+	Rules   []Rule              `json:"rules,omitempty"`
+}
+
+// DefaultPolicy scrubs everything from logs, personal identifiers from
+// stored data, and payment data and credentials from prompts
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Targets: map[Target][]string{
+			TargetLog:   {"api_key", "credit_card", "ssn", "email", "phone", "ipv4"},
+			TargetStore: {"api_key", "credit_card", "ssn", "email", "phone"},
+			TargetLLM:   {"api_key", "credit_card", "ssn"},
+		},
+	}
+}
+
+// LoadPolicy reads a policy from a JSON file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redaction policy: %w", err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse redaction policy: %w", err)
+	}
+
+	if err := policy.compile(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// compile validates detector names and compiles custom rules
+func (p *Policy) compile() error {
+	ruleNames := make(map[string]bool)
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("redaction rule %d has no name", i)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("redaction rule %q: %w", rule.Name, err)
+		}
+		rule.re = re
+		ruleNames[rule.Name] = true
+	}
+
+	for target, names := range p.Targets {
+		for _, name := range names {
+			if _, ok := detectors[name]; !ok && !ruleNames[name] {
+				return fmt.Errorf("redaction target %q references unknown detector %q", target, name)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply redacts text according to the detectors configured for target
+func (p *Policy) Apply(target Target, text string) string {
+	if text == "" {
+		return text
+	}
+
+	for _, name := range p.Targets[target] {
+		if detector, ok := detectors[name]; ok {
+			text = detector.redact(name, text)
+			continue
+		}
+		for _, rule := range p.Rules {
+			if rule.Name == name && rule.re != nil {
+				text = rule.re.ReplaceAllString(text, placeholder(rule.Name))
+			}
+		}
+	}
+	return text
+}
+
+func placeholder(name string) string {
+	return "[REDACTED:" + name + "]"
+}
+
+var (
+	mu      sync.RWMutex
+	current = DefaultPolicy()
+)
+
+// SetPolicy replaces the process-wide policy
+func SetPolicy(policy *Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = policy
+}
+
+func apply(target Target, text string) string {
+	mu.RLock()
+	policy := current
+	mu.RUnlock()
+	return policy.Apply(target, text)
+}
+
+// ForLog redacts text destined for log output
+func ForLog(text string) string { return apply(TargetLog, text) }
+
+// ForStore redacts text that will be retained in memory or storage
+func ForStore(text string) string { return apply(TargetStore, text) }
+
+// ForLLM redacts text that will be sent to the language model
+func ForLLM(text string) string { return apply(TargetLLM, text) }
+
+// sensitiveHeaders never have their values logged
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Prompt-Override-Key",
+	"X-A2A-Notification-Token",
+}
+
+// Headers returns a copy of h safe for logging: credential headers, and
+// any named in extra, are masked and the rest pass through the log policy
+func Headers(h http.Header, extra ...string) http.Header {
+	safe := make(http.Header, len(h))
+	for name, values := range h {
+		masked := false
+		for _, sensitive := range append(sensitiveHeaders, extra...) {
+			if strings.EqualFold(name, sensitive) {
+				masked = true
+				break
+			}
+		}
+
+		for _, value := range values {
+			if masked {
+				safe.Add(name, placeholder("header"))
+			} else {
+				safe.Add(name, ForLog(value))
+			}
+		}
+	}
+	return safe
+}
@@ -0,0 +1,99 @@
+// Package redisstore keeps tasks in Redis, so non-blocking and streamed
+// tasks survive restarts and every replica behind a load balancer sees the
+// same tasks.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/redis/go-redis/v9"
+)
+
+var logger = logging.For("redisstore")
+
+// DefaultKeyPrefix namespaces the store's keys, so it can share a database
+const DefaultKeyPrefix = "profiler:"
+
+// OperationTimeout bounds each Redis call; the TaskStore interface carries
+// no context
+const OperationTimeout = 3 * time.Second
+
+// TaskStore is an agentkit.TaskStore holding each task, with its status,
+// history and artifacts, as one JSON value that expires ttl after its last
+// save
+type TaskStore struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// Open connects to the Redis server at url, such as
+// redis://:password@localhost:6379/0 or rediss:// for TLS, and returns a
+// store of its tasks under prefix. A zero ttl keeps tasks until deleted.
+func Open(ctx context.Context, url, prefix string, ttl time.Duration) (*TaskStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	store := &TaskStore{client: redis.NewClient(options), prefix: prefix, ttl: ttl}
+	if err := store.Check(ctx); err != nil {
+		store.Close()
+		return nil, err
+	}
+	logger.Info("Connected to Redis", "addr", options.Addr, "db", options.DB)
+	return store, nil
+}
+
+func (s *TaskStore) key(id string) string {
+	return s.prefix + "task:" + id
+}
+
+func (s *TaskStore) Save(task a2a.TaskResult) error {
+	value, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task %s: %w", task.ID, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+	if err := s.client.Set(ctx, s.key(task.ID), value, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+func (s *TaskStore) Get(id string) (a2a.TaskResult, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OperationTimeout)
+	defer cancel()
+	value, err := s.client.Get(ctx, s.key(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return a2a.TaskResult{}, false, nil
+	}
+	if err != nil {
+		return a2a.TaskResult{}, false, fmt.Errorf("failed to load task %s: %w", id, err)
+	}
+
+	var task a2a.TaskResult
+	if err := json.Unmarshal(value, &task); err != nil {
+		return a2a.TaskResult{}, false, fmt.Errorf("failed to decode task %s: %w", id, err)
+	}
+	return task, true, nil
+}
+
+// Check pings the server, for the readiness probe
+func (s *TaskStore) Check(ctx context.Context) error {
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis unavailable: %w", err)
+	}
+	return nil
+}
+
+// Close closes the store's connections
+func (s *TaskStore) Close() error {
+	return s.client.Close()
+}
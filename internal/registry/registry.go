@@ -0,0 +1,165 @@
+// Package registry announces the agent to an agent registry so
+// orchestrators can discover it. The agent's card is PUT to
+// {URL}/agents/{id} on startup, {URL}/agents/{id}/heartbeat is POSTed
+// periodically, and the entry is DELETEd on shutdown.
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/agent"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+)
+
+var logger = logging.For("registry")
+
+// DefaultHeartbeatInterval is how often a registered agent reports that
+// it is still up
+const DefaultHeartbeatInterval = 30 * time.Second
+
+// errNotRegistered is returned by a heartbeat the registry doesn't
+// recognize, e.g. after it restarted
+var errNotRegistered = errors.New("agent is not registered")
+
+// Config describes the registry and the agent to announce
+type Config struct {
+	// URL is the registry's base URL
+	URL string
+	// Token, if set, is sent as a bearer token on every call
+	Token string
+	// AgentID names the agent's registry entry; empty uses the card's name
+	AgentID string
+	// Card is the agent card registered; its URL must be absolute
+	Card *agent.AgentCard
+	// Interval is the heartbeat period; zero uses DefaultHeartbeatInterval
+	Interval time.Duration
+}
+
+// Registrar keeps the agent registered while the server runs
+type Registrar struct {
+	config Config
+	client *http.Client
+}
+
+func New(config Config) (*Registrar, error) {
+	if config.Card == nil || !strings.HasPrefix(config.Card.URL, "http") {
+		return nil, errors.New("registry: the agent card needs an absolute URL; set PUBLIC_BASE_URL")
+	}
+	if _, err := url.ParseRequestURI(config.URL); err != nil {
+		return nil, fmt.Errorf("registry: invalid URL %q: %w", config.URL, err)
+	}
+	if config.AgentID == "" {
+		config.AgentID = slug(config.Card.Name)
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultHeartbeatInterval
+	}
+	return &Registrar{config: config, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Register creates or replaces the agent's registry entry with its card
+func (r *Registrar) Register(ctx context.Context) error {
+	body, err := json.Marshal(r.config.Card)
+	if err != nil {
+		return fmt.Errorf("registry: failed to encode agent card: %w", err)
+	}
+	return r.call(ctx, http.MethodPut, r.entryURL(), body)
+}
+
+// Heartbeat tells the registry the agent is still up
+func (r *Registrar) Heartbeat(ctx context.Context) error {
+	return r.call(ctx, http.MethodPost, r.entryURL()+"/heartbeat", nil)
+}
+
+// Deregister removes the agent's registry entry
+func (r *Registrar) Deregister(ctx context.Context) error {
+	return r.call(ctx, http.MethodDelete, r.entryURL(), nil)
+}
+
+// Run registers the agent and heartbeats every interval until ctx is
+// done. Failed registrations are retried at the next beat, and a registry
+// that forgot the agent gets its card again.
+func (r *Registrar) Run(ctx context.Context) {
+	registered := r.register(ctx)
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !registered {
+			registered = r.register(ctx)
+			continue
+		}
+		err := r.Heartbeat(ctx)
+		if errors.Is(err, errNotRegistered) {
+			logger.Warn("Registry lost the agent, registering again", "agent_id", r.config.AgentID)
+			registered = r.register(ctx)
+		} else if err != nil {
+			logger.Warn("Registry heartbeat failed", "error", err)
+		}
+	}
+}
+
+func (r *Registrar) register(ctx context.Context) bool {
+	if err := r.Register(ctx); err != nil {
+		logger.Warn("Failed to register with the agent registry", "error", err)
+		return false
+	}
+	logger.Info("Registered with the agent registry", "agent_id", r.config.AgentID, "url", r.config.URL)
+	return true
+}
+
+func (r *Registrar) entryURL() string {
+	return strings.TrimRight(r.config.URL, "/") + "/agents/" + url.PathEscape(r.config.AgentID)
+}
+
+func (r *Registrar) call(ctx context.Context, method, target string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("registry: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.config.Token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry: %s %s: %w", method, target, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && method == http.MethodPost:
+		return errNotRegistered
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("registry: %s %s returned HTTP %d", method, target, resp.StatusCode)
+	}
+	return nil
+}
+
+// slug turns an agent name into an entry ID, e.g. "Customer Profiler" to
+// "customer-profiler"
+func slug(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+	return strings.Join(fields, "-")
+}
@@ -0,0 +1,75 @@
+// Package requestid gives every request an ID that follows it through the
+// logs, the profiler and the response, so a failing generation can be
+// traced end to end.
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Header carries the request ID, honored on input and echoed on output
+const Header = "X-Request-ID"
+
+// maxLength bounds caller-supplied IDs, which end up in every log line
+const maxLength = 128
+
+type contextKey struct{}
+
+// New returns a fresh request ID
+func New() string {
+	return uuid.New().String()
+}
+
+// NewContext returns a copy of ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID in ctx, or "" if there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Ensure returns req with a request ID in its context, and the ID. An ID
+// already in the context is kept; otherwise a valid X-Request-ID header is
+// used, or a new ID generated.
+func Ensure(req *http.Request) (*http.Request, string) {
+	if id := FromContext(req.Context()); id != "" {
+		return req, id
+	}
+	id := req.Header.Get(Header)
+	if !valid(id) {
+		id = New()
+	}
+	return req.WithContext(NewContext(req.Context(), id)), id
+}
+
+// Middleware assigns each request an ID and returns it in the X-Request-ID
+// response header
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id string
+		c.Request, id = Ensure(c.Request)
+		c.Header(Header, id)
+		c.Next()
+	}
+}
+
+// valid accepts non-empty IDs of printable ASCII without spaces, so a
+// caller can't forge log lines through them
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
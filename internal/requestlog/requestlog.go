@@ -0,0 +1,180 @@
+// Package requestlog keeps a ring buffer of recent A2A requests for
+// production triage without log access.
+package requestlog
+
+import (
+	"crypto/subtle"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/redact"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultCapacity is how many requests the ring buffer keeps
+	DefaultCapacity = 100
+	// ideaSnippetLength caps how much of the business idea is retained
+	ideaSnippetLength = 80
+	// MaxRequestSize is the largest request body kept for replay; larger
+	// bodies are dropped rather than cut into invalid JSON
+	MaxRequestSize = 64 << 10
+)
+
+// Record summarizes one handled request
+type Record struct {
+	TraceID     string    `json:"trace_id"`
+	ReceivedAt  time.Time `json:"received_at"`
+	Method      string    `json:"method"`
+	IdeaSnippet string    `json:"idea_snippet"`
+	DurationMS  int64     `json:"duration_ms"`
+	Outcome     string    `json:"outcome"`
+	// Request is the redacted raw request body, kept so the request can be
+	// replayed against newer code
+	Request string `json:"request,omitempty"`
+}
+
+// Log is a fixed-size ring buffer of request records
+type Log struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+func New(capacity int) *Log {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Log{records: make([]Record, capacity)}
+}
+
+// Add stores a record, overwriting the oldest once the buffer is full
+func (l *Log) Add(record Record) {
+	record.IdeaSnippet = Snippet(redact.ForStore(record.IdeaSnippet))
+	if len(record.Request) > MaxRequestSize {
+		record.Request = ""
+	}
+	record.Request = redact.ForStore(record.Request)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records[l.next] = record
+	l.next = (l.next + 1) % len(l.records)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Recent returns the buffered records, newest first
+func (l *Log) Recent() []Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := l.next
+	if l.full {
+		count = len(l.records)
+	}
+
+	recent := make([]Record, 0, count)
+	for i := 1; i <= count; i++ {
+		idx := (l.next - i + len(l.records)) % len(l.records)
+		recent = append(recent, l.records[idx])
+	}
+	return recent
+}
+
+// Find returns the buffered record with the given trace ID
+func (l *Log) Find(traceID string) (Record, bool) {
+	for _, record := range l.Recent() {
+		if record.TraceID == traceID {
+			return record, true
+		}
+	}
+	return Record{}, false
+}
+
+// Snippet shortens an idea to a single line preview
+func Snippet(idea string) string {
+	idea = strings.Join(strings.Fields(idea), " ")
+	if runes := []rune(idea); len(runes) > ideaSnippetLength {
+		return string(runes[:ideaSnippetLength]) + "…"
+	}
+	return idea
+}
+
+// Authorize checks the bearer token of a debug request and writes the error
+// response if it is missing or wrong. An empty token disables the debug
+// endpoints, which then answer 404.
+func Authorize(c *gin.Context, token string) bool {
+	if token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return false
+	}
+
+	presented := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return false
+	}
+	return true
+}
+
+var pageTemplate = template.Must(template.New("requests").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Recent requests</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 6px 10px; text-align: left; font-size: 14px; }
+th { background: #f4f4f4; }
+.failed, .error { color: #b00020; }
+.completed { color: #1b7f3b; }
+code { font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>Recent requests ({{len .}})</h1>
+<table>
+<tr><th>Received</th><th>Method</th><th>Idea</th><th>Duration</th><th>Outcome</th><th>Trace ID</th></tr>
+{{range .}}<tr>
+<td>{{.ReceivedAt.Format "2006-01-02 15:04:05"}}</td>
+<td>{{.Method}}</td>
+<td>{{.IdeaSnippet}}</td>
+<td>{{.DurationMS}} ms</td>
+<td class="{{.Outcome}}">{{.Outcome}}</td>
+<td><code>{{.TraceID}}</code></td>
+</tr>{{end}}
+</table>
+</body>
+</html>
+`))
+
+// Handler serves the buffered requests as an HTML table for browsers and
+// JSON otherwise. Callers must present token as a bearer token; an empty
+// token disables the endpoint.
+func (l *Log) Handler(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Authorize(c, token) {
+			return
+		}
+
+		records := l.Recent()
+
+		if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+			c.Status(http.StatusOK)
+			c.Header("Content-Type", "text/html; charset=utf-8")
+			if err := pageTemplate.Execute(c.Writer, records); err != nil {
+				c.Error(err)
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"requests": records})
+	}
+}
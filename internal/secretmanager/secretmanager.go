@@ -0,0 +1,80 @@
+// Package secretmanager reads secrets from Google Cloud Secret Manager, for
+// settings written as gcpsm:// references.
+package secretmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/secretmanager/v1"
+)
+
+// Provider is a secrets.Provider reading secret versions from Secret
+// Manager
+type Provider struct {
+	service *secretmanager.Service
+	project string
+}
+
+// New connects to Secret Manager with Application Default Credentials, or
+// the credentials in opts. project is used for references that name only
+// a secret, and may be empty if none do.
+func New(ctx context.Context, project string, opts ...option.ClientOption) (*Provider, error) {
+	service, err := secretmanager.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Secret Manager: %w", err)
+	}
+	return &Provider{service: service, project: project}, nil
+}
+
+// Read accesses the secret version at path, which is either a full name,
+// projects/acme/secrets/gemini-api-key/versions/3, or a secret in the
+// default project, gemini-api-key/versions/3. Without a version it reads
+// the latest. A trailing newline, as left by echo, is dropped.
+func (p *Provider) Read(ctx context.Context, path string) (string, error) {
+	name, err := p.versionName(path)
+	if err != nil {
+		return "", err
+	}
+	response, err := p.service.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to access secret %s: %w", name, err)
+	}
+	if response.Payload == nil {
+		return "", fmt.Errorf("secret %s has no payload", name)
+	}
+	data, err := base64.StdEncoding.DecodeString(response.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret %s: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// versionName is the secret version resource name of path
+func (p *Provider) versionName(path string) (string, error) {
+	name := strings.Trim(path, "/")
+	if !strings.HasPrefix(name, "projects/") {
+		if p.project == "" {
+			return "", fmt.Errorf("secret %q names no project, and no default project is set", path)
+		}
+		name = "projects/" + p.project + "/secrets/" + name
+	}
+
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 4 && parts[2] == "secrets":
+		name += "/versions/latest"
+	case len(parts) == 6 && parts[2] == "secrets" && parts[4] == "versions":
+	default:
+		return "", fmt.Errorf("secret %q must be projects/PROJECT/secrets/SECRET[/versions/VERSION] or SECRET[/versions/VERSION]", path)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("secret %q has an empty name segment", path)
+		}
+	}
+	return name, nil
+}
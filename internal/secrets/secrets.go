@@ -0,0 +1,127 @@
+// Package secrets resolves settings written as references to a secret
+// manager, such as gcpsm://projects/acme/secrets/gemini-api-key, and reads
+// them again on an interval so rotated secrets apply without a restart.
+package secrets
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+)
+
+var logger = logging.For("secrets")
+
+// ReadTimeout bounds each read from a secret manager
+const ReadTimeout = 10 * time.Second
+
+// Provider reads secrets from one secret manager
+type Provider interface {
+	// Read returns the current value of the secret at path, the part of a
+	// reference after its scheme
+	Read(ctx context.Context, path string) (string, error)
+}
+
+// Ref is a reference to a secret, written scheme://path
+type Ref struct {
+	Scheme string
+	Path   string
+}
+
+func (r Ref) String() string {
+	return r.Scheme + "://" + r.Path
+}
+
+// Reference schemes of the supported secret managers
+const (
+	SchemeGCP   = "gcpsm"
+	SchemeVault = "vault"
+)
+
+// ParseRef tells whether value is a reference rather than the secret
+// itself: the scheme of a supported secret manager, "://" and a path.
+// Other values, such as a postgres:// URL, are secrets themselves.
+func ParseRef(value string) (Ref, bool) {
+	scheme, path, ok := strings.Cut(value, "://")
+	if !ok || path == "" || (scheme != SchemeGCP && scheme != SchemeVault) {
+		return Ref{}, false
+	}
+	return Ref{Scheme: scheme, Path: path}, true
+}
+
+// Read reads ref from provider within ReadTimeout
+func Read(ctx context.Context, provider Provider, ref Ref) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ReadTimeout)
+	defer cancel()
+	return provider.Read(ctx, ref.Path)
+}
+
+// rotated is a secret the Rotator reads again
+type rotated struct {
+	name     string
+	ref      Ref
+	provider Provider
+	value    string
+	apply    func(value string) error
+}
+
+// Rotator reads secrets again on an interval and applies those whose
+// value changed. Values are never logged.
+type Rotator struct {
+	mu      sync.Mutex
+	secrets []*rotated
+}
+
+// Add has the Rotator read ref, currently value, and pass new values to
+// apply. name is the setting, as the config file spells it.
+func (r *Rotator) Add(name string, ref Ref, provider Provider, value string, apply func(value string) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets = append(r.secrets, &rotated{name: name, ref: ref, provider: provider, value: value, apply: apply})
+}
+
+// Len is the number of secrets the Rotator reads
+func (r *Rotator) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.secrets)
+}
+
+// Run rotates every interval until ctx is done
+func (r *Rotator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Rotate(ctx)
+		}
+	}
+}
+
+// Rotate reads each secret once. A secret that can't be read, or whose
+// new value is refused, keeps its current value until the next round.
+func (r *Rotator) Rotate(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, secret := range r.secrets {
+		value, err := Read(ctx, secret.provider, secret.ref)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to read secret; keeping the current value", "setting", secret.name, "ref", secret.ref.String(), "error", err)
+			continue
+		}
+		if value == secret.value {
+			continue
+		}
+		if err := secret.apply(value); err != nil {
+			logger.ErrorContext(ctx, "Failed to apply rotated secret; keeping the current value", "setting", secret.name, "ref", secret.ref.String(), "error", err)
+			continue
+		}
+		secret.value = value
+		logger.InfoContext(ctx, "Applied rotated secret", "audit", true, "setting", secret.name, "ref", secret.ref.String())
+	}
+}
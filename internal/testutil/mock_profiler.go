@@ -0,0 +1,90 @@
+// Package testutil provides in-memory fakes for exercising handlers without
+// external services.
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/models"
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/profiler"
+)
+
+// GenerateCall records one call made to MockGenerator
+type GenerateCall struct {
+	BusinessIdea string
+	Options      profiler.GenerateOptions
+}
+
+// MockGenerator is a deterministic profiler.ProfileGenerator. By default it
+// returns MockProfile for every idea; set Response or Err to script results.
+type MockGenerator struct {
+	Response *models.ProfileResponse
+	Err      error
+	// Block, if set, holds every generation until it is closed or the
+	// request's context ends, to test tasks that are still running
+	Block chan struct{}
+
+	mu    sync.Mutex
+	calls []GenerateCall
+}
+
+var _ profiler.ProfileGenerator = (*MockGenerator)(nil)
+
+func NewMockGenerator() *MockGenerator {
+	return &MockGenerator{}
+}
+
+func (m *MockGenerator) GenerateCustomerProfiles(ctx context.Context, businessIdea string, opts profiler.GenerateOptions) (*models.ProfileResponse, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, GenerateCall{BusinessIdea: businessIdea, Options: opts})
+	resp, scriptedErr, block := m.Response, m.Err, m.Block
+	m.mu.Unlock()
+
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if scriptedErr != nil {
+		return nil, scriptedErr
+	}
+	if resp != nil {
+		copied := *resp
+		return &copied, nil
+	}
+
+	return &models.ProfileResponse{
+		BusinessIdea: businessIdea,
+		Profiles:     []models.CustomerProfile{MockProfile()},
+		Summary:      "",
+		Keywords:     []string{},
+	}, nil
+}
+
+// Calls returns a copy of every call received so far
+func (m *MockGenerator) Calls() []GenerateCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]GenerateCall(nil), m.calls...)
+}
+
+// MockProfile is the fixed persona returned by MockGenerator
+func MockProfile() models.CustomerProfile {
+	return models.CustomerProfile{
+		Age:               "30-45",
+		Gender:            "female",
+		Location:          "Urban",
+		Occupation:        "Marketing Manager",
+		Income:            "$75k-100k",
+		Motivations:       []string{"convenience", "quality"},
+		Interests:         []string{"travel", "fitness"},
+		PainPoints:        []string{"lack of time"},
+		BuyingBehaviors:   []string{"researches online before buying"},
+		PreferredChannels: []string{"Instagram"},
+	}
+}
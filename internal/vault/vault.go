@@ -0,0 +1,407 @@
+// Package vault reads secrets from HashiCorp Vault, for settings written as
+// vault:// references. It logs in with AppRole, Kubernetes or a token, and
+// renews its token and the leases of dynamic secrets, such as database
+// credentials, while the server runs.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/internal/logging"
+)
+
+var logger = logging.For("vault")
+
+// Auth methods
+const (
+	AuthAppRole    = "approle"
+	AuthKubernetes = "kubernetes"
+	AuthToken      = "token"
+)
+
+// DefaultKubernetesTokenFile is where Kubernetes mounts the pod's service
+// account token
+const DefaultKubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// RenewCheckInterval is how often the token and leases are checked for
+// renewal. They are renewed two thirds of the way through their lease.
+const RenewCheckInterval = 30 * time.Second
+
+// minLease is the shortest lease worth keeping: a token or secret renewed
+// for less, because it is reaching its max TTL, is replaced instead
+const minLease = 3 * RenewCheckInterval
+
+// Config describes the Vault server and how to log in to it
+type Config struct {
+	// Address is the server's URL, such as https://vault.internal:8200
+	Address string
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+	// AuthMethod is AuthAppRole, AuthKubernetes or AuthToken
+	AuthMethod string
+	// AuthMount is where the auth method is mounted; empty uses its name
+	AuthMount string
+	// Token is the token of AuthToken
+	Token string
+	// RoleID and SecretID are the AppRole credentials
+	RoleID   string
+	SecretID string
+	// Role is the Kubernetes auth role, and KubernetesTokenFile the
+	// service account token presented for it; empty uses
+	// DefaultKubernetesTokenFile
+	Role                string
+	KubernetesTokenFile string
+	// CACertFile, if set, verifies the server with these CAs instead of
+	// the system's
+	CACertFile string
+}
+
+// lease is how long a token or secret is valid
+type lease struct {
+	id        string
+	duration  time.Duration
+	renewable bool
+	obtained  time.Time
+}
+
+// renewDue tells whether the lease is two thirds through
+func (l lease) renewDue(now time.Time) bool {
+	return l.duration > 0 && now.Sub(l.obtained) >= l.duration*2/3
+}
+
+// Client is a secrets.Provider reading from Vault
+type Client struct {
+	config Config
+	http   *http.Client
+
+	mu    sync.Mutex
+	token string
+	// tokenLease is zero for tokens that don't expire
+	tokenLease lease
+	// leased holds the dynamic secrets, by path, so every field of a
+	// secret comes from the same lease until it is replaced
+	leased map[string]*leasedSecret
+}
+
+type leasedSecret struct {
+	data  map[string]interface{}
+	lease lease
+}
+
+// New logs in to Vault
+func New(ctx context.Context, config Config) (*Client, error) {
+	if config.Address == "" {
+		return nil, errors.New("vault: no address is set")
+	}
+	if config.AuthMount == "" {
+		config.AuthMount = config.AuthMethod
+	}
+	if config.KubernetesTokenFile == "" {
+		config.KubernetesTokenFile = DefaultKubernetesTokenFile
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.CACertFile != "" {
+		pem, err := os.ReadFile(config.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("vault: failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("vault: no certificates in %s", config.CACertFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	c := &Client{
+		config: config,
+		http:   &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		leased: map[string]*leasedSecret{},
+	}
+	if err := c.login(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Read returns a field of the secret at ref, written path#field, such as
+// secret/data/profiler#geminiApiKey for a KV version 2 secret or
+// database/creds/profiler#password for database credentials. The field may
+// be left out of secrets holding one. Dynamic secrets are read once per
+// lease, so their fields match.
+func (c *Client) Read(ctx context.Context, ref string) (string, error) {
+	path, field, _ := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "", fmt.Errorf("vault: secret %q names no path", ref)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := c.data(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	if field == "" {
+		if len(data) != 1 {
+			return "", fmt.Errorf("vault: secret %s has %d fields; name one, as %s#field", path, len(data), path)
+		}
+		for name := range data {
+			field = name
+		}
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault: secret %s has no field %q", path, field)
+	}
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case float64, bool:
+		return fmt.Sprint(value), nil
+	}
+	return "", fmt.Errorf("vault: field %q of secret %s is not a string", field, path)
+}
+
+// data returns the fields of the secret at path, from its lease if it has
+// one
+func (c *Client) data(ctx context.Context, path string) (map[string]interface{}, error) {
+	if secret, ok := c.leased[path]; ok {
+		return secret.data, nil
+	}
+
+	var response struct {
+		LeaseID       string                 `json:"lease_id"`
+		LeaseDuration int64                  `json:"lease_duration"`
+		Renewable     bool                   `json:"renewable"`
+		Data          map[string]interface{} `json:"data"`
+	}
+	if err := c.call(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return nil, fmt.Errorf("vault: failed to read secret %s: %w", path, err)
+	}
+	data := response.Data
+	// KV version 2 nests the fields beside the version metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"].(map[string]interface{}); ok {
+			data = inner
+		}
+	}
+	if response.LeaseID != "" {
+		c.leased[path] = &leasedSecret{data: data, lease: lease{
+			id:        response.LeaseID,
+			duration:  time.Duration(response.LeaseDuration) * time.Second,
+			renewable: response.Renewable,
+			obtained:  time.Now(),
+		}}
+		logger.InfoContext(ctx, "Obtained leased secret", "path", path, "lease_duration", time.Duration(response.LeaseDuration)*time.Second)
+	}
+	return data, nil
+}
+
+// authResponse is the auth block of a login or token renewal
+type authResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// login obtains a token with the configured auth method
+func (c *Client) login(ctx context.Context) error {
+	var body map[string]string
+	switch c.config.AuthMethod {
+	case AuthToken:
+		return c.lookupToken(ctx)
+	case AuthAppRole:
+		body = map[string]string{"role_id": c.config.RoleID, "secret_id": c.config.SecretID}
+	case AuthKubernetes:
+		jwt, err := os.ReadFile(c.config.KubernetesTokenFile)
+		if err != nil {
+			return fmt.Errorf("vault: failed to read the Kubernetes service account token: %w", err)
+		}
+		body = map[string]string{"role": c.config.Role, "jwt": strings.TrimSpace(string(jwt))}
+	default:
+		return fmt.Errorf("vault: unknown auth method %q", c.config.AuthMethod)
+	}
+
+	var response authResponse
+	c.token = ""
+	if err := c.call(ctx, http.MethodPost, "auth/"+strings.Trim(c.config.AuthMount, "/")+"/login", body, &response); err != nil {
+		return fmt.Errorf("vault: %s login failed: %w", c.config.AuthMethod, err)
+	}
+	c.token = response.Auth.ClientToken
+	c.tokenLease = lease{
+		duration:  time.Duration(response.Auth.LeaseDuration) * time.Second,
+		renewable: response.Auth.Renewable,
+		obtained:  time.Now(),
+	}
+	// Leases end with the token that obtained them
+	c.leased = map[string]*leasedSecret{}
+	logger.InfoContext(ctx, "Logged in to Vault", "method", c.config.AuthMethod, "token_ttl", c.tokenLease.duration)
+	return nil
+}
+
+// lookupToken checks a configured token and learns its lease
+func (c *Client) lookupToken(ctx context.Context) error {
+	c.token = c.config.Token
+	var response struct {
+		Data struct {
+			TTL       int64 `json:"ttl"`
+			Renewable bool  `json:"renewable"`
+		} `json:"data"`
+	}
+	if err := c.call(ctx, http.MethodGet, "auth/token/lookup-self", nil, &response); err != nil {
+		return fmt.Errorf("vault: token lookup failed: %w", err)
+	}
+	c.tokenLease = lease{
+		duration:  time.Duration(response.Data.TTL) * time.Second,
+		renewable: response.Data.Renewable,
+		obtained:  time.Now(),
+	}
+	return nil
+}
+
+// Run renews the token and leases until ctx is done
+func (c *Client) Run(ctx context.Context) {
+	ticker := time.NewTicker(RenewCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.renew(ctx)
+		}
+	}
+}
+
+// renew extends the token and the leases two thirds of the way through
+// them. A token that can't be extended is replaced by logging in again; a
+// secret that can't is dropped, so the next read obtains a new one for
+// secret rotation to apply.
+func (c *Client) renew(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+
+	if c.tokenLease.renewDue(now) {
+		if err := c.renewToken(ctx); err != nil {
+			logger.WarnContext(ctx, "Failed to renew the Vault token", "error", err)
+			if c.config.AuthMethod != AuthToken {
+				if err := c.login(ctx); err != nil {
+					logger.ErrorContext(ctx, "Failed to log in to Vault again", "error", err)
+				}
+			}
+		}
+	}
+
+	for path, secret := range c.leased {
+		if !secret.lease.renewDue(now) {
+			continue
+		}
+		if !secret.lease.renewable {
+			delete(c.leased, path)
+			logger.InfoContext(ctx, "Leased secret is ending; obtaining a new one at the next read", "path", path)
+			continue
+		}
+		var response struct {
+			LeaseDuration int64 `json:"lease_duration"`
+		}
+		body := map[string]interface{}{"lease_id": secret.lease.id, "increment": int64(secret.lease.duration / time.Second)}
+		err := c.call(ctx, http.MethodPut, "sys/leases/renew", body, &response)
+		duration := time.Duration(response.LeaseDuration) * time.Second
+		if err != nil || duration < minLease {
+			delete(c.leased, path)
+			logger.InfoContext(ctx, "Leased secret can't be renewed further; obtaining a new one at the next read", "path", path, "error", err)
+			continue
+		}
+		secret.lease.duration, secret.lease.obtained = duration, now
+	}
+}
+
+// renewToken extends the token's lease, failing when it is reaching its
+// max TTL
+func (c *Client) renewToken(ctx context.Context) error {
+	if !c.tokenLease.renewable {
+		return errors.New("the token is not renewable")
+	}
+	var response authResponse
+	if err := c.call(ctx, http.MethodPost, "auth/token/renew-self", map[string]string{}, &response); err != nil {
+		return err
+	}
+	duration := time.Duration(response.Auth.LeaseDuration) * time.Second
+	if duration < minLease {
+		return fmt.Errorf("the token is reaching its max TTL, with %s left", duration)
+	}
+	c.tokenLease.duration, c.tokenLease.obtained = duration, time.Now()
+	return nil
+}
+
+// call sends a request to the Vault API at /v1/path and decodes the
+// response into out
+func (c *Client) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	target := strings.TrimRight(c.config.Address, "/") + "/v1/" + (&url.URL{Path: path}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("X-Vault-Token", c.token)
+	}
+	if c.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.config.Namespace)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(data, &failure) == nil && len(failure.Errors) > 0 {
+			return fmt.Errorf("%s %s returned HTTP %d: %s", method, path, resp.StatusCode, strings.Join(failure.Errors, "; "))
+		}
+		return fmt.Errorf("%s %s returned HTTP %d", method, path, resp.StatusCode)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+// Package a2a holds the A2A JSON-RPC envelope types spoken by the profiler
+// agent, for Go agents that call it or speak the same protocol.
+package a2a
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+)
+
+// JSON-RPC types
+type JSONRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      string      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type JSONRPCResponse struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      string        `json:"id"`
+	Result  interface{}   `json:"result,omitempty"`
+	Error   *JSONRPCError `json:"error,omitempty"`
+}
+
+// JSONRPCError is a JSON-RPC 2.0 error object
+type JSONRPCError = a2aerrors.Error
+
+// ErrorData carries machine-readable details of an error
+type ErrorData = a2aerrors.Data
+
+// MessageParams are the params of message/send and message/stream
+type MessageParams struct {
+	Message       A2AMessage             `json:"message"`
+	Configuration MessageConfiguration   `json:"configuration"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type A2AMessage struct {
+	Kind      string        `json:"kind"`
+	Role      string        `json:"role"`
+	Parts     []MessagePart `json:"parts"`
+	MessageID string        `json:"messageId,omitempty"`
+	TaskID    string        `json:"taskId,omitempty"`
+	// ContextID groups the messages of one conversation; a message reusing
+	// the contextId of an earlier task refines that task's profiles
+	ContextID string `json:"contextId,omitempty"`
+	// ReferenceTaskIDs name earlier tasks whose profiles the message builds on
+	ReferenceTaskIDs []string `json:"referenceTaskIds,omitempty"`
+	// Metadata is kept in the task history and echoed in the task's
+	// metadata; the profiler agent reads brandVoice and hints from it
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type MessagePart struct {
+	Kind     string                 `json:"kind"`
+	Text     interface{}            `json:"text,omitempty"`
+	Data     interface{}            `json:"data,omitempty"`
+	File     *FileContent           `json:"file,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// FileContent is the payload of a file part, referenced by URI or inlined as base64 bytes
+type FileContent struct {
+	Name     string `json:"name,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Bytes    string `json:"bytes,omitempty"`
+}
+
+type MessageConfiguration struct {
+	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"`
+	// HistoryLength limits the history in the response to the most recent
+	// messages; zero returns all of it
+	HistoryLength int `json:"historyLength,omitempty"`
+	// Blocking false returns the task while it is still working; omitted
+	// means blocking
+	Blocking *bool `json:"blocking,omitempty"`
+	// Language is a BCP 47 tag for the output language; defaults to English
+	Language string `json:"language,omitempty"`
+	// PushNotificationConfig registers a webhook for the task's final state
+	PushNotificationConfig *PushNotificationConfig `json:"pushNotificationConfig,omitempty"`
+}
+
+// PushNotificationConfig is a webhook the agent POSTs finished tasks to
+type PushNotificationConfig struct {
+	ID  string `json:"id,omitempty"`
+	URL string `json:"url"`
+	// Token is echoed in the X-A2A-Notification-Token header so the
+	// receiver can check the notification belongs to its task
+	Token          string                          `json:"token,omitempty"`
+	Authentication *PushNotificationAuthentication `json:"authentication,omitempty"`
+}
+
+// PushNotificationAuthentication is how the agent authenticates to the webhook
+type PushNotificationAuthentication struct {
+	Schemes     []string `json:"schemes"`
+	Credentials string   `json:"credentials,omitempty"`
+}
+
+// TaskPushNotificationConfig binds a push notification config to a task
+type TaskPushNotificationConfig struct {
+	TaskID                 string                 `json:"taskId"`
+	PushNotificationConfig PushNotificationConfig `json:"pushNotificationConfig"`
+}
+
+// TaskQueryParams are the params of tasks/get
+type TaskQueryParams struct {
+	ID string `json:"id"`
+	// HistoryLength limits the returned history to the most recent messages;
+	// zero returns all of it
+	HistoryLength int                    `json:"historyLength,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskIDParams are the params of tasks/cancel
+type TaskIDParams struct {
+	ID       string                 `json:"id"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskFeedbackParams are the params of tasks/feedback
+type TaskFeedbackParams struct {
+	ID string `json:"id"`
+	// Rating scores the task's profiles from 1 (poor) to 5 (excellent)
+	Rating   int                    `json:"rating"`
+	Comment  string                 `json:"comment,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Feedback is an end user's rating of a task's output, kept in the task's
+// metadata
+type Feedback struct {
+	Rating      int    `json:"rating"`
+	Comment     string `json:"comment,omitempty"`
+	SubmittedAt string `json:"submittedAt"`
+	// RegenerationTaskID is the refinement the agent started in response
+	// to a low rating, if any
+	RegenerationTaskID string `json:"regenerationTaskId,omitempty"`
+}
+
+// Task types
+type TaskResult struct {
+	ID        string       `json:"id"`
+	ContextID string       `json:"contextId"`
+	Status    TaskStatus   `json:"status"`
+	Artifacts []Artifact   `json:"artifacts,omitempty"`
+	History   []A2AMessage `json:"history,omitempty"`
+	Kind      string       `json:"kind"`
+	// Metadata carries the latency breakdown, warnings and echoed
+	// correlation and message metadata of a task
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskStatusUpdateEvent is streamed when a task changes state; Final marks
+// the last event of a stream
+type TaskStatusUpdateEvent struct {
+	TaskID    string     `json:"taskId"`
+	ContextID string     `json:"contextId"`
+	Kind      string     `json:"kind"`
+	Status    TaskStatus `json:"status"`
+	Final     bool       `json:"final"`
+	// Metadata is the task's metadata, on the final event only
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskArtifactUpdateEvent is streamed when a task produces an artifact
+type TaskArtifactUpdateEvent struct {
+	TaskID    string   `json:"taskId"`
+	ContextID string   `json:"contextId"`
+	Kind      string   `json:"kind"`
+	Artifact  Artifact `json:"artifact"`
+	Append    bool     `json:"append,omitempty"`
+	LastChunk bool     `json:"lastChunk,omitempty"`
+}
+
+// Stream event kinds
+const (
+	KindStatusUpdate   = "status-update"
+	KindArtifactUpdate = "artifact-update"
+)
+
+type TaskStatus struct {
+	State     string      `json:"state"`
+	Timestamp string      `json:"timestamp"`
+	Message   *A2AMessage `json:"message,omitempty"`
+}
+
+type Artifact struct {
+	ArtifactID string                 `json:"artifactId"`
+	Name       string                 `json:"name"`
+	Parts      []MessagePart          `json:"parts"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Helper functions
+func TextPart(text string) MessagePart {
+	return MessagePart{
+		Kind: "text",
+		Text: &text,
+	}
+}
+
+func DataPart(data interface{}) MessagePart {
+	return MessagePart{
+		Kind: "data",
+		Data: data,
+	}
+}
+
+func FilePart(name, mimeType, uri string) MessagePart {
+	return MessagePart{
+		Kind: "file",
+		File: &FileContent{
+			Name:     name,
+			MimeType: mimeType,
+			URI:      uri,
+		},
+	}
+}
+
+func Timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// Task states
+const (
+	StateSubmitted     = "submitted"
+	StateWorking       = "working"
+	StateInputRequired = "input-required"
+	StateCompleted     = "completed"
+	StateFailed        = "failed"
+	StateCanceled      = "canceled"
+	StateRejected      = "rejected"
+)
+
+// IsTerminalState reports whether a task in state can no longer change
+func IsTerminalState(state string) bool {
+	return state == StateCompleted || state == StateFailed || state == StateCanceled || state == StateRejected
+}
+
+// Message roles
+const (
+	RoleUser  = "user"
+	RoleAgent = "agent"
+)
@@ -0,0 +1,105 @@
+// Package a2aerrors defines the JSON-RPC errors of the profiler's A2A
+// endpoint. The server sends them and clients decode them, so both sides
+// can match errors with errors.Is and errors.As instead of by message.
+package a2aerrors
+
+import "fmt"
+
+// Code is a JSON-RPC error code
+type Code int
+
+// JSON-RPC error codes
+const (
+	CodeParseError        Code = -32700
+	CodeInvalidRequest    Code = -32600
+	CodeMethodNotFound    Code = -32601
+	CodeInvalidParams     Code = -32602
+	CodeInternalError     Code = -32603
+	CodeTaskNotFound      Code = -32001
+	CodeTaskNotCancelable Code = -32002
+	// CodePushNotificationNotSupported is reserved by A2A; this agent
+	// supports push notifications
+	CodePushNotificationNotSupported Code = -32003
+	CodeUnsupportedOperation         Code = -32004
+	CodeContentTypeNotSupported      Code = -32005
+	CodeInvalidAgentResponse         Code = -32006
+	CodeExtendedCardNotConfigured    Code = -32007
+	// CodeUnauthorized is server-defined, outside the range A2A reserves
+	CodeUnauthorized Code = -32010
+	// CodeMaintenance is server-defined: the agent is read-only while its
+	// operators maintain it
+	CodeMaintenance Code = -32011
+	// CodeRateLimited is server-defined: the client sent too many requests
+	CodeRateLimited Code = -32012
+)
+
+// Retryable reports whether errors with the code are transient
+func (c Code) Retryable() bool {
+	return c == CodeInternalError || c == CodeMaintenance || c == CodeRateLimited
+}
+
+// Sentinel errors, one per code. errors.Is matches any *Error with the
+// same code, whatever its message and data.
+var (
+	ErrParse                        = &Error{Code: CodeParseError, Message: "Parse error"}
+	ErrInvalidRequest               = &Error{Code: CodeInvalidRequest, Message: "Invalid request"}
+	ErrMethodNotFound               = &Error{Code: CodeMethodNotFound, Message: "Method not found"}
+	ErrInvalidParams                = &Error{Code: CodeInvalidParams, Message: "Invalid params"}
+	ErrInternal                     = &Error{Code: CodeInternalError, Message: "Internal error"}
+	ErrTaskNotFound                 = &Error{Code: CodeTaskNotFound, Message: "Task not found"}
+	ErrTaskNotCancelable            = &Error{Code: CodeTaskNotCancelable, Message: "Task cannot be canceled"}
+	ErrPushNotificationNotSupported = &Error{Code: CodePushNotificationNotSupported, Message: "Push notifications are not supported"}
+	ErrUnsupportedOperation         = &Error{Code: CodeUnsupportedOperation, Message: "Operation is not supported"}
+	ErrContentTypeNotSupported      = &Error{Code: CodeContentTypeNotSupported, Message: "Content type is not supported"}
+	ErrInvalidAgentResponse         = &Error{Code: CodeInvalidAgentResponse, Message: "Invalid agent response"}
+	ErrExtendedCardNotConfigured    = &Error{Code: CodeExtendedCardNotConfigured, Message: "Extended agent card is not configured"}
+	ErrUnauthorized                 = &Error{Code: CodeUnauthorized, Message: "Unauthorized"}
+	ErrMaintenance                  = &Error{Code: CodeMaintenance, Message: "Agent is under maintenance"}
+	ErrRateLimited                  = &Error{Code: CodeRateLimited, Message: "Too many requests"}
+)
+
+// Data carries machine-readable details of an error
+type Data struct {
+	// TaskID is the task the error concerns
+	TaskID string `json:"taskId,omitempty"`
+	// Method is the JSON-RPC method that was not found or not allowed
+	Method string `json:"method,omitempty"`
+	// Field names the invalid parameter of a validation failure
+	Field string `json:"field,omitempty"`
+	// ETA is when a refused request may be retried, such as the end of a
+	// maintenance window, in RFC 3339
+	ETA string `json:"eta,omitempty"`
+	// RequestID identifies the request in the server's logs; quote it when
+	// reporting a problem
+	RequestID string `json:"requestId,omitempty"`
+	// Retryable tells clients whether sending the same request again may succeed
+	Retryable bool `json:"retryable"`
+}
+
+// Error is a JSON-RPC 2.0 error object
+type Error struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	Data    *Data  `json:"data,omitempty"`
+}
+
+// New returns an error with code and message and no data
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("a2a error %d: %s", e.Code, e.Message)
+}
+
+// Is matches errors with the same code, so errors.Is(err, ErrTaskNotFound)
+// holds for every task-not-found error
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Retryable reports whether sending the same request again may succeed
+func (e *Error) Retryable() bool {
+	return (e.Data != nil && e.Data.Retryable) || e.Code.Retryable()
+}
@@ -0,0 +1,156 @@
+package agentkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamChunkSize is the largest text chunk, in bytes, of a streamed artifact
+const StreamChunkSize = 1024
+
+// EventStream writes JSON-RPC responses to one client as Server-Sent Events
+type EventStream struct {
+	c         *gin.Context
+	id        string
+	logger    *slog.Logger
+	redactLog func(string) string
+}
+
+// NewEventStream starts an SSE response answering the request with JSON-RPC
+// ID id. Events are debug-logged to logger, or slog.Default() if it is nil,
+// after redactLog when it is set.
+func NewEventStream(c *gin.Context, id string, logger *slog.Logger, redactLog func(string) string) *EventStream {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	return &EventStream{c: c, id: id, logger: logger, redactLog: redactLog}
+}
+
+// Send writes one event with its sequence number as the SSE id; seq < 0
+// omits the id. Write errors mean the client went away and are ignored.
+func (s *EventStream) Send(seq int, response a2a.JSONRPCResponse) {
+	response.JSONRPC = "2.0"
+	response.ID = s.id
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		s.logger.ErrorContext(s.c.Request.Context(), "Failed to encode stream event", "error", err)
+		return
+	}
+
+	if ctx := s.c.Request.Context(); s.logger.Enabled(ctx, slog.LevelDebug) {
+		event := string(data)
+		if s.redactLog != nil {
+			event = s.redactLog(event)
+		}
+		s.logger.DebugContext(ctx, "Stream event", "event", event)
+	}
+	if seq >= 0 {
+		fmt.Fprintf(s.c.Writer, "id: %d\n", seq)
+	}
+	fmt.Fprintf(s.c.Writer, "data: %s\n\n", data)
+	s.c.Writer.Flush()
+}
+
+// StatusEvent is a status-update event of a task
+func StatusEvent(taskID, contextID string, status a2a.TaskStatus, final bool) a2a.TaskStatusUpdateEvent {
+	return a2a.TaskStatusUpdateEvent{
+		TaskID:    taskID,
+		ContextID: contextID,
+		Kind:      a2a.KindStatusUpdate,
+		Status:    status,
+		Final:     final,
+	}
+}
+
+// ArtifactEvents streams an artifact in chunks so clients can render it as
+// it arrives. Text parts are split into pieces of at most StreamChunkSize
+// bytes; other parts go whole. The first event carries the artifact's name
+// and metadata, later ones set append to add their parts to it, and the
+// last one sets lastChunk.
+func ArtifactEvents(taskID, contextID string, artifact a2a.Artifact) []a2a.TaskArtifactUpdateEvent {
+	var chunks [][]a2a.MessagePart
+	for _, part := range artifact.Parts {
+		text, ok := PartText(part)
+		if !ok || len(text) <= StreamChunkSize {
+			chunks = append(chunks, []a2a.MessagePart{part})
+			continue
+		}
+		for _, piece := range splitText(text, StreamChunkSize) {
+			chunk := a2a.TextPart(piece)
+			chunk.Metadata = part.Metadata
+			chunks = append(chunks, []a2a.MessagePart{chunk})
+		}
+	}
+	if len(chunks) == 0 {
+		chunks = append(chunks, nil)
+	}
+
+	events := make([]a2a.TaskArtifactUpdateEvent, len(chunks))
+	for i, parts := range chunks {
+		chunk := a2a.Artifact{ArtifactID: artifact.ArtifactID, Parts: parts}
+		if i == 0 {
+			chunk.Name = artifact.Name
+			chunk.Metadata = artifact.Metadata
+		}
+		events[i] = a2a.TaskArtifactUpdateEvent{
+			TaskID:    taskID,
+			ContextID: contextID,
+			Kind:      a2a.KindArtifactUpdate,
+			Artifact:  chunk,
+			Append:    i > 0,
+			LastChunk: i == len(chunks)-1,
+		}
+	}
+	return events
+}
+
+// PartText returns the text of a text part, whether built with TextPart or
+// decoded from JSON
+func PartText(part a2a.MessagePart) (string, bool) {
+	if part.Kind != "text" {
+		return "", false
+	}
+	switch text := part.Text.(type) {
+	case *string:
+		if text == nil {
+			return "", false
+		}
+		return *text, true
+	case string:
+		return text, true
+	}
+	return "", false
+}
+
+// splitText cuts text into pieces of at most size bytes, preferring line
+// breaks and never splitting a UTF-8 sequence
+func splitText(text string, size int) []string {
+	var pieces []string
+	for len(text) > size {
+		cut := strings.LastIndexByte(text[:size], '\n') + 1
+		if cut == 0 {
+			cut = size
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = size
+			}
+		}
+		pieces = append(pieces, text[:cut])
+		text = text[cut:]
+	}
+	return append(pieces, text)
+}
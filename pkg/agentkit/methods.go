@@ -0,0 +1,96 @@
+package agentkit
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/gin-gonic/gin"
+)
+
+// MethodHandler serves one JSON-RPC method
+type MethodHandler func(c *gin.Context, rpcReq a2a.JSONRPCRequest)
+
+// Methods maps JSON-RPC method names to their handlers. Aliases resolve to
+// a registered method, and disabled names answer as if unknown.
+type Methods struct {
+	handlers map[string]MethodHandler
+	aliases  map[string]string
+	disabled map[string]bool
+	logger   *slog.Logger
+}
+
+// NewMethods makes an empty method table, which warns about bad aliases
+// and disabled names through logger, or slog.Default() if it is nil
+func NewMethods(logger *slog.Logger) *Methods {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Methods{
+		logger:   logger,
+		handlers: make(map[string]MethodHandler),
+		aliases:  make(map[string]string),
+		disabled: make(map[string]bool),
+	}
+}
+
+// Register adds a method under its canonical name
+func (r *Methods) Register(name string, handler MethodHandler) {
+	r.handlers[name] = handler
+}
+
+// Alias makes name another way to call method; aliases to unknown methods
+// are ignored
+func (r *Methods) Alias(name, method string) {
+	if _, ok := r.handlers[method]; !ok {
+		r.logger.Warn("Ignoring alias of unknown method", "alias", name, "method", method)
+		return
+	}
+	r.aliases[name] = method
+}
+
+// Disable turns off a method or alias; disabling a method also turns off
+// its aliases
+func (r *Methods) Disable(name string) {
+	if _, ok := r.handlers[name]; !ok {
+		if _, ok := r.aliases[name]; !ok {
+			r.logger.Warn("Ignoring unknown disabled method", "method", name)
+			return
+		}
+	}
+	r.disabled[name] = true
+}
+
+// Resolve returns the canonical name and handler for a requested method;
+// ok is false for unknown or disabled methods
+func (r *Methods) Resolve(name string) (string, MethodHandler, bool) {
+	if r.disabled[name] {
+		return "", nil, false
+	}
+	method := name
+	if target, ok := r.aliases[name]; ok {
+		method = target
+	}
+	handler, ok := r.handlers[method]
+	if !ok || r.disabled[method] {
+		return "", nil, false
+	}
+	return method, handler, true
+}
+
+// Names lists the enabled methods and aliases
+func (r *Methods) Names() []string {
+	var names []string
+	for name := range r.handlers {
+		if _, _, ok := r.Resolve(name); ok {
+			names = append(names, name)
+		}
+	}
+	for name := range r.aliases {
+		if _, _, ok := r.Resolve(name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,424 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2aerrors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MetadataSkillID is the params metadata key naming the skill a message is for
+const MetadataSkillID = "skillId"
+
+// Config configures a Server
+type Config struct {
+	// Card is served by ServeCard as the agent card
+	Card interface{}
+	// Skills handle messages by the skillId params metadata; messages
+	// without one go to the first skill
+	Skills []Skill
+	// Tasks keeps tasks for tasks/get; nil keeps them in an unbounded map
+	Tasks TaskStore
+	// Logger receives the server's logs; nil logs through slog.Default()
+	Logger *slog.Logger
+	// RedactLog, when set, scrubs streamed events before they are
+	// debug-logged
+	RedactLog func(string) string
+}
+
+// Server serves an agent's skills over A2A JSON-RPC. It answers
+// message/send, message/stream, tasks/get and tasks/cancel; agents can
+// register more methods on Methods.
+type Server struct {
+	config  Config
+	skills  map[string]Skill
+	methods *Methods
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+// New builds a server for config's skills
+func New(config Config) (*Server, error) {
+	if len(config.Skills) == 0 {
+		return nil, fmt.Errorf("agentkit: at least one skill is required")
+	}
+	skills := make(map[string]Skill, len(config.Skills))
+	for _, skill := range config.Skills {
+		if skill.ID() == "" {
+			return nil, fmt.Errorf("agentkit: skill without an ID")
+		}
+		if _, dup := skills[skill.ID()]; dup {
+			return nil, fmt.Errorf("agentkit: duplicate skill %q", skill.ID())
+		}
+		skills[skill.ID()] = skill
+	}
+	if config.Tasks == nil {
+		config.Tasks = &mapTaskStore{tasks: make(map[string]a2a.TaskResult)}
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	s := &Server{
+		config:  config,
+		skills:  skills,
+		methods: NewMethods(config.Logger),
+		running: make(map[string]context.CancelFunc),
+	}
+	s.methods.Register("message/send", s.handleSend)
+	s.methods.Register("message/stream", s.handleStream)
+	s.methods.Register("tasks/get", s.handleGet)
+	s.methods.Register("tasks/cancel", s.handleCancel)
+	return s, nil
+}
+
+// Methods is the server's method table
+func (s *Server) Methods() *Methods {
+	return s.methods
+}
+
+// ServeCard serves the agent card, typically at /.well-known/agent.json
+func (s *Server) ServeCard(c *gin.Context) {
+	if s.config.Card == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Agent card not available"})
+		return
+	}
+	c.JSON(http.StatusOK, s.config.Card)
+}
+
+// ServeRPC serves JSON-RPC requests
+func (s *Server) ServeRPC(c *gin.Context) {
+	var rpcReq a2a.JSONRPCRequest
+	if err := c.ShouldBindJSON(&rpcReq); err != nil {
+		SendError(c, "", a2aerrors.New(a2aerrors.CodeParseError, "Invalid JSON-RPC request"))
+		return
+	}
+	if rpcReq.JSONRPC != "2.0" {
+		SendError(c, rpcReq.ID, a2aerrors.New(a2aerrors.CodeInvalidRequest, "Invalid JSON-RPC version"))
+		return
+	}
+
+	_, handler, ok := s.methods.Resolve(rpcReq.Method)
+	if !ok {
+		SendError(c, rpcReq.ID, &a2aerrors.Error{
+			Code:    a2aerrors.CodeMethodNotFound,
+			Message: fmt.Sprintf("Method not found: %s", rpcReq.Method),
+			Data:    &a2aerrors.Data{Method: rpcReq.Method},
+		})
+		return
+	}
+	handler(c, rpcReq)
+}
+
+// SendResult writes a JSON-RPC success response
+func SendResult(c *gin.Context, id string, result interface{}) {
+	c.JSON(http.StatusOK, a2a.JSONRPCResponse{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// SendError writes a JSON-RPC error response, marking errors with
+// retryable codes as retryable
+func SendError(c *gin.Context, id string, err *a2aerrors.Error) {
+	c.JSON(http.StatusOK, a2a.JSONRPCResponse{JSONRPC: "2.0", ID: id, Error: wireError(err)})
+}
+
+func wireError(err *a2aerrors.Error) *a2aerrors.Error {
+	if !err.Code.Retryable() || (err.Data != nil && err.Data.Retryable) {
+		return err
+	}
+	wire := *err
+	data := a2aerrors.Data{}
+	if err.Data != nil {
+		data = *err.Data
+	}
+	data.Retryable = true
+	wire.Data = &data
+	return &wire
+}
+
+// DecodeParams decodes the params of a JSON-RPC request into v
+func DecodeParams(rpcReq a2a.JSONRPCRequest, v interface{}) error {
+	raw, err := json.Marshal(rpcReq.Params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func (s *Server) handleSend(c *gin.Context, rpcReq a2a.JSONRPCRequest) {
+	params, resumed, rpcErr := s.messageParams(rpcReq)
+	if rpcErr != nil {
+		SendError(c, rpcReq.ID, rpcErr)
+		return
+	}
+	result, rpcErr := s.run(c, params, resumed, nil)
+	if rpcErr != nil {
+		SendError(c, rpcReq.ID, rpcErr)
+		return
+	}
+	SendResult(c, rpcReq.ID, result)
+}
+
+// handleStream runs a message like message/send but streams its status
+// and artifact updates as Server-Sent Events
+func (s *Server) handleStream(c *gin.Context, rpcReq a2a.JSONRPCRequest) {
+	params, resumed, rpcErr := s.messageParams(rpcReq)
+	if rpcErr != nil {
+		SendError(c, rpcReq.ID, rpcErr)
+		return
+	}
+	taskID, contextID := params.Message.TaskID, params.Message.ContextID
+
+	stream := NewEventStream(c, rpcReq.ID, s.config.Logger, s.config.RedactLog)
+	var mu sync.Mutex
+	seq := 0
+	send := func(event interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		stream.Send(seq, a2a.JSONRPCResponse{Result: event})
+		seq++
+	}
+
+	result, rpcErr := s.run(c, params, resumed, func(status a2a.TaskStatus) {
+		send(StatusEvent(taskID, contextID, status, false))
+	})
+	if rpcErr != nil {
+		stream.Send(-1, a2a.JSONRPCResponse{Error: wireError(rpcErr)})
+		return
+	}
+
+	for _, artifact := range result.Artifacts {
+		for _, event := range ArtifactEvents(taskID, contextID, artifact) {
+			send(event)
+		}
+	}
+	final := StatusEvent(taskID, contextID, result.Status, true)
+	final.Metadata = result.Metadata
+	send(final)
+}
+
+func (s *Server) handleGet(c *gin.Context, rpcReq a2a.JSONRPCRequest) {
+	var params a2a.TaskQueryParams
+	if err := DecodeParams(rpcReq, &params); err != nil || params.ID == "" {
+		SendError(c, rpcReq.ID, invalidParams("id", "Invalid parameters: id is required"))
+		return
+	}
+	if params.HistoryLength < 0 {
+		SendError(c, rpcReq.ID, invalidParams("historyLength", "historyLength must not be negative"))
+		return
+	}
+
+	task, rpcErr := s.task(params.ID)
+	if rpcErr != nil {
+		SendError(c, rpcReq.ID, rpcErr)
+		return
+	}
+	if params.HistoryLength > 0 && len(task.History) > params.HistoryLength {
+		task.History = task.History[len(task.History)-params.HistoryLength:]
+	}
+	SendResult(c, rpcReq.ID, task)
+}
+
+func (s *Server) handleCancel(c *gin.Context, rpcReq a2a.JSONRPCRequest) {
+	var params a2a.TaskIDParams
+	if err := DecodeParams(rpcReq, &params); err != nil || params.ID == "" {
+		SendError(c, rpcReq.ID, invalidParams("id", "Invalid parameters: id is required"))
+		return
+	}
+
+	task, rpcErr := s.task(params.ID)
+	if rpcErr != nil {
+		SendError(c, rpcReq.ID, rpcErr)
+		return
+	}
+	if a2a.IsTerminalState(task.Status.State) {
+		SendError(c, rpcReq.ID, &a2aerrors.Error{
+			Code:    a2aerrors.CodeTaskNotCancelable,
+			Message: fmt.Sprintf("Task %s is already %s", task.ID, task.Status.State),
+			Data:    &a2aerrors.Data{TaskID: task.ID},
+		})
+		return
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.running[task.ID]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	task.Status = a2a.TaskStatus{State: a2a.StateCanceled, Timestamp: a2a.Timestamp()}
+	s.save(task)
+	SendResult(c, rpcReq.ID, task)
+}
+
+// messageParams decodes message params and returns them with the task
+// and context IDs filled in, along with the input-required task the
+// message resumes, if any. A reply without a contextId stays in the
+// resumed task's conversation.
+func (s *Server) messageParams(rpcReq a2a.JSONRPCRequest) (a2a.MessageParams, *a2a.TaskResult, *a2aerrors.Error) {
+	var params a2a.MessageParams
+	if err := DecodeParams(rpcReq, &params); err != nil {
+		return params, nil, invalidParams("message", "Invalid parameters")
+	}
+	msg := &params.Message
+
+	var resumed *a2a.TaskResult
+	if msg.TaskID != "" {
+		previous, ok, err := s.config.Tasks.Get(msg.TaskID)
+		if err != nil {
+			s.config.Logger.Error("Failed to load task", "task_id", msg.TaskID, "error", err)
+			return params, nil, &a2aerrors.Error{Code: a2aerrors.CodeInternalError, Message: "Failed to load task", Data: &a2aerrors.Data{TaskID: msg.TaskID}}
+		}
+		if ok {
+			if previous.Status.State != a2a.StateInputRequired {
+				return params, nil, &a2aerrors.Error{
+					Code:    a2aerrors.CodeInvalidParams,
+					Message: fmt.Sprintf("Task %s is %s and takes no more messages", msg.TaskID, previous.Status.State),
+					Data:    &a2aerrors.Data{TaskID: msg.TaskID, Field: "message.taskId"},
+				}
+			}
+			resumed = &previous
+			if msg.ContextID == "" {
+				msg.ContextID = previous.ContextID
+			}
+		}
+	}
+
+	if msg.TaskID == "" {
+		msg.TaskID = uuid.New().String()
+	}
+	if msg.ContextID == "" {
+		msg.ContextID = uuid.New().String()
+	}
+	return params, resumed, nil
+}
+
+// run routes a message to its skill and returns the task it ends in
+func (s *Server) run(c *gin.Context, params a2a.MessageParams, resumed *a2a.TaskResult, updates Updates) (a2a.TaskResult, *a2aerrors.Error) {
+	skill, rpcErr := s.skill(params.Metadata)
+	if rpcErr != nil {
+		return a2a.TaskResult{}, rpcErr
+	}
+	msg := params.Message
+
+	var history []a2a.A2AMessage
+	if resumed != nil {
+		history = resumed.History
+	}
+	history = append(history, msg)
+
+	// The task outlives a client that disconnects; only tasks/cancel stops it
+	ctx, cancel := context.WithCancel(context.WithoutCancel(c.Request.Context()))
+	s.mu.Lock()
+	s.running[msg.TaskID] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, msg.TaskID)
+		s.mu.Unlock()
+		cancel()
+	}()
+
+	s.save(a2a.TaskResult{
+		ID:        msg.TaskID,
+		ContextID: msg.ContextID,
+		Kind:      "task",
+		Status:    a2a.TaskStatus{State: a2a.StateWorking, Timestamp: a2a.Timestamp()},
+		History:   history,
+	})
+
+	req := &Request{TaskID: msg.TaskID, ContextID: msg.ContextID, Params: params, Resumed: resumed, HTTP: c.Request, ClientIP: c.ClientIP()}
+	result, err := skill.Execute(ctx, req, updates)
+	switch {
+	case ctx.Err() != nil:
+		result = a2a.TaskResult{Status: a2a.TaskStatus{State: a2a.StateCanceled, Timestamp: a2a.Timestamp()}}
+	case err != nil:
+		var rpcErr *a2aerrors.Error
+		if errors.As(err, &rpcErr) {
+			s.save(a2a.TaskResult{
+				ID:        msg.TaskID,
+				ContextID: msg.ContextID,
+				Kind:      "task",
+				Status:    a2a.TaskStatus{State: a2a.StateFailed, Timestamp: a2a.Timestamp()},
+				History:   history,
+			})
+			return a2a.TaskResult{}, rpcErr
+		}
+		s.config.Logger.ErrorContext(c.Request.Context(), "Skill failed task", "skill", skill.ID(), "task_id", msg.TaskID, "error", err)
+		result = a2a.TaskResult{Status: a2a.TaskStatus{
+			State:     a2a.StateFailed,
+			Timestamp: a2a.Timestamp(),
+			Message: &a2a.A2AMessage{
+				Kind:  "message",
+				Role:  a2a.RoleAgent,
+				Parts: []a2a.MessagePart{a2a.TextPart("The agent failed to complete the task.")},
+			},
+		}}
+	}
+
+	result.ID = msg.TaskID
+	result.ContextID = msg.ContextID
+	result.Kind = "task"
+	if result.Status.Timestamp == "" {
+		result.Status.Timestamp = a2a.Timestamp()
+	}
+	result.History = history
+	if reply := result.Status.Message; reply != nil {
+		reply.TaskID = msg.TaskID
+		reply.ContextID = msg.ContextID
+		result.History = append(result.History, *reply)
+	}
+	s.save(result)
+	return result, nil
+}
+
+// skill returns the skill named by the skillId metadata, or the first one
+func (s *Server) skill(metadata map[string]interface{}) (Skill, *a2aerrors.Error) {
+	raw, ok := metadata[MetadataSkillID]
+	if !ok || raw == nil {
+		return s.config.Skills[0], nil
+	}
+	id, ok := raw.(string)
+	if !ok {
+		return nil, invalidParams(MetadataSkillID, MetadataSkillID+" must be a string")
+	}
+	skill, ok := s.skills[id]
+	if !ok {
+		return nil, invalidParams(MetadataSkillID, fmt.Sprintf("unknown skill %q", id))
+	}
+	return skill, nil
+}
+
+func (s *Server) task(id string) (a2a.TaskResult, *a2aerrors.Error) {
+	task, ok, err := s.config.Tasks.Get(id)
+	if err != nil {
+		s.config.Logger.Error("Failed to load task", "task_id", id, "error", err)
+		return task, &a2aerrors.Error{Code: a2aerrors.CodeInternalError, Message: "Failed to load task", Data: &a2aerrors.Data{TaskID: id}}
+	}
+	if !ok {
+		return task, &a2aerrors.Error{
+			Code:    a2aerrors.CodeTaskNotFound,
+			Message: fmt.Sprintf("Task not found: %s", id),
+			Data:    &a2aerrors.Data{TaskID: id},
+		}
+	}
+	return task, nil
+}
+
+func (s *Server) save(task a2a.TaskResult) {
+	if err := s.config.Tasks.Save(task); err != nil {
+		s.config.Logger.Warn("Failed to store task", "task_id", task.ID, "error", err)
+	}
+}
+
+func invalidParams(field, message string) *a2aerrors.Error {
+	return &a2aerrors.Error{Code: a2aerrors.CodeInvalidParams, Message: message, Data: &a2aerrors.Data{Field: field}}
+}
@@ -0,0 +1,44 @@
+// Package agentkit is the A2A plumbing of the profiler agent, reusable for
+// sibling agents: JSON-RPC dispatch, the task lifecycle, streaming and
+// agent card serving. An agent only implements its skills.
+package agentkit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+)
+
+// Skill does the work of one skill of the agent card. Execute may be
+// called concurrently.
+type Skill interface {
+	// ID is the skill's ID on the agent card, which callers name with the
+	// skillId params metadata
+	ID() string
+	// Execute runs a task and returns it in its final state, or in
+	// input-required. The server fills in the task's ID, context ID and
+	// history. Errors of type *a2aerrors.Error are sent to the caller as
+	// JSON-RPC errors; other errors fail the task. ctx is canceled by
+	// tasks/cancel.
+	Execute(ctx context.Context, req *Request, updates Updates) (a2a.TaskResult, error)
+}
+
+// Request is a message routed to a skill
+type Request struct {
+	TaskID    string
+	ContextID string
+	Params    a2a.MessageParams
+	// Resumed is the input-required task the message answers, or nil
+	Resumed *a2a.TaskResult
+	// HTTP is the request that carried the message, for its headers
+	HTTP *http.Request
+	// ClientIP is the caller's address, as gin resolves it through any
+	// trusted proxies
+	ClientIP string
+}
+
+// Updates reports a working task's progress; streaming clients get each
+// status as an event. It may be called from several goroutines, or be nil
+// when nobody is listening.
+type Updates func(status a2a.TaskStatus)
@@ -0,0 +1,35 @@
+package agentkit
+
+import (
+	"sync"
+
+	"github.com/BerylCAtieno/customer-profiler-agent/pkg/a2a"
+)
+
+// TaskStore persists task snapshots so clients can poll them with tasks/get
+type TaskStore interface {
+	// Save inserts or replaces the task with the same ID
+	Save(task a2a.TaskResult) error
+	// Get returns the task with the given ID; ok is false if it is unknown
+	Get(id string) (task a2a.TaskResult, ok bool, err error)
+}
+
+// mapTaskStore is the unbounded TaskStore of servers configured without one
+type mapTaskStore struct {
+	mu    sync.Mutex
+	tasks map[string]a2a.TaskResult
+}
+
+func (s *mapTaskStore) Save(task a2a.TaskResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[task.ID] = task
+	return nil
+}
+
+func (s *mapTaskStore) Get(id string) (a2a.TaskResult, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, ok := s.tasks[id]
+	return task, ok, nil
+}
@@ -0,0 +1,103 @@
+// Package models holds the customer profile types the profiler agent
+// returns, for Go agents that consume its structured artifacts.
+package models
+
+// CustomerProfile represents a detailed customer persona
+type CustomerProfile struct {
+	Age               string   `json:"age"`
+	Gender            string   `json:"gender"`
+	Location          string   `json:"location"`
+	Occupation        string   `json:"occupation"`
+	Income            string   `json:"income"`
+	Motivations       []string `json:"motivations"`
+	Interests         []string `json:"interests"`
+	PainPoints        []string `json:"pain_points"`
+	BuyingBehaviors   []string `json:"buying_behaviors"`
+	PreferredChannels []string `json:"preferred_channels"`
+	// Custom holds the extra fields a tenant asked for, keyed by field name
+	Custom map[string]string `json:"custom,omitempty"`
+	// FitWarning explains why the readback check found this persona not to
+	// fit the business idea when it couldn't be corrected
+	FitWarning string `json:"fit_warning,omitempty"`
+}
+
+// ProfileResponse contains mulriple customer profiles related to a given business idea
+
+type ProfileResponse struct {
+	BusinessIdea string            `json:"business_idea"`
+	Profiles     []CustomerProfile `json:"profiles"`
+	Summary      string            `json:"summary"`
+	Keywords     []string          `json:"keywords"`
+	Reproducible bool              `json:"reproducible,omitempty"`
+	PromptHash   string            `json:"prompt_hash,omitempty"`
+	// Degraded marks a template persona served while the model was unavailable
+	Degraded         bool   `json:"degraded,omitempty"`
+	FallbackIndustry string `json:"fallback_industry,omitempty"`
+	// Sources lists web pages cited by search-grounded generation
+	Sources []Source `json:"sources,omitempty"`
+	// InputCondensed marks an idea that was summarized or truncated to fit
+	// the input token budget; BusinessIdea then holds the condensed text
+	InputCondensed bool `json:"input_condensed,omitempty"`
+	// Refinement is the follow-up instruction applied to the previous
+	// profiles of the conversation; empty for a fresh generation
+	Refinement string `json:"refinement,omitempty"`
+	// InterestGraph is only set when more than one persona is generated
+	InterestGraph *InterestGraph `json:"interest_graph,omitempty"`
+	// Readback reports the post-generation check of personas against the
+	// idea; nil when the check didn't run
+	Readback *Readback `json:"readback,omitempty"`
+	// Journeys holds a journey map per persona when one was asked for
+	Journeys []Journey `json:"journeys,omitempty"`
+	// Warnings describe limits that were relaxed instead of failing the
+	// request, in soft-limit mode
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Readback counts the personas the readback check found not to fit the
+// business idea, split by whether they were rewritten or only flagged
+type Readback struct {
+	Checked   int `json:"checked"`
+	Corrected int `json:"corrected"`
+	Flagged   int `json:"flagged"`
+}
+
+// Journey maps how one persona, numbered from 1, moves from first hearing
+// of the business to recommending it
+type Journey struct {
+	Persona int            `json:"persona"`
+	Stages  []JourneyStage `json:"stages"`
+}
+
+type JourneyStage struct {
+	Stage       string   `json:"stage"`
+	Touchpoints []string `json:"touchpoints"`
+	Goal        string   `json:"goal"`
+	PainPoint   string   `json:"pain_point"`
+	Opportunity string   `json:"opportunity"`
+}
+
+// Source is a web page a grounded profile drew on
+type Source struct {
+	Title string `json:"title"`
+	URI   string `json:"uri"`
+}
+
+// InterestGraph describes how personas overlap: nodes are personas and
+// edges connect personas sharing interests or channels
+type InterestGraph struct {
+	Nodes []PersonaNode `json:"nodes"`
+	Edges []OverlapEdge `json:"edges"`
+}
+
+type PersonaNode struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type OverlapEdge struct {
+	Source          string   `json:"source"`
+	Target          string   `json:"target"`
+	SharedInterests []string `json:"shared_interests,omitempty"`
+	SharedChannels  []string `json:"shared_channels,omitempty"`
+	Weight          int      `json:"weight"`
+}
@@ -51,7 +51,7 @@ build_test_client() {
         mkdir -p bin
     fi
     
-    if go build -o "${TEST_CLIENT}" cmd/test/main.go; then
+    if go build -o "${TEST_CLIENT}" ./cmd/test; then
         print_success "Test client built successfully"
         return 0
     else
@@ -106,7 +106,7 @@ Examples:
     BASE_URL=https://example.com $0 all    # Test remote server
 
 Before running tests:
-    1. Start the server: make run (or go run cmd/server/main.go)
+    1. Start the server: make run (or go run ./cmd/server)
     2. Set GEMINI_API_KEY environment variable
     3. Run tests: $0
 